@@ -2,20 +2,22 @@ package main
 
 import (
 	"time"
+
 	"pfeifer.dev/mapd/cereal/car"
+	m "pfeifer.dev/mapd/math"
 	ms "pfeifer.dev/mapd/settings"
 	"pfeifer.dev/mapd/utils"
-	m "pfeifer.dev/mapd/math"
 )
 
 type CarState struct {
-	SetSpeed   utils.Float32Tracker
-	VEgo       float32
-	AEgo       float32
-	VCruise    float32
-	GasPressed bool
-	UpdateTime utils.UpdateTracker
-	SetSpeedChanging bool
+	Clock             utils.Clock
+	SetSpeed          utils.Float32Tracker
+	VEgo              float32
+	AEgo              float32
+	VCruise           float32
+	GasPressed        bool
+	UpdateTime        utils.UpdateTracker
+	SetSpeedChanging  bool
 	EnableSpeedActive bool
 }
 
@@ -25,7 +27,7 @@ func (c *CarState) Update(carData car.CarState) {
 	c.AEgo = carData.AEgo()
 	c.VCruise = carData.VCruise()
 	c.GasPressed = carData.GasPressed()
-	c.SetSpeedChanging = time.Since(c.SetSpeed.UpdatedTime) < 1500*time.Millisecond
+	c.SetSpeedChanging = c.Clock.Now().Sub(c.SetSpeed.UpdatedTime) < 1500*time.Millisecond
 	if ms.Settings.EnableSpeed == 0 {
 		c.EnableSpeedActive = true
 	} else {
@@ -34,7 +36,9 @@ func (c *CarState) Update(carData car.CarState) {
 	c.UpdateTime.Update()
 }
 
-func (c *CarState) Init() {
+func (c *CarState) Init(clock utils.Clock) {
+	c.Clock = clock
+	c.SetSpeed.Clock = clock
 	c.SetSpeed.AllowNullLastValue = true
-	c.UpdateTime.Init(100)
+	c.UpdateTime.Init(clock, 100)
 }
@@ -1361,12 +1361,12 @@ type MapdPathPoint capnp.Struct
 const MapdPathPoint_TypeID = 0xd6f78acca1bc3939
 
 func NewMapdPathPoint(s *capnp.Segment) (MapdPathPoint, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 0})
 	return MapdPathPoint(st), err
 }
 
 func NewRootMapdPathPoint(s *capnp.Segment) (MapdPathPoint, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 40, PointerCount: 0})
 	return MapdPathPoint(st), err
 }
 
@@ -1434,12 +1434,28 @@ func (s MapdPathPoint) SetTargetVelocity(v float32) {
 	capnp.Struct(s).SetUint32(20, math.Float32bits(v))
 }
 
+func (s MapdPathPoint) NodeId() int64 {
+	return int64(capnp.Struct(s).Uint64(24))
+}
+
+func (s MapdPathPoint) SetNodeId(v int64) {
+	capnp.Struct(s).SetUint64(24, uint64(v))
+}
+
+func (s MapdPathPoint) WayId() int64 {
+	return int64(capnp.Struct(s).Uint64(32))
+}
+
+func (s MapdPathPoint) SetWayId(v int64) {
+	capnp.Struct(s).SetUint64(32, uint64(v))
+}
+
 // MapdPathPoint_List is a list of MapdPathPoint.
 type MapdPathPoint_List = capnp.StructList[MapdPathPoint]
 
 // NewMapdPathPoint creates a new list of MapdPathPoint.
 func NewMapdPathPoint_List(s *capnp.Segment, sz int32) (MapdPathPoint_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 40, PointerCount: 0}, sz)
 	return capnp.StructList[MapdPathPoint](l), err
 }
 
@@ -1451,18 +1467,124 @@ func (f MapdPathPoint_Future) Struct() (MapdPathPoint, error) {
 	return MapdPathPoint(p.Struct()), err
 }
 
+type MapdPhaseTiming capnp.Struct
+
+// MapdPhaseTiming_TypeID is the unique identifier for the type MapdPhaseTiming.
+const MapdPhaseTiming_TypeID = 0x916b986e1430edc4
+
+func NewMapdPhaseTiming(s *capnp.Segment) (MapdPhaseTiming, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	return MapdPhaseTiming(st), err
+}
+
+func NewRootMapdPhaseTiming(s *capnp.Segment) (MapdPhaseTiming, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1})
+	return MapdPhaseTiming(st), err
+}
+
+func ReadRootMapdPhaseTiming(msg *capnp.Message) (MapdPhaseTiming, error) {
+	root, err := msg.Root()
+	return MapdPhaseTiming(root.Struct()), err
+}
+
+func (s MapdPhaseTiming) String() string {
+	str, _ := text.Marshal(0x916b986e1430edc4, capnp.Struct(s))
+	return str
+}
+
+func (s MapdPhaseTiming) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (MapdPhaseTiming) DecodeFromPtr(p capnp.Ptr) MapdPhaseTiming {
+	return MapdPhaseTiming(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s MapdPhaseTiming) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s MapdPhaseTiming) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s MapdPhaseTiming) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s MapdPhaseTiming) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s MapdPhaseTiming) Phase() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s MapdPhaseTiming) HasPhase() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s MapdPhaseTiming) PhaseBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s MapdPhaseTiming) SetPhase(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s MapdPhaseTiming) Count() uint32 {
+	return capnp.Struct(s).Uint32(0)
+}
+
+func (s MapdPhaseTiming) SetCount(v uint32) {
+	capnp.Struct(s).SetUint32(0, v)
+}
+
+func (s MapdPhaseTiming) AverageMs() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(4))
+}
+
+func (s MapdPhaseTiming) SetAverageMs(v float32) {
+	capnp.Struct(s).SetUint32(4, math.Float32bits(v))
+}
+
+func (s MapdPhaseTiming) MaxMs() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(8))
+}
+
+func (s MapdPhaseTiming) SetMaxMs(v float32) {
+	capnp.Struct(s).SetUint32(8, math.Float32bits(v))
+}
+
+// MapdPhaseTiming_List is a list of MapdPhaseTiming.
+type MapdPhaseTiming_List = capnp.StructList[MapdPhaseTiming]
+
+// NewMapdPhaseTiming creates a new list of MapdPhaseTiming.
+func NewMapdPhaseTiming_List(s *capnp.Segment, sz int32) (MapdPhaseTiming_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 1}, sz)
+	return capnp.StructList[MapdPhaseTiming](l), err
+}
+
+// MapdPhaseTiming_Future is a wrapper for a MapdPhaseTiming promised by a client call.
+type MapdPhaseTiming_Future struct{ *capnp.Future }
+
+func (f MapdPhaseTiming_Future) Struct() (MapdPhaseTiming, error) {
+	p, err := f.Future.Ptr()
+	return MapdPhaseTiming(p.Struct()), err
+}
+
 type MapdExtendedOut capnp.Struct
 
 // MapdExtendedOut_TypeID is the unique identifier for the type MapdExtendedOut.
 const MapdExtendedOut_TypeID = 0xa30662f84033036c
 
 func NewMapdExtendedOut(s *capnp.Segment) (MapdExtendedOut, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4})
 	return MapdExtendedOut(st), err
 }
 
 func NewRootMapdExtendedOut(s *capnp.Segment) (MapdExtendedOut, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4})
 	return MapdExtendedOut(st), err
 }
 
@@ -1563,13 +1685,36 @@ func (s MapdExtendedOut) NewPath(n int32) (MapdPathPoint_List, error) {
 	err = capnp.Struct(s).SetPtr(2, l.ToPtr())
 	return l, err
 }
+func (s MapdExtendedOut) Timings() (MapdPhaseTiming_List, error) {
+	p, err := capnp.Struct(s).Ptr(3)
+	return MapdPhaseTiming_List(p.List()), err
+}
+
+func (s MapdExtendedOut) HasTimings() bool {
+	return capnp.Struct(s).HasPtr(3)
+}
+
+func (s MapdExtendedOut) SetTimings(v MapdPhaseTiming_List) error {
+	return capnp.Struct(s).SetPtr(3, v.ToPtr())
+}
+
+// NewTimings sets the timings field to a newly
+// allocated MapdPhaseTiming_List, preferring placement in s's segment.
+func (s MapdExtendedOut) NewTimings(n int32) (MapdPhaseTiming_List, error) {
+	l, err := NewMapdPhaseTiming_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return MapdPhaseTiming_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(3, l.ToPtr())
+	return l, err
+}
 
 // MapdExtendedOut_List is a list of MapdExtendedOut.
 type MapdExtendedOut_List = capnp.StructList[MapdExtendedOut]
 
 // NewMapdExtendedOut creates a new list of MapdExtendedOut.
 func NewMapdExtendedOut_List(s *capnp.Segment, sz int32) (MapdExtendedOut_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 3}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 0, PointerCount: 4}, sz)
 	return capnp.StructList[MapdExtendedOut](l), err
 }
 
@@ -1630,6 +1775,27 @@ const (
 	MapdInputType_setAdjustSetSpeedToAcceptSpeedLimit    MapdInputType = 36
 	MapdInputType_setAcceptSpeedLimitTimeout             MapdInputType = 37
 	MapdInputType_setPressGasToOverrideSpeedLimit        MapdInputType = 38
+	MapdInputType_setWayMatchingMode                     MapdInputType = 39
+	MapdInputType_setPositionFilterEnabled               MapdInputType = 40
+	MapdInputType_setDeadReckoningMaxDistance            MapdInputType = 41
+	MapdInputType_setPositionSource                      MapdInputType = 42
+	MapdInputType_setCurveSpeedMode                      MapdInputType = 43
+	MapdInputType_setCurveLearningEnabled                MapdInputType = 44
+	MapdInputType_resetLearnedCurves                     MapdInputType = 45
+	MapdInputType_setWayMaxSpeed                         MapdInputType = 46
+	MapdInputType_setWayIgnoreMaxSpeed                   MapdInputType = 47
+	MapdInputType_setWayAdvisorySpeed                    MapdInputType = 48
+	MapdInputType_setWayHazard                           MapdInputType = 49
+	MapdInputType_setWayIgnoreHazard                     MapdInputType = 50
+	MapdInputType_setWayCurveSpeed                       MapdInputType = 51
+	MapdInputType_clearWayOverride                       MapdInputType = 52
+	MapdInputType_importWayOverrides                     MapdInputType = 53
+	MapdInputType_exportWayOverrides                     MapdInputType = 54
+	MapdInputType_setWebServerEnabled                    MapdInputType = 55
+	MapdInputType_setWebServerPort                       MapdInputType = 56
+	MapdInputType_setOutputRate                          MapdInputType = 57
+	MapdInputType_setWebServerAddress                    MapdInputType = 58
+	MapdInputType_setDriveOnLeft                         MapdInputType = 59
 )
 
 // String returns the enum's constant name.
@@ -1713,6 +1879,48 @@ func (c MapdInputType) String() string {
 		return "setAcceptSpeedLimitTimeout"
 	case MapdInputType_setPressGasToOverrideSpeedLimit:
 		return "setPressGasToOverrideSpeedLimit"
+	case MapdInputType_setWayMatchingMode:
+		return "setWayMatchingMode"
+	case MapdInputType_setPositionFilterEnabled:
+		return "setPositionFilterEnabled"
+	case MapdInputType_setDeadReckoningMaxDistance:
+		return "setDeadReckoningMaxDistance"
+	case MapdInputType_setPositionSource:
+		return "setPositionSource"
+	case MapdInputType_setCurveSpeedMode:
+		return "setCurveSpeedMode"
+	case MapdInputType_setCurveLearningEnabled:
+		return "setCurveLearningEnabled"
+	case MapdInputType_resetLearnedCurves:
+		return "resetLearnedCurves"
+	case MapdInputType_setWayMaxSpeed:
+		return "setWayMaxSpeed"
+	case MapdInputType_setWayIgnoreMaxSpeed:
+		return "setWayIgnoreMaxSpeed"
+	case MapdInputType_setWayAdvisorySpeed:
+		return "setWayAdvisorySpeed"
+	case MapdInputType_setWayHazard:
+		return "setWayHazard"
+	case MapdInputType_setWayIgnoreHazard:
+		return "setWayIgnoreHazard"
+	case MapdInputType_setWayCurveSpeed:
+		return "setWayCurveSpeed"
+	case MapdInputType_clearWayOverride:
+		return "clearWayOverride"
+	case MapdInputType_importWayOverrides:
+		return "importWayOverrides"
+	case MapdInputType_exportWayOverrides:
+		return "exportWayOverrides"
+	case MapdInputType_setWebServerEnabled:
+		return "setWebServerEnabled"
+	case MapdInputType_setWebServerPort:
+		return "setWebServerPort"
+	case MapdInputType_setOutputRate:
+		return "setOutputRate"
+	case MapdInputType_setWebServerAddress:
+		return "setWebServerAddress"
+	case MapdInputType_setDriveOnLeft:
+		return "setDriveOnLeft"
 
 	default:
 		return ""
@@ -1801,6 +2009,48 @@ func MapdInputTypeFromString(c string) MapdInputType {
 		return MapdInputType_setAcceptSpeedLimitTimeout
 	case "setPressGasToOverrideSpeedLimit":
 		return MapdInputType_setPressGasToOverrideSpeedLimit
+	case "setWayMatchingMode":
+		return MapdInputType_setWayMatchingMode
+	case "setPositionFilterEnabled":
+		return MapdInputType_setPositionFilterEnabled
+	case "setDeadReckoningMaxDistance":
+		return MapdInputType_setDeadReckoningMaxDistance
+	case "setPositionSource":
+		return MapdInputType_setPositionSource
+	case "setCurveSpeedMode":
+		return MapdInputType_setCurveSpeedMode
+	case "setCurveLearningEnabled":
+		return MapdInputType_setCurveLearningEnabled
+	case "resetLearnedCurves":
+		return MapdInputType_resetLearnedCurves
+	case "setWayMaxSpeed":
+		return MapdInputType_setWayMaxSpeed
+	case "setWayIgnoreMaxSpeed":
+		return MapdInputType_setWayIgnoreMaxSpeed
+	case "setWayAdvisorySpeed":
+		return MapdInputType_setWayAdvisorySpeed
+	case "setWayHazard":
+		return MapdInputType_setWayHazard
+	case "setWayIgnoreHazard":
+		return MapdInputType_setWayIgnoreHazard
+	case "setWayCurveSpeed":
+		return MapdInputType_setWayCurveSpeed
+	case "clearWayOverride":
+		return MapdInputType_clearWayOverride
+	case "importWayOverrides":
+		return MapdInputType_importWayOverrides
+	case "exportWayOverrides":
+		return MapdInputType_exportWayOverrides
+	case "setWebServerEnabled":
+		return MapdInputType_setWebServerEnabled
+	case "setWebServerPort":
+		return MapdInputType_setWebServerPort
+	case "setOutputRate":
+		return MapdInputType_setOutputRate
+	case "setWebServerAddress":
+		return MapdInputType_setWebServerAddress
+	case "setDriveOnLeft":
+		return MapdInputType_setDriveOnLeft
 
 	default:
 		return 0
@@ -1820,11 +2070,13 @@ const WaySelectionType_TypeID = 0xfa3e5ce74e25e82a
 
 // Values of WaySelectionType.
 const (
-	WaySelectionType_current   WaySelectionType = 0
-	WaySelectionType_predicted WaySelectionType = 1
-	WaySelectionType_possible  WaySelectionType = 2
-	WaySelectionType_extended  WaySelectionType = 3
-	WaySelectionType_fail      WaySelectionType = 4
+	WaySelectionType_current       WaySelectionType = 0
+	WaySelectionType_predicted     WaySelectionType = 1
+	WaySelectionType_possible      WaySelectionType = 2
+	WaySelectionType_extended      WaySelectionType = 3
+	WaySelectionType_fail          WaySelectionType = 4
+	WaySelectionType_hmm           WaySelectionType = 5
+	WaySelectionType_deadReckoning WaySelectionType = 6
 )
 
 // String returns the enum's constant name.
@@ -1840,6 +2092,10 @@ func (c WaySelectionType) String() string {
 		return "extended"
 	case WaySelectionType_fail:
 		return "fail"
+	case WaySelectionType_hmm:
+		return "hmm"
+	case WaySelectionType_deadReckoning:
+		return "deadReckoning"
 
 	default:
 		return ""
@@ -1860,6 +2116,10 @@ func WaySelectionTypeFromString(c string) WaySelectionType {
 		return WaySelectionType_extended
 	case "fail":
 		return WaySelectionType_fail
+	case "hmm":
+		return WaySelectionType_hmm
+	case "deadReckoning":
+		return WaySelectionType_deadReckoning
 
 	default:
 		return 0
@@ -2071,18 +2331,409 @@ func NewRoadContext_List(s *capnp.Segment, sz int32) (RoadContext_List, error) {
 	return capnp.NewEnumList[RoadContext](s, sz)
 }
 
+type GpsFixStatus uint16
+
+// GpsFixStatus_TypeID is the unique identifier for the type GpsFixStatus.
+const GpsFixStatus_TypeID = 0xbe935587c4efb5b0
+
+// Values of GpsFixStatus.
+const (
+	GpsFixStatus_ok              GpsFixStatus = 0
+	GpsFixStatus_noFix           GpsFixStatus = 1
+	GpsFixStatus_poorAccuracy    GpsFixStatus = 2
+	GpsFixStatus_jump            GpsFixStatus = 3
+	GpsFixStatus_bearingMismatch GpsFixStatus = 4
+)
+
+// String returns the enum's constant name.
+func (c GpsFixStatus) String() string {
+	switch c {
+	case GpsFixStatus_ok:
+		return "ok"
+	case GpsFixStatus_noFix:
+		return "noFix"
+	case GpsFixStatus_poorAccuracy:
+		return "poorAccuracy"
+	case GpsFixStatus_jump:
+		return "jump"
+	case GpsFixStatus_bearingMismatch:
+		return "bearingMismatch"
+
+	default:
+		return ""
+	}
+}
+
+// GpsFixStatusFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func GpsFixStatusFromString(c string) GpsFixStatus {
+	switch c {
+	case "ok":
+		return GpsFixStatus_ok
+	case "noFix":
+		return GpsFixStatus_noFix
+	case "poorAccuracy":
+		return GpsFixStatus_poorAccuracy
+	case "jump":
+		return GpsFixStatus_jump
+	case "bearingMismatch":
+		return GpsFixStatus_bearingMismatch
+
+	default:
+		return 0
+	}
+}
+
+type GpsFixStatus_List = capnp.EnumList[GpsFixStatus]
+
+func NewGpsFixStatus_List(s *capnp.Segment, sz int32) (GpsFixStatus_List, error) {
+	return capnp.NewEnumList[GpsFixStatus](s, sz)
+}
+
+type SpeedReason uint16
+
+// SpeedReason_TypeID is the unique identifier for the type SpeedReason.
+const SpeedReason_TypeID = 0xae1ee0f5a968719c
+
+// Values of SpeedReason.
+const (
+	SpeedReason_none               SpeedReason = 0
+	SpeedReason_speedLimit         SpeedReason = 1
+	SpeedReason_nextSpeedLimit     SpeedReason = 2
+	SpeedReason_heldSpeedLimit     SpeedReason = 3
+	SpeedReason_externalSpeedLimit SpeedReason = 4
+	SpeedReason_gasOverride        SpeedReason = 5
+	SpeedReason_awaitingAcceptance SpeedReason = 6
+	SpeedReason_mapCurve           SpeedReason = 7
+	SpeedReason_visionCurve        SpeedReason = 8
+	SpeedReason_curve              SpeedReason = 9
+	SpeedReason_other              SpeedReason = 10
+)
+
+// String returns the enum's constant name.
+func (c SpeedReason) String() string {
+	switch c {
+	case SpeedReason_none:
+		return "none"
+	case SpeedReason_speedLimit:
+		return "speedLimit"
+	case SpeedReason_nextSpeedLimit:
+		return "nextSpeedLimit"
+	case SpeedReason_heldSpeedLimit:
+		return "heldSpeedLimit"
+	case SpeedReason_externalSpeedLimit:
+		return "externalSpeedLimit"
+	case SpeedReason_gasOverride:
+		return "gasOverride"
+	case SpeedReason_awaitingAcceptance:
+		return "awaitingAcceptance"
+	case SpeedReason_mapCurve:
+		return "mapCurve"
+	case SpeedReason_visionCurve:
+		return "visionCurve"
+	case SpeedReason_curve:
+		return "curve"
+	case SpeedReason_other:
+		return "other"
+
+	default:
+		return ""
+	}
+}
+
+// SpeedReasonFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func SpeedReasonFromString(c string) SpeedReason {
+	switch c {
+	case "none":
+		return SpeedReason_none
+	case "speedLimit":
+		return SpeedReason_speedLimit
+	case "nextSpeedLimit":
+		return SpeedReason_nextSpeedLimit
+	case "heldSpeedLimit":
+		return SpeedReason_heldSpeedLimit
+	case "externalSpeedLimit":
+		return SpeedReason_externalSpeedLimit
+	case "gasOverride":
+		return SpeedReason_gasOverride
+	case "awaitingAcceptance":
+		return SpeedReason_awaitingAcceptance
+	case "mapCurve":
+		return SpeedReason_mapCurve
+	case "visionCurve":
+		return SpeedReason_visionCurve
+	case "curve":
+		return SpeedReason_curve
+	case "other":
+		return SpeedReason_other
+
+	default:
+		return 0
+	}
+}
+
+type SpeedReason_List = capnp.EnumList[SpeedReason]
+
+func NewSpeedReason_List(s *capnp.Segment, sz int32) (SpeedReason_List, error) {
+	return capnp.NewEnumList[SpeedReason](s, sz)
+}
+
+type MapdSpeedCandidate capnp.Struct
+
+// MapdSpeedCandidate_TypeID is the unique identifier for the type MapdSpeedCandidate.
+const MapdSpeedCandidate_TypeID = 0xfb199b7aa69e4c27
+
+func NewMapdSpeedCandidate(s *capnp.Segment) (MapdSpeedCandidate, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return MapdSpeedCandidate(st), err
+}
+
+func NewRootMapdSpeedCandidate(s *capnp.Segment) (MapdSpeedCandidate, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	return MapdSpeedCandidate(st), err
+}
+
+func ReadRootMapdSpeedCandidate(msg *capnp.Message) (MapdSpeedCandidate, error) {
+	root, err := msg.Root()
+	return MapdSpeedCandidate(root.Struct()), err
+}
+
+func (s MapdSpeedCandidate) String() string {
+	str, _ := text.Marshal(0xfb199b7aa69e4c27, capnp.Struct(s))
+	return str
+}
+
+func (s MapdSpeedCandidate) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (MapdSpeedCandidate) DecodeFromPtr(p capnp.Ptr) MapdSpeedCandidate {
+	return MapdSpeedCandidate(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s MapdSpeedCandidate) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s MapdSpeedCandidate) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s MapdSpeedCandidate) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s MapdSpeedCandidate) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s MapdSpeedCandidate) Reason() SpeedReason {
+	return SpeedReason(capnp.Struct(s).Uint16(0))
+}
+
+func (s MapdSpeedCandidate) SetReason(v SpeedReason) {
+	capnp.Struct(s).SetUint16(0, uint16(v))
+}
+
+func (s MapdSpeedCandidate) Speed() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(4))
+}
+
+func (s MapdSpeedCandidate) SetSpeed(v float32) {
+	capnp.Struct(s).SetUint32(4, math.Float32bits(v))
+}
+
+func (s MapdSpeedCandidate) Distance() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(8))
+}
+
+func (s MapdSpeedCandidate) SetDistance(v float32) {
+	capnp.Struct(s).SetUint32(8, math.Float32bits(v))
+}
+
+// MapdSpeedCandidate_List is a list of MapdSpeedCandidate.
+type MapdSpeedCandidate_List = capnp.StructList[MapdSpeedCandidate]
+
+// NewMapdSpeedCandidate creates a new list of MapdSpeedCandidate.
+func NewMapdSpeedCandidate_List(s *capnp.Segment, sz int32) (MapdSpeedCandidate_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	return capnp.StructList[MapdSpeedCandidate](l), err
+}
+
+// MapdSpeedCandidate_Future is a wrapper for a MapdSpeedCandidate promised by a client call.
+type MapdSpeedCandidate_Future struct{ *capnp.Future }
+
+func (f MapdSpeedCandidate_Future) Struct() (MapdSpeedCandidate, error) {
+	p, err := f.Future.Ptr()
+	return MapdSpeedCandidate(p.Struct()), err
+}
+
+type MapdSpeedLimiter capnp.Struct
+
+// MapdSpeedLimiter_TypeID is the unique identifier for the type MapdSpeedLimiter.
+const MapdSpeedLimiter_TypeID = 0xdf783c4015c7045f
+
+func NewMapdSpeedLimiter(s *capnp.Segment) (MapdSpeedLimiter, error) {
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
+	return MapdSpeedLimiter(st), err
+}
+
+func NewRootMapdSpeedLimiter(s *capnp.Segment) (MapdSpeedLimiter, error) {
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3})
+	return MapdSpeedLimiter(st), err
+}
+
+func ReadRootMapdSpeedLimiter(msg *capnp.Message) (MapdSpeedLimiter, error) {
+	root, err := msg.Root()
+	return MapdSpeedLimiter(root.Struct()), err
+}
+
+func (s MapdSpeedLimiter) String() string {
+	str, _ := text.Marshal(0xdf783c4015c7045f, capnp.Struct(s))
+	return str
+}
+
+func (s MapdSpeedLimiter) EncodeAsPtr(seg *capnp.Segment) capnp.Ptr {
+	return capnp.Struct(s).EncodeAsPtr(seg)
+}
+
+func (MapdSpeedLimiter) DecodeFromPtr(p capnp.Ptr) MapdSpeedLimiter {
+	return MapdSpeedLimiter(capnp.Struct{}.DecodeFromPtr(p))
+}
+
+func (s MapdSpeedLimiter) ToPtr() capnp.Ptr {
+	return capnp.Struct(s).ToPtr()
+}
+func (s MapdSpeedLimiter) IsValid() bool {
+	return capnp.Struct(s).IsValid()
+}
+
+func (s MapdSpeedLimiter) Message() *capnp.Message {
+	return capnp.Struct(s).Message()
+}
+
+func (s MapdSpeedLimiter) Segment() *capnp.Segment {
+	return capnp.Struct(s).Segment()
+}
+func (s MapdSpeedLimiter) Name() (string, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.Text(), err
+}
+
+func (s MapdSpeedLimiter) HasName() bool {
+	return capnp.Struct(s).HasPtr(0)
+}
+
+func (s MapdSpeedLimiter) NameBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(0)
+	return p.TextBytes(), err
+}
+
+func (s MapdSpeedLimiter) SetName(v string) error {
+	return capnp.Struct(s).SetText(0, v)
+}
+
+func (s MapdSpeedLimiter) Enabled() bool {
+	return capnp.Struct(s).Bit(0)
+}
+
+func (s MapdSpeedLimiter) SetEnabled(v bool) {
+	capnp.Struct(s).SetBit(0, v)
+}
+
+func (s MapdSpeedLimiter) TargetSpeed() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(4))
+}
+
+func (s MapdSpeedLimiter) SetTargetSpeed(v float32) {
+	capnp.Struct(s).SetUint32(4, math.Float32bits(v))
+}
+
+func (s MapdSpeedLimiter) Confidence() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(8))
+}
+
+func (s MapdSpeedLimiter) SetConfidence(v float32) {
+	capnp.Struct(s).SetUint32(8, math.Float32bits(v))
+}
+
+func (s MapdSpeedLimiter) Reason() (string, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.Text(), err
+}
+
+func (s MapdSpeedLimiter) HasReason() bool {
+	return capnp.Struct(s).HasPtr(1)
+}
+
+func (s MapdSpeedLimiter) ReasonBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(1)
+	return p.TextBytes(), err
+}
+
+func (s MapdSpeedLimiter) SetReason(v string) error {
+	return capnp.Struct(s).SetText(1, v)
+}
+
+func (s MapdSpeedLimiter) Candidates() (MapdSpeedCandidate_List, error) {
+	p, err := capnp.Struct(s).Ptr(2)
+	return MapdSpeedCandidate_List(p.List()), err
+}
+
+func (s MapdSpeedLimiter) HasCandidates() bool {
+	return capnp.Struct(s).HasPtr(2)
+}
+
+func (s MapdSpeedLimiter) SetCandidates(v MapdSpeedCandidate_List) error {
+	return capnp.Struct(s).SetPtr(2, v.ToPtr())
+}
+
+// NewCandidates sets the candidates field to a newly
+// allocated MapdSpeedCandidate_List, preferring placement in s's segment.
+func (s MapdSpeedLimiter) NewCandidates(n int32) (MapdSpeedCandidate_List, error) {
+	l, err := NewMapdSpeedCandidate_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return MapdSpeedCandidate_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(2, l.ToPtr())
+	return l, err
+}
+func (s MapdSpeedLimiter) ReasonCode() SpeedReason {
+	return SpeedReason(capnp.Struct(s).Uint16(2))
+}
+
+func (s MapdSpeedLimiter) SetReasonCode(v SpeedReason) {
+	capnp.Struct(s).SetUint16(2, uint16(v))
+}
+
+// MapdSpeedLimiter_List is a list of MapdSpeedLimiter.
+type MapdSpeedLimiter_List = capnp.StructList[MapdSpeedLimiter]
+
+// NewMapdSpeedLimiter creates a new list of MapdSpeedLimiter.
+func NewMapdSpeedLimiter_List(s *capnp.Segment, sz int32) (MapdSpeedLimiter_List, error) {
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 3}, sz)
+	return capnp.StructList[MapdSpeedLimiter](l), err
+}
+
+// MapdSpeedLimiter_Future is a wrapper for a MapdSpeedLimiter promised by a client call.
+type MapdSpeedLimiter_Future struct{ *capnp.Future }
+
+func (f MapdSpeedLimiter_Future) Struct() (MapdSpeedLimiter, error) {
+	p, err := f.Future.Ptr()
+	return MapdSpeedLimiter(p.Struct()), err
+}
+
 type MapdOut capnp.Struct
 
 // MapdOut_TypeID is the unique identifier for the type MapdOut.
 const MapdOut_TypeID = 0xa4f1eb3323f5f582
 
 func NewMapdOut(s *capnp.Segment) (MapdOut, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 64, PointerCount: 5})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 120, PointerCount: 8})
 	return MapdOut(st), err
 }
 
 func NewRootMapdOut(s *capnp.Segment) (MapdOut, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 64, PointerCount: 5})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 120, PointerCount: 8})
 	return MapdOut(st), err
 }
 
@@ -2360,12 +3011,231 @@ func (s MapdOut) SetSpeedLimitAccepted(v bool) {
 	capnp.Struct(s).SetBit(226, v)
 }
 
+func (s MapdOut) WayId() int64 {
+	return int64(capnp.Struct(s).Uint64(64))
+}
+
+func (s MapdOut) SetWayId(v int64) {
+	capnp.Struct(s).SetUint64(64, uint64(v))
+}
+
+func (s MapdOut) WaySelectionConfidence() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(60))
+}
+
+func (s MapdOut) SetWaySelectionConfidence(v float32) {
+	capnp.Struct(s).SetUint32(60, math.Float32bits(v))
+}
+
+func (s MapdOut) DeadReckoning() bool {
+	return capnp.Struct(s).Bit(227)
+}
+
+func (s MapdOut) SetDeadReckoning(v bool) {
+	capnp.Struct(s).SetBit(227, v)
+}
+
+func (s MapdOut) PositionStale() bool {
+	return capnp.Struct(s).Bit(228)
+}
+
+func (s MapdOut) SetPositionStale(v bool) {
+	capnp.Struct(s).SetBit(228, v)
+}
+
+func (s MapdOut) PositionSource() (string, error) {
+	p, err := capnp.Struct(s).Ptr(5)
+	return p.Text(), err
+}
+
+func (s MapdOut) HasPositionSource() bool {
+	return capnp.Struct(s).HasPtr(5)
+}
+
+func (s MapdOut) PositionSourceBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(5)
+	return p.TextBytes(), err
+}
+
+func (s MapdOut) SetPositionSource(v string) error {
+	return capnp.Struct(s).SetText(5, v)
+}
+
+func (s MapdOut) GpsFixAge() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(72))
+}
+
+func (s MapdOut) SetGpsFixAge(v float32) {
+	capnp.Struct(s).SetUint32(72, math.Float32bits(v))
+}
+
+func (s MapdOut) GpsAccepted() uint32 {
+	return capnp.Struct(s).Uint32(76)
+}
+
+func (s MapdOut) SetGpsAccepted(v uint32) {
+	capnp.Struct(s).SetUint32(76, v)
+}
+
+func (s MapdOut) GpsRejected() uint32 {
+	return capnp.Struct(s).Uint32(80)
+}
+
+func (s MapdOut) SetGpsRejected(v uint32) {
+	capnp.Struct(s).SetUint32(80, v)
+}
+
+func (s MapdOut) GpsFixStatus() GpsFixStatus {
+	return GpsFixStatus(capnp.Struct(s).Uint16(58))
+}
+
+func (s MapdOut) SetGpsFixStatus(v GpsFixStatus) {
+	capnp.Struct(s).SetUint16(58, uint16(v))
+}
+
+func (s MapdOut) LaneFromLeft() uint8 {
+	return capnp.Struct(s).Uint8(84)
+}
+
+func (s MapdOut) SetLaneFromLeft(v uint8) {
+	capnp.Struct(s).SetUint8(84, v)
+}
+
+func (s MapdOut) LaneFromRight() uint8 {
+	return capnp.Struct(s).Uint8(85)
+}
+
+func (s MapdOut) SetLaneFromRight(v uint8) {
+	capnp.Struct(s).SetUint8(85, v)
+}
+
+func (s MapdOut) LaneConfidence() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(88))
+}
+
+func (s MapdOut) SetLaneConfidence(v float32) {
+	capnp.Struct(s).SetUint32(88, math.Float32bits(v))
+}
+
+func (s MapdOut) VisionCurveRoll() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(92))
+}
+
+func (s MapdOut) SetVisionCurveRoll(v float32) {
+	capnp.Struct(s).SetUint32(92, math.Float32bits(v))
+}
+
+func (s MapdOut) VisionMapAgreement() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(96))
+}
+
+func (s MapdOut) SetVisionMapAgreement(v float32) {
+	capnp.Struct(s).SetUint32(96, math.Float32bits(v))
+}
+
+func (s MapdOut) LeavingMappedRoad() bool {
+	return capnp.Struct(s).Bit(229)
+}
+
+func (s MapdOut) SetLeavingMappedRoad(v bool) {
+	capnp.Struct(s).SetBit(229, v)
+}
+
+func (s MapdOut) CurveSpeed() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(100))
+}
+
+func (s MapdOut) SetCurveSpeed(v float32) {
+	capnp.Struct(s).SetUint32(100, math.Float32bits(v))
+}
+
+func (s MapdOut) CurveMapWeight() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(104))
+}
+
+func (s MapdOut) SetCurveMapWeight(v float32) {
+	capnp.Struct(s).SetUint32(104, math.Float32bits(v))
+}
+
+func (s MapdOut) CurveVisionWeight() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(108))
+}
+
+func (s MapdOut) SetCurveVisionWeight(v float32) {
+	capnp.Struct(s).SetUint32(108, math.Float32bits(v))
+}
+
+func (s MapdOut) WayOverridden() bool {
+	return capnp.Struct(s).Bit(230)
+}
+
+func (s MapdOut) SetWayOverridden(v bool) {
+	capnp.Struct(s).SetBit(230, v)
+}
+
+func (s MapdOut) SpeedLimiters() (MapdSpeedLimiter_List, error) {
+	p, err := capnp.Struct(s).Ptr(6)
+	return MapdSpeedLimiter_List(p.List()), err
+}
+
+func (s MapdOut) HasSpeedLimiters() bool {
+	return capnp.Struct(s).HasPtr(6)
+}
+
+func (s MapdOut) SetSpeedLimiters(v MapdSpeedLimiter_List) error {
+	return capnp.Struct(s).SetPtr(6, v.ToPtr())
+}
+
+// NewSpeedLimiters sets the speedLimiters field to a newly
+// allocated MapdSpeedLimiter_List, preferring placement in s's segment.
+func (s MapdOut) NewSpeedLimiters(n int32) (MapdSpeedLimiter_List, error) {
+	l, err := NewMapdSpeedLimiter_List(capnp.Struct(s).Segment(), n)
+	if err != nil {
+		return MapdSpeedLimiter_List{}, err
+	}
+	err = capnp.Struct(s).SetPtr(6, l.ToPtr())
+	return l, err
+}
+func (s MapdOut) ActiveSpeedLimiter() (string, error) {
+	p, err := capnp.Struct(s).Ptr(7)
+	return p.Text(), err
+}
+
+func (s MapdOut) HasActiveSpeedLimiter() bool {
+	return capnp.Struct(s).HasPtr(7)
+}
+
+func (s MapdOut) ActiveSpeedLimiterBytes() ([]byte, error) {
+	p, err := capnp.Struct(s).Ptr(7)
+	return p.TextBytes(), err
+}
+
+func (s MapdOut) SetActiveSpeedLimiter(v string) error {
+	return capnp.Struct(s).SetText(7, v)
+}
+
+func (s MapdOut) SuggestedSpeedReason() SpeedReason {
+	return SpeedReason(capnp.Struct(s).Uint16(86))
+}
+
+func (s MapdOut) SetSuggestedSpeedReason(v SpeedReason) {
+	capnp.Struct(s).SetUint16(86, uint16(v))
+}
+
+func (s MapdOut) SuggestedSpeedReasonDistance() float32 {
+	return math.Float32frombits(capnp.Struct(s).Uint32(112))
+}
+
+func (s MapdOut) SetSuggestedSpeedReasonDistance(v float32) {
+	capnp.Struct(s).SetUint32(112, math.Float32bits(v))
+}
+
 // MapdOut_List is a list of MapdOut.
 type MapdOut_List = capnp.StructList[MapdOut]
 
 // NewMapdOut creates a new list of MapdOut.
 func NewMapdOut_List(s *capnp.Segment, sz int32) (MapdOut_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 64, PointerCount: 5}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 120, PointerCount: 8}, sz)
 	return capnp.StructList[MapdOut](l), err
 }
 
@@ -2377,178 +3247,275 @@ func (f MapdOut_Future) Struct() (MapdOut, error) {
 	return MapdOut(p.Struct()), err
 }
 
-const schema_b526ba661d550a59 = "x\xda\x94X}l\x15e\xba\x7f\x9ey{z\xda\xf2" +
-	"q\xce\xe9{\xc0\x0f\xe4V\x14L.\x91+\xb5r\x05" +
-	"\xbcZK\x8f\xa8\xcdA:\x1d\xb0\xd0`\xe2\xf4\xcc\xdb" +
-	"vt:s\x9cyOK\x89\x86{\xb9\xde\xc4p\xd7" +
-	"\xb8\xee\xaa\x01\x03Q\x04\x13wWD]\xdc\x18\xa2Y" +
-	"%\x9aP\x96&b\x96\xcd\xa2!\x82\xc9\x06q5\xea" +
-	"\xaed\xd5\x95\xcc\xe6y\xe7|\x15\xce.\xf4\xaf\xd3\xf9" +
-	"=\xbfy\xbe\xdeg\x9e\xe7y\xbb8Q\x7f{]\xeb" +
-	"\x8c}M\xa0\xe9\x0f\xc7\xea\xc3\xd4\x83\xeb?u\xe5+" +
-	"\xff\x0d\xa99\x18\xaeoZ;w\xe0\xc0uo@]" +
-	"\x1c\xa0\xed\xd6X\x1fr=\x16\x07\x16\xee\xfb6\xbb\xac" +
-	"\xef\xeb\x83\xffS\x83\xb5\x88X\x1d\x8a\xb5\xae\xf3\xe0\x13" +
-	"\xfd\xd7=\xf7\x7f\x90\x9a\xa3UX\x80msc]\xc8" +
-	"[c\x11}\x1f\x03\x0c\x9b\xf7\xe3\xe0\xe0\xa1\x89\x1d5" +
-	"\x14\x9ei\xe8G~\xae\x81\x14.\xfa\xb8\x85e\xe2C" +
-	"\xbbj\xb0\x8e7\xf4!\xffR\xb1\x1c\xd6v\xfbw\xfd" +
-	"\xf5\xbb'\xb3b\x8ch\xe3D;\xd1\x10\x07\xe0\xc7\x1b" +
-	"\xf6\x01\x86[\xce\x9e\xbd\xb6\xed\xcf\xdf\xec\x01}\x0e6" +
-	"T\xb1\x95s\x8f7^\x89|g#\xfd\xb9\xbd\xf1\xce" +
-	"\x18`\x98\xe9|\xe7\xf0\xdeOv\xbfxAH\xdb\x13" +
-	"[\x90\xefM\x90\xe2_$n\x06\x0c\xd7\xee\xd1\x8f_" +
-	"?2\xf1b\x0d_\xf7&\xfa\x90\xbf\x9b __\xf9" +
-	"8\xf7\xf4\xff\xbf\xf4\xd5\xaf.\xd0\xb73\xb1\xbc\xa2o" +
-	"5`x\xdf\x1b\xa7[\x9f8\xf7\xc9+5\xf4\xbdM" +
-	"\xfa\x8e*}\xed\x9f|\xfe\xc8\xaa\xab{_\xadi\xb5" +
-	"\xbfd\xf5\x10f\xe7\xd99\xf7\xcd\x1a\xac\x9d\xa4\xeb5" +
-	"\xc5\x1az\xeb\xe7O\x19\xb7d\xde\xae\xc1z\x9ct\xed" +
-	"R\xacG\xd9M'\xc5\x7f\xcd?X\x83\xf5\x08\xb1\x9e" +
-	"T\xac\xaf~}\xdb\xd1\xa5\xb7>p\x88\xd2\\}(" +
-	"H\xbc\xe1D3\xf2GT\xacc\x89\xd3\x80\xe1\xf2\xf5" +
-	"\xbdy\xe7\xd8s\xbf\xab\xa1sU\xb2\x1f\xb9\x99$\x9d" +
-	"\x1fly~\xf0\xef\x1f=3Q\xabT\x89\xa5+\xd6" +
-	"\xb2eo\xed:\xb2\xf5o\x7f \xcb\xec\xfcZMv" +
-	"!\xefH\x92\xe1[\x93dxI6\xf3\xbf\xdbz\x9f" +
-	"\xf9\xa8\x86\xcay\xa9>\xe4KR\xa4\xf2\xa0\xfb\xdbi" +
-	"\xf7\xbe\xb7\xe1\xaf5X)b-P\xac+v\xee\xbc" +
-	"\xbb\xf1\x8b\xd9\xdf\xd6`!\xb1f)\xd6\xee\xba\xfc\xb9" +
-	"[\x1e}\xfc\xfb\x1a\xaco\x92}\xc8c\x8a\xb5\xf0\xb3" +
-	"\x05\xf7\x9c\xdep\xdb\x0f\x17\x94\xc9)\x0a\xf4\xac\x8a\xe0" +
-	"\x9b\xe4f\xc0\xf0\x85\xa0\xfb\xd0\xe1\xfbv\xff@\xe1V" +
-	"Qc\x1ailMmA~G\x8a\xfe\xecH\xf5\"" +
-	"`\x98\xfa\xcd\xe8c_v\xf4\xffX\xc3\xfc\xbb\xcd\xfd" +
-	"\xc8\x8f5\x93\xf9\xcd\xdb^=ml{,\xacyz" +
-	"\xaf5\x1f@>\xdeL.\xbc\xdf\xbc\x0f\x16\x859\xe1" +
-	"\x0b\xd3\xb9!WW\x08\xa47|CN\xfd\xfcG\xce" +
-	"\xcc\xbb\xf9\xe5\x9d\xea\xa1G\x04\xc2\x1f\x11\xcc\xba\xa9\x1b" +
-	"q*\xfc\xc5\x17\xe3\xaf2\xf3\xd6\xddn\xbe \xd7\x8c" +
-	"\xe5\x05@7\xa2\xbe\x035\x00>\x81]\x00\x88|\x1c" +
-	"_\x07@\x8d\x8f\xe3\x0b\x00\xc8\xf88\xfe\x12\x00\xeb\xf8" +
-	"8\x1e\x04\xc0\x18\x1f\xc7\x8f\x00\xb0\x9eO`?\x00\xc6" +
-	"\xf98\x1e\x06\xc0\x06>\xa1~\x1b\xf9Q\xdc\x04\x80M" +
-	"|\x02\x1f\x00\xc0i|\\=O\xe7\xef\xe3\xe7\x008" +
-	"\x83\x8f\xe3\x87\x008\x93O\xe0\xa7\x00\x98\xe0G\xd5s" +
-	"\x92\x1f\xc3g\x010\xc5\x8f)\xbb\xcd\xfc\x98\xd2\xc7\xf9" +
-	"q\xf5\x9c\xe6\xc7\x95_\xb3\x8a\xcf\xb3\xf9q\xe5\xcfe" +
-	"\xfc\xb8\xd2{9?\xa1\xf4]\xd1v\x0a\x97#\x00^" +
-	"\xc9\xcf\xe0\x01\x00\x9c\xc3\xcf(\x07\xae\xe2\x7f\xc2>\x00" +
-	"\x9c\xcbO)\xc7\xfe\x8d\x9fP/\xb6\xf0SJ\xf1\xd5" +
-	"\xc5\xdfym\xa7\xb0\x99\x14\\\xc3\xcf\xe0V\x00\xbc\x96" +
-	"\x9f\xc1\xbf\x00\xe0\xfc\xb6/\xf1\x1a\x12,\xe0gU\x0a" +
-	"\xaek\xfb\x1e5\x04\x08-o\xd4u<\xd3\x02\x800" +
-	"\x10r\x8d\xe9\x0f\x0a\x94YS\x0a\xdftZ:r9" +
-	"\xe1\x10n\xe4\x85\xb00k\x0f\xdbr\xf5\xc0@<\x10" +
-	"\xf2<\xb4\xd3s\x13\xd2\xf7\x14y\x95\x99\xef,\xc4\xfc" +
-	"\x11\xa1\xe4\x9d\x9eK\x02\x08\x84\xbc\xd7\x0el\xcf\xed," +
-	"L\x12\xb1\xe8\xa5\xac7\x98\x15\x10\x1f\x89\xec)\xa6\x16" +
-	"Q\x95O\xe4RG\xe4c\xb5l\x95\xedF\xe2{\x01" +
-	"B_P$\x86\x80v)mw0\x08\x03sD\x18" +
-	"BJHD\x8fB\xde\xe1\x9a\xfd\x0e\xb4G\xe6\xcfW" +
-	"\xb66\x10J.\x8cDI\xacB\xd1&\xc9\xf2B\xa0" +
-	"U\x8e^S\xd1WI\xe3\xc57\xef\xf2\x1c+\xab\x99" +
-	"\x814\x84p\x15\x95\x98(\xab\xb2\xac\xd0.\xc1\xfc\x07" +
-	"\xcf\x07;r\xf1b\xe2\x15\xaaE\xe8\x1a{X\xac\x1e" +
-	"\x18\x08\x84\x8c\x12\x91\x11\x03f\x01\x1d\x995]\xd1k" +
-	"\xc7-9Tv\x19KykQ\x89\x0b)1D\xc7" +
-	"\x82#)#v\x9c\x12Bh\x8f\xc8y\xb1\xe1a\xe1" +
-	"Z\xc2R\x12w0\xa0\xb32\x1co4\xe3\x8d\xba+" +
-	"=\xff\x1e\xb11r \x9b\xa0`+\xb1\xaf\xcdO\x92" +
-	"\xda\xf1\xa2\x94b7X)f\xd9;d;\xa2s\xc8" +
-	"t\x07mw\xd0\x10\xed\x11]Y\xef\x16~\x80v " +
-	"\x85+I\x10\x1d[\xcets\xc2\xc9x\xd0\x1e\xd5f" +
-	"\xb1<\xba\x02`\x9e[|0<H\x14\xfc\x9cP\x87" +
-	"\xbaQ\x0a_sM\xa7\x9c\xe6I\xe5\xa8\xc4X\x12\xb7" +
-	"d'\xc5\x10Uo\xb7o\xb7x\xbe-\xc7\xca8\x8b" +
-	"\xd4\x90\xd3\xa2G<T\xb0}\x11\xd0\xd7\x90G\x19\x9a" +
-	"\xf4+\x8d<\x96\xacE\xc7\xd1\xed\x8b \xd0\xee4\x83" +
-	"5^G\x911\xc9^\x87\xf5@!`\x94\xfe\xe84" +
-	"\xabY\x95\xdc)P\x93\x95P\xe8\xd4=V\x90e\x13" +
-	"\x0d\xca\xc4\xea\x11\xe1\xfb\xb6%*D(\xb5\xce\x8bu" +
-	"\xda\xb8\xd5z\xe3\x14[\xf3\xb2Ki\xcd\x94h*\xa3" +
-	"\xd5\xac \xa97Ogu\x00u\x08\x90\xbac+\x80" +
-	"~\x17C}\x8d\x86)\xc44\x12\xa8w\x01\xe8\xdd\x0c" +
-	"\xf5\x0d\x1a\xa64-M\x9d<\xb5~!\x80\xbe\x86\xa1" +
-	"\x9e\xd7\xb0\xdc\x9c\xb0\xdb\xf7\x06)tj\xb6\x95!\x08" +
-	"\x88I@\xcaKT\xb5\xd4\xaaA\xc3\xe9\x80\x89\xbc)" +
-	"\x87p&`7CLV\xb6\x04@\x02\xcb\x81\xb0\x7f" +
-	"\x12H)\x80\xfbK\x01\xf0'\xb5\x15\x00\xc6O4\x86" +
-	"\xc66\xad\x12\x03\x7fZ[\x0e`\xfc\x94\xf0\x1dZ%" +
-	"\x0c\xbe]\xeb\x020\xb6\x11\xbeG\xd3\x10Y\x1a\x19\x00" +
-	"\xdf\xa5\xf5\x01\x18\xcf\x13\xfc2\xd1\xeb0\x8du\xb4\x04" +
-	"j\x9b\x00\x8c\x97\x08\xdfOxLKc\x0c\x80\xbf\xa6" +
-	"\x1d\x000\xf6\x13\xfe\x0e\xe1\xf5,\x8d\xf5\x00\xfcme" +
-	"\xf6M\xc2\xdf#<^\x97\xa6i\xcd\xdfU\xfa\xdf!" +
-	"\xfc\x08\xe1\x0d,\x8d\x0d\x00|\\{\x16\xc08B\xf8" +
-	"\x1f\x09o\xacKc#\x00?\xa6\xf9\x00\xc6\xef\x09?" +
-	"IxS,\x8dM\x00\xfc\x84\xf63\x00\xe3$\xe1_" +
-	"\x10>\xad>\x8d\xd3\x00\xf8\x19\xedC\x00\xe3k\xc2\x7f" +
-	"$|\xfa\xc94N\x07\xe0\xdf+\x7f\xbe\xd5\x18\xf60" +
-	"\x0dS3\xe6\xa6q\x06\x00?\xa7\xdd\x08`|G\xf4" +
-	":\xc2g\x9eJ\xe3L\x00\x8e\xac\x0f\xa0\x8714\xa6" +
-	"\x13\x9chHc\x02\x807\xb2\xc3\x00F\x92\xf0\xab\x08" +
-	"O6\xa61\x09\xc0\xaf`\x94\x9d\xcb\x09\x9fOx\xaa" +
-	")\x8d)\x00>\x8fQT\xf3\x09_Lxs\"\x8d" +
-	"\xcd\x00|\x11\xeb\x070\xae'|)\xe1|Z\x1a9" +
-	"\x00_\xc2^\x070\x96\x12\x9e!<==\x8di\x00" +
-	"\xde\xc1\xb6\x02\x18\x19\xc2\xbb\x09\x9f5#\x8d\xb3\x00\xf8" +
-	"*F\xd9\xc9\x12\xbe\x8e\xf0\xd9W\xa5q6\x00_\xab" +
-	"\xf8\xeb\x08\xb7\x08\xbf\xec\xd34^\x06\xc0M\xe5\x8fE" +
-	"x\x9ei\xb8y\xd4\x1c\xbb\xc7\x1c\x16\xa5\xa2l\x1f5" +
-	"\xc7z\xc4@\xe91\xf4=\xd3\"yU\xdd\x86A\xf1" +
-	"k\x06fKl\x02\x0d\x9b\x00C\xb7\xd8a\xa1=\xfa" +
-	"\xd0/\x10`\x84g\xec\xf6@R\xef,\x11\xda\x87\xcc" +
-	"M\xa6o\x95\xb5\x13\xff.s\x93\x09\xac\x06\x88\xbe\x95" +
-	"\xb1\xe9}VQ\x10\x9a\xd6\x88\x1dx\xfe\x18\xb4D\xdd" +
-	"\xb2\xdar\x875b#\x09#\x17.\x90i%YQ" +
-	"o\x0e+\x8ey\xae\xe85\xc7\x10AC\x04lqL" +
-	"W\x04X\x0f\x1a\xd6\x03\x86\xd2vD\x96v\x12&\xac" +
-	"\x12\xa5\x9c\x19\xcd\x96FapP\x04RXJ9-" +
-	"lE\xcbAQ\x00\xed\xd6dwE \xedaS\x0a" +
-	"\xb4z<\xd3\xea\xb5-&\x87\xcaB:\x07\xdaD " +
-	".6JLTnm\x80\x98\x00\x0c-\xbb\x98\xd5\x95" +
-	"\xbe7\xdck\x8eu\xb6\x08\x97&J\xe9\xfd\x91\xe2V" +
-	"\x83\xa5\xb5\xa6\xca\xa3a\x1a\xc6\xfe\x888?\x7f\xa3\xe6" +
-	"\x98!\x1c\x91Ci{n\xb4\xcdb\xa2r\x11(Z" +
-	".\xc5\x8cv4\x16duBJM,V\xa3\x89U" +
-	"\x86B\xb4/(\x0b\xd4\xd1\x1aT\x93M-'\x0b\xa9" +
-	"\xc6\x15\x00T1\xd2\xcem\xce\x0b?'\\9\x95\x99" +
-	"\xb0\xa4z&\xd4j\xa5\x94\xeb\xcev\xcf\x95bc4" +
-	"\x0f\x94\xf1\xb9+\x94\xf1Y\x0biSO\xcdX\x01\xb0" +
-	"y\xc0\x17b\xd4\x1cK\xe4l9\xb6\xb9\xe0>\xe8z" +
-	"\xa3\xeeT<i\x9d\xd24\x8b[\xadS\xbd\x99\xdc<" +
-	"U\x03K\xa6\xfaB\xeb\xc5\x92\xa9\xee>\xe8R\x1e\x93" +
-	"\xe5\xb9j\xd2\xb4\xdc\xc0P\x1f\xaa\x9a\xab\xe2F\x00\xfd" +
-	"~\x86\xba\xa3!\x16\xc7\xaa}\x0d\x80nEc5\xc5" +
-	"\x92j\x1a\xa5\x86\xe9\xed!\x86\xba\xd40!\xc7\xf2\x02" +
-	"\x13\x95\xff\xeaD%\xd82\xe0xf\xb9\xe1\xc4\x03\xe9" +
-	"\x97Gl\xbf\xe79\x17\x14\xe3\xa5\x84\xbax\xaa\xb9i" +
-	"\xbb\x94\xe5\xa3\xdb\x94C\xdd\x9e\xed\xca\xe8^X\x95\xa3" +
-	"\xaeJ:\xca9\xb2{*\xa1\xa7\xb4\xba(I\x0f\x11" +
-	"\x98g\xa8?LI\x8aEI\x1a\xdb\x04\xa0od\xa8" +
-	"?\xaaa\xe8\x98\xd2\x96\x05K\xb5\xeci\xa0\xe14\xc0" +
-	"\xd0\xf1\xdcA\x02\x01E\x19\xcb\x15\xfc\x11S\x16|\xa8" +
-	"\xb4\xbcPF\xf7\x14\x01\xed\x8eG\x85^\x16L\xa1\x0e" +
-	"\xdb\xa6X\xb7S]\xf3\x96N\x91\xff\x9f\x17\xe3\xf7\x16" +
-	"\xfb\x9cjs\xf1\xb1\xbc\xa0\xb3\x89\xb2\xbd$\xea\x03\x8b" +
-	"zT\x1f\xf8w\xba\xc0\xb3\xd4\x02\xfa\xa9K\xcd[\x08" +
-	"\xb09W\xf0}jIy_XvN\x0a@+\xcc" +
-	"{A`\xf7;t\x00\xa1(\xae\x9b\x00\x90\x180m" +
-	"\xe7_vD*\x91Lq\x9d,o\x93\xe4\xcc\xe5\xe5" +
-	"B\xd9\xbe\x1c@\x7f\x8a\xa1\xfe|U\xa1\xec\xa4\x9a\xd8" +
-	"\xc1P\x7f\x89\x0a\x05#\xd7_\xec\x03\xd0\xf70\xd4_" +
-	"\xa5B\xd1\xa2B\xd9\xbb\x05@\x7f\x99\xa1\xfe\xa6\x86X" +
-	"\xa7\xf6\xba\xd4\x1b\xf4\xf6~\x86\xfa\x07\xb4\xd4\xa1Z\xea" +
-	"R\x13D<\xc2P\xffL\xc3v3'\xed\x11Q\xf9" +
-	"\x8e\xd4\xd5\xc7\xa1X\xcb\x98\xf4\xa4\xe9\xac\xb4\x1d`\"" +
-	"\xc0\x06\xd0\xb0\x01\xaaVca\xad\xb4\x1d\x11@Y\xe2" +
-	"x9\x93\xd2\x0d\x18\x946`\xfadgV\x890#" +
-	"\xa4i;\x01TV\xe4\xf2?\x81\xce[\x91/\xe5\xfb" +
-	"\x9cT\x05\x17K~\xb6\xe8C\xa6=\xf2\xe1\xbc\x8b\x02" +
-	"}\xac\x19\x86z\xb7\x86\xa5#XE\xd9\xce2\xd4\xd7" +
-	"U\x1d\xc1\xda-\xc5{\xc2\xfdZU\xc8U\x9b\xd4\xd4" +
-	"\xb2\xf6\x8f\x00\x00\x00\xff\xff\xc7\xba\x9eE"
+const schema_b526ba661d550a59 = "x\xda\x94y\x7fp\\\xd5u\xff9\xefiwe[" +
+	"b\xbd\xbe\xcf`\xfd\\\xd9\xd8\x80\x85M,\x0b'\xb6" +
+	"\x81\x08Y\xc2\xc1\x1e\x09\xebim\x04\x1a3\xdf<\xed" +
+	"\xbbZ={\xf7\xbd\xf5{w%\xad'\x8c\x83\xbff" +
+	"Jh\x18 \x81\x8cIq\x03\x01ZH\x03\x98\x14w" +
+	"\x801m\xc2\xa0\xa9M\xf1\x0c\xce\x84N!a \xb4" +
+	".\x84\x92!\xb4x\x8a\x93z\xb6s\xee\xdb}\xbbZ" +
+	"m1\xfak\xf5>\xf7s\xcf=\xbf\xee\xbd\xe7\\\xad" +
+	"\x9bYp}]Wc\xaa\x01\x14\xfd\xbeP\xb8\x10\xdb" +
+	"{\xeb\xfb\xb6x\xe6\xdb\x10k\xc1\xc2\xad\x0bw\xb5\x8d" +
+	"\xbft\xd91\xa8\x8b\x00t7\x85G\x91\xad\x0dG@" +
+	"-<\xfb\xd9\xc0\xa6\xd1?\xbcrG\x0d\xd6\x02b\xb5" +
+	"I\xd6-}\xaf\xdc;v\xd9\x8f\xee\x84X\x8bRf" +
+	"\x01v\x9f\x0bmG\xd6\x18\xf6\xe9\x7f\x1d\x02,\xbc\xfa" +
+	"\xfbu\x9a}x\xef\xfd\xa0\xb7`\x057\x84\xc49\xb6" +
+	"h\x14\xd9\xc9E\x11\x006\xb3\xe8\x03\xc0\xc2\x92\xe71" +
+	"\x95:q\xea\xe1\x1a\xcb\x1fi\x18C\xf6\\\x03-\xbf" +
+	"\xf6\xd7q\xb5?2\xf1h\x0d\xd6=\x0d\xa3\xc8\x1e\x95" +
+	"\xac\xb4\xda}\xfd\xe7c\xe1\xc7f\xb3B\x92v;\xd1" +
+	"\xeeo\xa0\x85\xefi\xa0\x85\x0f\x9e={i\xf7\x7f|" +
+	"\xfa8\xa9\x19\xad`\xd7K\xa1\x8d\xcd\xc8\x8e4\xd2\x9f" +
+	"\x0f5\xeeX\x08X\xe8\xef\xfb\xf9kO\xbf\xfb\xd8\x13" +
+	"s\x1cpg\xd3Ad\x0f5\x91\xe0\x07\x9b\xbe\x06X" +
+	"\xd8\xf5\xb8\xfe\xd6\x9a\xc9SO\xd4\xd0\xf5\xa1\xa6Qd" +
+	"O7\x91\xae\xcf\xfc:\xf9\xe0\x9f?\xf9\xc9\xdf\xcc\x91" +
+	"\xf7\x9d\xa6\xcdey;\x00\x0b\x0f\xef\x9bx\xea\xec{" +
+	"\xed\xcf\xcca>E\xcc\x17\x9b\xa4[\x9b\xa2\x08X\xb8" +
+	"\xed\xd8\x07]\xf7\x9e\x7f\xf7\x99\x1aK\xefk\x1eEv" +
+	"g3-\xdd\xf3\xeeG\xb7\x0fv\x8c\x1c\xad\xc12\x9a" +
+	"\xc7\x90\xe5$\xeb\x04\x0e,\xb7\x92\xf6\x0b5X:\xc9" +
+	"\xe2\x925q\xfc\xfb\x0f$\xae\xe9\x7f\xb9\x06\xab\x97d" +
+	"\xed\x92\xac\xa3\xc7>y\xf5\xcfv}\xff\xef\xe7\x98\xd0" +
+	"\xd5\xbc\x05Yo3\x19{]\xf3\x01\xc0\xc2!\xf5\xea" +
+	"\xf7\xf8\xb5+_\xa9!/O\xf2\xee\x91\xf2>\xf9\xdb" +
+	"\xaf\x9f\xdex\xdd\x9e\x13\x14;\xacN1\xaby\x09\xb2" +
+	"\xbc\x94\x99k\xa6Ho\xbeu$\x9b~\xf3G\xffT" +
+	"C\xe6\xb6\x961d\xb7\xb5\x90\xcc7\x0e>\x92\xfa\xd3" +
+	"\xdb?8U\x83\xb5\x89X\x83\x92\xb5i\xd3\xf1G_" +
+	"\xbf\xfb\xbf\xff\x99V\x0eU\xd1V\xb7lGv]\x8b" +
+	"?c\x04\x01\x0b\x1b\x06\xfa\xff\xff\xe1\x91\x1f\xbc]C" +
+	"\xe6\x83\xad\xa3\xc8\x9ej%\x99\xff\xaf\xee\x1f\x97^\x7f" +
+	"\xed\xf4\xbb\xd5\x1bF%\xde\x9d\xadc\xc8\x1ej\xf5\xa7" +
+	"\xdcKB_\xb1\xffa\xd1\xcd\xaf\xee\xfe\xaf\x1aB\xaf" +
+	"k\x1fE\xa6\xb7\x93\xd0\xa6#G\xb6-\xf8\xf8\xe2\xcf" +
+	"j\xb0\xd6\x12\xabW\xb2\x1e\xab\xcb\x9e\xbf\xe6\xd0=\xe7" +
+	"j\xb0\xda\x88\xd5%Y\x9d\x1f\xae\xba\xe9\x83\xdd_\xff" +
+	"\xe3\x9c\xf05\xb6\x8f![\xdeN\xaenk\x7f\x16\xb0" +
+	"\xf0co\xe8\xc4k\xb7=\xf6\xc7jS\x14\x92xG" +
+	"\xfbAd\x0f\x12\xbb\xfb\xfev\xe9\x9f\xcb\x07\xfe\xf2\xaf" +
+	"\xf6\xffE\xd3\x9f\xaa\xe8r\xfdX\x87\x8blU\x07\xc9" +
+	"^\xdeA\xb2c\x7f7u\xd7\xef{\xc7\xfe\xa7\x86\xae" +
+	"/w\x8c!;\xddA\xba\x1e8|\xf4\x83\xc4\xe1\xbb" +
+	"\x0a5S\xe3\xe9\x8e\x97\x90\xcdH\x99\xbf\xe8x\x16\xd6" +
+	"\x16\x92\xdc\xe5F\xfa+\xc9\xba\x9c'\x9c\xccW\x92\xf2" +
+	"\xe7\xaa\xa4\x91\xb5\xb3\x9b\xfb\xe4\xc70\xf7\xb8;\xc9U" +
+	"\xf3\xea!\xc4\xf9\xf0\xd7]\x88?hd\xcdmv6" +
+	"'v\xe6\xb3\x1c`\x08Q\xff\x03*\x00\xec\xa4\xb2\x1d" +
+	"\x00\x91\xcd(?\x03@\x85\xcd(?\x06@\x95\xcd(" +
+	"?\x01\xc0:6\xa3\xbc\x02\x80!6\xa3\xbc\x0d\x80a" +
+	"vR\x19\x03\xc0\x08\x9bQ^\x03\xc0zvR\xfe." +
+	"`\xa7\x94\xfd\x00\xb8\x90\x9dT\xf6\x00\xe0\"6#\xbf" +
+	"\x1b\xd8/\x94\x8f\x00\xb0\x91\xcd(\xbf\x04\xc0\x8b\xd8I" +
+	"\xe5}\x00\x8c\xb2S\xf2{1;\xad\xfc\x10\x00c\xec" +
+	"\xb4\\w\x09;-\xe51\xf6\xa6\xfc\xd6\xd8\x9bR\xaf" +
+	"\xa5\xc5\xef\x8b\xd9\x9bR\x9fK\xd8\x9bR\xee2\xf6\x96" +
+	"\x94\xd7\xd4\xfd\x8e\xb2\x19\x01\xb0\x99\x9dQ^\x02\xc0\x16" +
+	"vF*\xd0\xca~\xab\x8c\x02`\x1b{G*\xd6\xce" +
+	"\xde\x92\x13\xe3\xec\x1d)\xb8\xa3\xf8\xbb\xbc\xfb\x1de\x09" +
+	"\x02\xe0\x0avF\xb9\x1b\x00/eg\x94\xff\x04\xc0\x95" +
+	"\xdd\xbfSV\xd0\xc0*\xf6\xa9t\xc1e\xddg\x15\x85" +
+	"\x80\xcb\xd99\xa9\xfb\x15\xec\x9c\xd4y5;/u\xe9" +
+	"d\xa8~\x0f\x00\xaf,\xfe\xaea\xa8\x92\xcek\x19\xaa" +
+	"\xc4\xbf\x8a\xa1J\xaa}\x85\x9d\x97>^\xc7\xceK\xdb" +
+	"\xba\xd8y\xa9\xe2\xfa\xa2\xdcnvNjru\xf1w" +
+	"C\x11\xffj\xf1\xf7k\xec\x9c\x9c\xb7\xb18\xbe\x89\x9d" +
+	"S\\\x00\xdc\xcc\xceJ\xfc\x1av\x96\\P0\x9d)" +
+	";\xed\x18&\x00\x14<.v\x1an\x8a\xa3\x180\x04" +
+	"w\x8dt\xbc7\x99\xe4i\xc2\x13Y\xceM\x1c\xb02" +
+	"\x96\xd81>\x1e\xf1\xb8\xa8B\xfb\x1c;*\\G\x92" +
+	"\x07\x8dl_.\xe4Nr9\xde\xe7\xd84\x00\x1e\x17" +
+	"7[\x9e\xe5\xd8}\xb9YC\xaa?i\xc0I\x0dp" +
+	"\x88L\xfa\xebI\xa6\xe2S\xa5N\xa4R/@\xf5\xd8" +
+	"\xa0e\xfb\xc37\x03\x14\\N\x96$8\xf4\x08a\xd9" +
+	")\xaf\xe0\x19\x93<\xc1\x85\x80\xa8\xff\xc9\xc5\x0d\xb61" +
+	"\x96\x86\x1e\x7f\xf9ja\xbb<.\xc7y\"Z\x1a\x96" +
+	"\xa6(\xb3\xc6\xb2\x9c\xa3\x19X\xafH\xeb+F#\xc5" +
+	"\x997:is@1<\x91\xe0\xdc\x96Tb\xa2\xa8" +
+	"\xf0\xb2D\xb7s\xd5\xdd[\x0d\xf6&#E\xc7KT" +
+	"\xf1\xd1\x9dV\x86\xef\x18\x1f\xf7\xb8\xf0\x1d\xd1\xcf\xc7\x8d" +
+	"\x1c\xa6\xc5\x80a\xf3\x11+b\x8a\x89@e,\xf9-" +
+	".\x1dW \xc7\x10\x1dsiA\x1e\xb1\"\xe4\x10B" +
+	"\x87y\xd2\x09e2\xdc6\xb9)G\xec\x94G\xb1J" +
+	"\xa4\x9d\xa9~g\xca\xde\xea\xb87\xf1i_\x81\x81(" +
+	"\x19[\xb6}Wv\xd6\xa8\x15)\x8e\x92\xed\x09\xb5d" +
+	"\xb3\x18\x99\xb0\xd2\xbco\xc2\xb0S\x96\x9dJ\xf0\x1e\x9f" +
+	".W\x1f\xe2\xae\x87\x96'\xb8-h\xc0\x0f[\xd2\xb0" +
+	"\x93<\xdd\xef@\x8f\x9f\x9b\xc5\xf4\xd8\xee\x81\xea\xd8\xc5" +
+	"\x8f\x84\x03\xd1\x9c\x9b\xe42\xa8\xd3\x82\xbb\x8am\xa4\x03" +
+	"7\xcfJG9\x8c\xa5\xe1\xf8\xc0,\x1b\xfc\xec\x1dr" +
+	"\xad\xb8\xe3Z\"\x1f\xe0\xaa/\x86\x94\xe6\xc3|_\xce" +
+	"r\xb9G\xbb!\x8b\xa2`\xd0\xafHd\xb1\xb4\x9a\x1f" +
+	"\x8e!\x97{\x9e\xf2\x0d\xc3\xdb\xe9\xf4\x16\x19\xb3\xd6\xeb" +
+	"5\xf7\xe4<\x95\xdc\xefG\xb3\x92U\xf6\x9d\x04\x15Q" +
+	"6\x85\xa2\xee\xa89\x11,\x11\x92K\xec\x98\xe4\xaek" +
+	"\x99\xbcL\xa4\xa8\x8d\x18\xf9AC$',;5\xe8" +
+	"\xa8\xa6t\xcf\x90\xe3YB\xb1\x1c{\xab\x95\x16\xdc\xf5" +
+	"\x13\xd5,\xa5\x90a\x0e+<\xb9\xd7\xb1i\x8a1\xdd" +
+	"oy\xc2\x88\xd8\xc9\xf2L\xb4\x1c;\xe1\xe4\xdc$J" +
+	"L\xee\x14\x94\xab\x0e:f\x05V7\xc0\x0d\x97\xa4\x94" +
+	"\x16p9\x85\x8a@n\x12C\xe5^\xa1\xa4\"\xf4L" +
+	"\x07[p\xc4\xc8oK\xa1\xed\xb8|\xd0\x98.o\xbd" +
+	"\x11#\xdfk\xe2\xa4\xe59n\xbe\xbc\xadF\x8c\xfc\x8d" +
+	"\x06D\xf7\x1bn\xd5\xdc\x1b\x8d\xfd\x86\x1a\x80\xfe\x0e\x90" +
+	"K\x00\x14\x92in\xb8#F\x1eKN\x03(X\x99" +
+	"\xac\xe3\x8a\x11\x03\xf3E\x90\xd4\xe3\xd35@\x92\xc8\xc7" +
+	"\x12\x9c$J\xefE\xd2\xdc\x9c\x8d\x0e9n1\x09v" +
+	"\xe4D6\x07q1l\x08>\x9b\xd3k\x9an\x84{" +
+	"R^\xbfkMr\xe8\xd9a\x0f\xf0qq\xc1\xaby" +
+	"h\xc2\xf0\xf8N+c\xa9v\x8a\xee\xe6\xc5j\x1d@" +
+	"\x1d\x02\xc4\x8c\xf5\x00\xfan\x15\xf5\x09\x05\x115$\x8c" +
+	"\x13\xf6M\x15\xf5\xb4\x821\x055T\x00b\xd60\x80" +
+	">\xa1\xa2.\x14\x8c\xa9\x8a\x86*@l\x1f1\xd3*" +
+	"\xea\xd3\x0a\xc6\xb3\xb4\x086\x80\x82\x0d\x80\xf1\xa4\x93\xb3" +
+	"\x05\xd6\x83\x82\xf5\x80\x05c\x92\xbbF\x8a\x0f\x02z\xb8" +
+	"\x10\x14\\\x08\x18\xcf\x18\xd3\x83\xc1\xd7\x97\xafF\"f" +
+	"\xd7\xfay\x96/\x9b\xbeL\xf9B\x9b\x9c\x8e\xb0\x1dj" +
+	"NT\xf9\xe8n\x00\xddTQ\xcf*\x18+9)\xb3" +
+	"\xbdlzLQ|'\xe5:\x01\xf4\xac\x8a\xfaw\xc9" +
+	"I\xaa\xef\xa4\xefl\x01\xd0\x0f\xa9\xa8?\xa2`p[" +
+	"\xe2\x90\xeb\xa4h/R\x95R.5\x01q1 \x05" +
+	"\xd8?F\x01J\x0e\x8df\x0d1\x81\x17\x01\x0e\xa9\x88" +
+	"\x8b\xcb\xb5; \x81\x07\x84\x95\xa1\x09eB\xd0\xb9\xfa" +
+	"\x84\xc0|\xf5\xff0\xbfd\xf6\xf1\x92\xd9lih\x0b" +
+	"@bqH\xc5Dk\xa8l9k\x0am\x06Hh" +
+	"\x84w\x84\xca\xc6\xb3\xb6\xd0v\x80D+\xe1W\x84\x14" +
+	"D\xdf|\xb6*4\x0a\x90XI\xf0:\xa2\xd7\xa1\x86" +
+	"u\x00lmh?@b\x0d\xe1\x1b\x09\x0f)\x1a\x86" +
+	"\x00\xd8\x86\xd0K\x00\x89\x8d\x84\xf7\x13\x1eV5\x0c\x03" +
+	"\xb0^\xb9\xec\xb5\x84\xdfHx\xa4NC*~o\x90" +
+	"\xf2\xfb\x09\x1f\"\xbc^\xd5\xb0\x1e\x80\x0d\x86~\x08\x90" +
+	"\x18\"|7\xe1\x0b\xea4\\\x00\xc0n\x0d\xb9\x00\x89" +
+	"[\x087\x09_\x18\xd2p!\x003B\xdf\x03H\x98" +
+	"\x84g\x09_\x14\xd6p\x11\x00\xcb\x84~\x09\x90\x10\x84" +
+	"\x7f\x9b\xf0\x86\xf74l\x00`\xb7K}\xa6\x09?D" +
+	"xc\x9b\x86\x8d\x00\xec\x8e\xd0z\x80\xc4\xb7\x08\xbf\x8b" +
+	"\xf0\x8b~\xab\xe1E\x00\xecN\xa9\xe7!\xc2\xef#<" +
+	"Z\xafa\x94:\xf8\xd0k\x00\x89\x07\x08\x7f\x84\xf0\xc5" +
+	"\x0b4\\\x0c\xc0\x8eH\xff<L\xf8\x93\x84\xc7\x16j" +
+	"\x18\x03`OH\xbb\x9e$\xfcy\xc2\x97D5\\\x02" +
+	"\xc0\x9e\x0b\x8d\x01$\x8e\x12~\x9cp\xb6HC\x06\xc0" +
+	"^\x0c\xfd\x0c q\x9c\xf0\x13\x84k\x0d\x1aj\xf4d" +
+	"\x11\xba\x1b q\x82\xf0_\x11\xbe\xb4Q\xc3\xa5\x00\xec" +
+	"\xb4\xf4\xcf\x1b\x84\xff\x86\xf0\x8b[5\xbc\x18\x80\xbd%" +
+	"\xf9\xbf!\xfcC\xc2/y_\xc3K\x00\xd8\x19\xa9\xcf" +
+	"\x87\x84\x7fF\xf8\xb2z\x0d\x97\x01\xb0O\xa5\x1f>&" +
+	"\xfcs\xc2\x9b\xa2\x1a6\x01\xb0\xb32\xbe\x9f\x13^\x17" +
+	"V0\xd6\xfc\xaf\x1a6\x030\x0c\xbb\x00\xc3a\x15\x13" +
+	"\x0d\x04\xb7\xfc\x9b\x86-\x00l\x01\xc1\x89z\xc25\xc2" +
+	"[C\x1a\xb6\x02\xb0X\x98\xdc\xb3\x98\xf0V\xc2\xdb\x96" +
+	"h\xd8F\xd9\x19\x1e\x06H,#|%\xe1\xedL\xc3" +
+	"v\xea\xbb\xc2\xe4\x9e\x0e\xc2\xd7\x10\x1e\xd74\x8c\x03\xb0" +
+	"\xd5\x12\xbf\x82\xf0\xab\x09\xefh\xd3\xb0\x03\x80u\x85\xf7" +
+	"\x00$\xd6\x11~-\xe1\xcbwj\xb8\x1c\x80m\x92\xf8" +
+	"F\xc2\xfb\x09_\xb1K\xc3\x15\x94\x9eR\xcf\xeb\x09\x1f" +
+	" \xfc\xd2\x8b5\xbc\x14\x80m\x93z\xdeH\xf8N\xc2" +
+	"W^\xa2\xe1J\x00\xa6\x87\x0fRz\x12\xbe\x9b\xf0U" +
+	"\xcb4\\E\xe9\x19&w\xee&|\x82\xf0\xcb\xceh" +
+	"x\x19\x00\xe3aJ\xcf\x09\xc2\x05\xe1\x977ix9" +
+	"\x00\xdb\x17\xa6\xb4\xca\x12\xfe-\xc2\xafh\xd6\xf0\x0a\x00" +
+	"\x96\x97\xebN\x13~\x88\xf0\xd5-\x1a\xae\xa6\xf4\x94r" +
+	"\x0e\x11~\x1f\xe1\x9d\xff\xaea'\xa5\xa1\xd4\xff\xbb\x84" +
+	"\x1f&\xfc\xca\xb0\x86W\xd2\xbb\x8d\xc4\x1f \xfc(\xe1" +
+	"k\"\x1a\xae\x01`OK=\x8f\x12~\x9c\xf0\xb5W" +
+	"j\xb8\x96\xd2-\xfc\x13J7\xc2O\x10~U\xab\x86" +
+	"WQ\xba\x85?\x02H\xbcN\xf8\xbf\x84\x15<0e" +
+	"\xe4o22\xc1}\xd13e\xe4\x87\xf9x\xe9\xb3\xe0" +
+	":\x86I\xe3\x15'`\xc1+\x16*\xa0Z\"\xb87" +
+	"\xecb\xf1\x08=~\x0d3g\x00}\xbc\xdf\xea\xf1\x04" +
+	"\x95\x85%B\xcf\x84A5@ \x9d\xf8T\x02\x80Z" +
+	"\x03D\xd7\x94u\x8d\xad\x96\x05\x14\x0c\xd3\xaf- \xee" +
+	"\x17\x82\x95+\xf7\x9a\x93\x16\xfa\x85\x07\xa90gL)" +
+	"\x8d\x15\xe5&\xb1\xac\x98cs*5\x10\x14D\xc0x" +
+	"\xda\xb0\xb9\x87aP0\x0cX\x10V\x9a\x0fP\xbb\xa5" +
+	"r\xb3D\x09<\xa3X\"\x91K\xa5\xb8'\xb8Y," +
+	"]\x82\x95\xbd\xe2\x00\xf4\x98\xb3\xd5\xe5\x9e\xb02\x86\xe0" +
+	"h\x0e;\x869b\x99\xaa\x98\x08\x06)\x0e\xd4dA" +
+	"\x84O\x0b\x8c\x96\x9f\xfa\x001\x0aX0\xad\xa2W\xb7" +
+	"\xbaN\x86\xca\xa68\xb7\xa9X.\xcd\x9f,6l\x15" +
+	"\xc5T0\x96\xa1>\xc3\x9d\xe4\xd5\xfe\x9b2\xf2\x09\x9e" +
+	"\xe6I\x14\x96c\xfb\x0f\x0a\x18-?\xdc\x14W.\xd9" +
+	"\x8c\x96_\xf1\x8a\x0a\x87\xc4\xa7\x8c\xfc6\x13C\xa0`" +
+	"\xa8Z`\x9fc\x8f\xf7X&\xafH\x85\x82\xc9e\x1b" +
+	"\xb3\x17\xe2\xb2\x8a\x0d\xfc\x9a\xa5\xf2\xd5rl\x88'\x84" +
+	"\x91\xe6s\xf1\x1eY\xd7\x06Y\\He\xbd\xad\xd6t" +
+	"o\x0a\xca\xe1$L*\x08\x11\xc1\xcd\xa0\"Je\xbd" +
+	"a\xbe\x87'\xe7\xa0[\xad\xe9\x84\x80\xa8!r\x1eF" +
+	"\xcb\xaf\x8dE\xab)\x19\xc8\xd5\x10\xa5\xc2/\xc8\x8a\x00" +
+	"\x8e\x0f[\xa9\x89\xd98\x19\x0c\xd5\x16W\xc6e\xd8I" +
+	"\xa7\xa1jd\xd0\xc0lo\xca\xe5<\xc3U\xbb\xbc\xa9" +
+	"\xd2\xdc\x98\xa42\x1f\x8dl\x96S\xbe`9\x09\x93\xc5" +
+	"\xae\\ffi\x82\x04\x07\x8d,\xf4\x8cp\xa9\xd9\xac" +
+	"\x81\x9b-\x8f\x9a\x039\x84\xa22\x01d\xf5\x0cq\xcb" +
+	"4\xb9='\xcd!n\x09\xeeV\x14;\xc1\xabc\xb1" +
+	"\xd81\x92\xc2\x9a\xe4\x15\xfd\x95\xca\xdd\xf21R\xdc\x08" +
+	"\xe8\xef\x83a\x1e5<\xc7\xc6h\xf9q\xba\x94aE" +
+	"\xa2R\"\x12\x8f\xb6k\xb4\xf2\x1c\x09*\xabP\x8d\xca" +
+	"\xaa\xdc[\xf9m\xb7\xccf*\xb3\xeae\xbd\x18\xdb\x0c" +
+	"\x80\x18[\xb0\x05\x80N'a%\x0fd\xb9\x9b\xe4\xb6" +
+	"\x98Oy\xbba\x08\xbf\xb8\xbe\xa3}\xdd\xd7\xe3\xd8\x82" +
+	"O\xcb\x1a\xafA.\xde\xb6E.\xbe\xb4\x13\x00\x95X" +
+	"\xe3\x16\x80\x03\xe3.\xe7SF>\x9a\xb4D\xfe@\xce" +
+	"\xdek;S\xf6\x17J\x96\xf6\xf5\xf8\x9e!\xc9k\xa4" +
+	"\xe4\xdb;\xa5\xe4\xdc\xa8\x94\xbco?\x00\xaa\xb1\x0c\xfd" +
+	"\xd4\xc5,z[\x0a\xc5\xac1\x00\x0c\xc78}Eb" +
+	"\x9c\x1e\x09\xebc\x06\x81\x0bb\xb7\xad\x07\xc0\x85\xb1]" +
+	"\xeb\x01\xa2\xb6c\xf3Y\x07\xff\x9c\x03\xbf0\xc1\xd3\xe6" +
+	",\x80S\xbbn\x1bi,y\x9f\xa6\xa5\x0c\xcf\xcf\xa9" +
+	"\x88e\xf2\x821eXTa\xa3\x7ft\xc8s\xbd|" +
+	"\x1e\x01\x04\xfb\x03\"\xee$\x8f\xcbd\x8d;b\x82\xbb" +
+	"\xf3\x09L\xd7\xbc\xfa\x94\x88\xd95\xdfw\xd9\xaf\xcdw" +
+	"\x81\x0d\x17\x9a\xf0\x8d\xe2\x01D\xc7\x8fLT\xbf\xb1\xd9" +
+	"\xd0,#\xbav\xbd\x8c\xe8\xaa=2\xa2\xcb;eD" +
+	"\x9b\x0e\x02\xa8\xce\xde\xb8\xedl\xb5\xa6\x0bY\xc7q{" +
+	"\x93\xc9\x1cD]#\x99\x8f\xee\xc9e\xb2\x851n\xb8" +
+	"tj\x84-/Co\x0bP\xd2\xe0Kh\xdc5\x84" +
+	"\x17n^\xb6\xa1]\xd5\xb2u\x96\xdb\xdaX\xad\xbe\x16" +
+	"\x8b\x1d\x9b\xb5\xa2\xa2\xb7S\x17\xfb\x1d[\xa6\xb3\xdc\xeb" +
+	"FE>\xcb1Z\xfe\xa7\xa1\x7f8\xc4\xc7\xd3\x8e\x11" +
+	"\x9cX\x11O\x04\xe7Kt\xccq\xd2\xe5Cq\x1e\xc1" +
+	"Y7\xdfhv\x7f\x99\xbev\xc8\x10\x13C\x8ee\x0b" +
+	"\xffY~Y\xe0\xa3\x87\xa8\x83=\xac\xa2\xfex\x85\x8f" +
+	"\x1e\xa56\xff\x11\x15\xf5\x9fRgW\xe7;\xe9)\x02" +
+	"\x9fTQ\x7f\x9e\x9c\x14\xf2\x9d\xf4\xdc~\x00\xfd\xa8\x8a" +
+	"\xfaqj\xeaT\xd9\xd4\xc5^\xdc\x0c\xa0?\xaf\xa2\xfe" +
+	"s\xea\xe8\xeadG\x17{\x99\xfc\xfe\x82\x8a\xfa\xab\x0a" +
+	"\xddH\xc2\x129\x93\x03=\xe3\x83\x82\x8b\xe8Vq\xec" +
+	"\x14\x81\x80<\xc0h\xcb\x19\"\xe7V\xde\xa4\xc2\x7f\xa8" +
+	"\xe5\xd0\x93v\xe8\x88\x0a*&\xdb1y\xf9\xbe\xaf\xba" +
+	"\xfd\xe7\xb1\x9f\xbe\x94C\x833=\"\xb8K.m\x0d" +
+	"\\z\xac\xb3\xec\x93\x92G_\xdcR\xe1\x92\xd2k\xca" +
+	"\xcbc\x00\xfaq\x15\xf5\x13\x15\xaf)3\xa3\x00\xfa\xab" +
+	"*\xeao\x94\xdb\xe4\xd8)\xf2\xe8\x09\x15\xf5_\x95{" +
+	"\xe4\xd8ib\xbe\xa1\xa2\xfe15\xc8(\x1b\xe4\xd8\xef" +
+	"\x08\xfcPE\xfd3\x05\xa3vEm}\x80\xfb\xefi" +
+	"AR\xfa~Ld!RYv%\x1d{\x9c\xaa\x04" +
+	"\xa8\xa8q{\\y\xb4\x07wg\xd2\xb0M\xcb4\x04" +
+	"\xa8\xbc\xe2\xfa\x0d\xfe\xf5U\xbc~\xfdI}\x0e\xa8&" +
+	"\xafq\xaf\xce# \xf3}\xe9\xd98O\xfeW/\xc4" +
+	"\x1f)\x96\x8e\xb2\x14\x8d\xe4\xb3\\\x06\\\xc6p\xd0\xbf" +
+	"?o\x18\x96gb\xefvy&^\xb7]\x9e\x89\x9b" +
+	":\xe5-\xd7\xb5B\xder\xab]\x80\x03\xc9\x9c\xeb\xd2" +
+	"\xc5\x9eu\xb9i%\x05\x074\xa9\x8e\xf4\xac\xb1\xb4\xbc" +
+	"qx\xf1\xfd\x09\x00\xa2\xe3\x86\x95\x8eLd2\xd5\x85" +
+	"\xe9\x17\xd6\x1a\x94\x9b\xfd\xc5\xe7\xa5\xe0u\xa9j\xcbS" +
+	"2=\xe0?E\x05[\xfe\x08\xed\xee\x87U\xd4\x9f\xac" +
+	"H\xd0'(\x99\x1eWQ?Z\x91\xa0O\x1f\x04\xd0" +
+	"\x7f\xaa\xa2\xfe\x82\x82X\xe7\xe7\xe7\xb1\xe1bzS\xd2" +
+	"\x86\xd0\xcf\xcfSD|]E\xfdC\x05{\xfcb\xac" +
+	"|\"\xca\xb7\xf94\xd9\x1f`\xc2\x11Fz\xab\x95\x96" +
+	"iU\xaa\x85\x83\xa72nn\xb5\xd2\xdc\x83`$\xed" +
+	"$\x0d\x0a\x08`\x90\x84\x94\xa0\x17U\x0ca?\x17\x86" +
+	"\x95\xf6\xa0\x9c\xa5\xc1\x7fS\xab^\xc4\xbep\x9f\xf7\x19" +
+	"\xb6\x19\xa7\x8c\x97\x81o\x08<y\x03y\xf2z\x15\xf5" +
+	"\x81\x0aOn\xa3\x83\xae_E}\xa8\xe2Mp\x90\x8e" +
+	"\xd9\x01\x15\xf5[\x94`;\xcd\xd9\x13qoV\x07T" +
+	"\xea\xaa\xa0\xa2[\x9a\xcf\xc50+\xad/\x94+\x03E" +
+	"\x97\xf5\xf7\xf8.\xab2t{\xd9\xa6\x92\x9d\x83\xa3e" +
+	"\x93\x82\x8c\xd9E1\xdf\xa9\xa2\xfeM\xa5\"B\x15\xed" +
+	"\xfb\xfc\x82\xfc\xbf\x03\x00^\xfe\xdf\x1b"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -2557,6 +3524,7 @@ func RegisterSchema(reg *schemas.Registry) {
 			0x80ae746ee2596b11,
 			0x81c2f05a394cf4af,
 			0x859f26628fc24358,
+			0x916b986e1430edc4,
 			0x9ccdc8676701b412,
 			0xa1680744031fdb2d,
 			0xa30662f84033036c,
@@ -2564,21 +3532,25 @@ func RegisterSchema(reg *schemas.Registry) {
 			0xa5a3dfadcac04344,
 			0xa5cd762cd951a455,
 			0xabefa88b9563dbae,
+			0xae1ee0f5a968719c,
 			0xaedffd8f31e7b55d,
 			0xb057204d7deadf3f,
 			0xb86e6369214c01c8,
 			0xbd443b539493bc68,
+			0xbe935587c4efb5b0,
 			0xc2243c65e0340384,
 			0xc86a3d38d13eb3ef,
 			0xcb9fd56c7057593a,
 			0xcd96dafb67a082d0,
 			0xd6f78acca1bc3939,
 			0xda96579883444c35,
+			0xdf783c4015c7045f,
 			0xf35cc4560bbf6ec2,
 			0xf416ec09499d9d19,
 			0xf98d843bfd7004a3,
 			0xfa3e5ce74e25e82a,
 			0xfaa35dcac85073a2,
+			0xfb199b7aa69e4c27,
 			0xfc6241ed8877b611,
 			0xff889853e7b0987f,
 		},
@@ -0,0 +1,116 @@
+package cereal
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// these methods exist on every generated struct but are not fields
+var skippedMethods = map[string]bool{
+	"String":  true,
+	"IsValid": true,
+	"Message": true,
+	"Segment": true,
+	"ToPtr":   true,
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// StructFields returns the fields of a generated cereal struct keyed by their
+// schema name so they can be marshaled as json. Enums are converted to their
+// names and nested structs and lists are converted recursively.
+func StructFields(v any) map[string]any {
+	res := map[string]any{}
+	val := reflect.ValueOf(v)
+	typ := val.Type()
+	for i := range typ.NumMethod() {
+		method := typ.Method(i)
+		if skippedMethods[method.Name] || strings.HasPrefix(method.Name, "Has") || method.Type.NumIn() != 1 {
+			continue
+		}
+		numOut := method.Type.NumOut()
+		if numOut == 0 || numOut > 2 || (numOut == 2 && method.Type.Out(1) != errorType) {
+			continue
+		}
+		if !isScalar(method.Type.Out(0)) && !isStruct(method.Type.Out(0)) && !isList(method.Type.Out(0)) {
+			continue
+		}
+		result := val.Method(i).Call(nil)
+		if numOut == 2 && !result[1].IsNil() {
+			continue
+		}
+		name := []rune(method.Name)
+		name[0] = unicode.ToLower(name[0])
+		res[string(name)] = fieldValue(result[0])
+	}
+	return res
+}
+
+func fieldValue(val reflect.Value) any {
+	typ := val.Type()
+	switch {
+	case isList(typ):
+		length := int(val.MethodByName("Len").Call(nil)[0].Int())
+		at := val.MethodByName("At")
+		items := make([]any, length)
+		for i := range length {
+			item := at.Call([]reflect.Value{reflect.ValueOf(i)})
+			if len(item) == 2 && !item[1].IsNil() {
+				continue
+			}
+			items[i] = fieldValue(item[0])
+		}
+		return items
+	case isStruct(typ):
+		return StructFields(val.Interface())
+	}
+
+	field := val.Interface()
+	if stringer, ok := field.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	// json can't represent NaN or infinity
+	if typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64 {
+		f := val.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+	}
+	return field
+}
+
+func isScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isStruct reports whether typ is a generated struct type.
+func isStruct(typ reflect.Type) bool {
+	_, ok := typ.MethodByName("IsValid")
+	return typ.Kind() == reflect.Struct && ok && !isList(typ)
+}
+
+// isList reports whether typ is a capnp list with Len and At methods. Text
+// and data lists return an error from At as well.
+func isList(typ reflect.Type) bool {
+	length, ok := typ.MethodByName("Len")
+	if !ok || length.Type.NumIn() != 1 || length.Type.NumOut() != 1 || length.Type.Out(0).Kind() != reflect.Int {
+		return false
+	}
+	at, ok := typ.MethodByName("At")
+	if !ok || at.Type.NumIn() != 2 || at.Type.NumOut() == 0 || at.Type.NumOut() > 2 {
+		return false
+	}
+	if at.Type.NumOut() == 2 && at.Type.Out(1) != errorType {
+		return false
+	}
+	return isScalar(at.Type.Out(0)) || isStruct(at.Type.Out(0))
+}
@@ -15,12 +15,12 @@ type Way capnp.Struct
 const Way_TypeID = 0xa4b9c59286b69600
 
 func NewWay(s *capnp.Segment) (Way, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 72, PointerCount: 4})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 80, PointerCount: 4})
 	return Way(st), err
 }
 
 func NewRootWay(s *capnp.Segment) (Way, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 72, PointerCount: 4})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 80, PointerCount: 4})
 	return Way(st), err
 }
 
@@ -213,12 +213,44 @@ func (s Way) SetMaxSpeedBackward(v float64) {
 	capnp.Struct(s).SetUint64(64, math.Float64bits(v))
 }
 
+func (s Way) Highway() HighwayType {
+	return HighwayType(capnp.Struct(s).Uint16(42))
+}
+
+func (s Way) SetHighway(v HighwayType) {
+	capnp.Struct(s).SetUint16(42, uint16(v))
+}
+
+func (s Way) Id() int64 {
+	return int64(capnp.Struct(s).Uint64(72))
+}
+
+func (s Way) SetId(v int64) {
+	capnp.Struct(s).SetUint64(72, uint64(v))
+}
+
+func (s Way) LanesForward() uint8 {
+	return capnp.Struct(s).Uint8(44)
+}
+
+func (s Way) SetLanesForward(v uint8) {
+	capnp.Struct(s).SetUint8(44, v)
+}
+
+func (s Way) LanesBackward() uint8 {
+	return capnp.Struct(s).Uint8(45)
+}
+
+func (s Way) SetLanesBackward(v uint8) {
+	capnp.Struct(s).SetUint8(45, v)
+}
+
 // Way_List is a list of Way.
 type Way_List = capnp.StructList[Way]
 
 // NewWay creates a new list of Way.
 func NewWay_List(s *capnp.Segment, sz int32) (Way_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 72, PointerCount: 4}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 80, PointerCount: 4}, sz)
 	return capnp.StructList[Way](l), err
 }
 
@@ -230,18 +262,137 @@ func (f Way_Future) Struct() (Way, error) {
 	return Way(p.Struct()), err
 }
 
+type HighwayType uint16
+
+// HighwayType_TypeID is the unique identifier for the type HighwayType.
+const HighwayType_TypeID = 0x9e9dfd3d0cfad458
+
+// Values of HighwayType.
+const (
+	HighwayType_unknown       HighwayType = 0
+	HighwayType_motorway      HighwayType = 1
+	HighwayType_motorwayLink  HighwayType = 2
+	HighwayType_trunk         HighwayType = 3
+	HighwayType_trunkLink     HighwayType = 4
+	HighwayType_primary       HighwayType = 5
+	HighwayType_primaryLink   HighwayType = 6
+	HighwayType_secondary     HighwayType = 7
+	HighwayType_secondaryLink HighwayType = 8
+	HighwayType_tertiary      HighwayType = 9
+	HighwayType_tertiaryLink  HighwayType = 10
+	HighwayType_unclassified  HighwayType = 11
+	HighwayType_residential   HighwayType = 12
+	HighwayType_livingStreet  HighwayType = 13
+	HighwayType_service       HighwayType = 14
+	HighwayType_road          HighwayType = 15
+	HighwayType_other         HighwayType = 16
+)
+
+// String returns the enum's constant name.
+func (c HighwayType) String() string {
+	switch c {
+	case HighwayType_unknown:
+		return "unknown"
+	case HighwayType_motorway:
+		return "motorway"
+	case HighwayType_motorwayLink:
+		return "motorwayLink"
+	case HighwayType_trunk:
+		return "trunk"
+	case HighwayType_trunkLink:
+		return "trunkLink"
+	case HighwayType_primary:
+		return "primary"
+	case HighwayType_primaryLink:
+		return "primaryLink"
+	case HighwayType_secondary:
+		return "secondary"
+	case HighwayType_secondaryLink:
+		return "secondaryLink"
+	case HighwayType_tertiary:
+		return "tertiary"
+	case HighwayType_tertiaryLink:
+		return "tertiaryLink"
+	case HighwayType_unclassified:
+		return "unclassified"
+	case HighwayType_residential:
+		return "residential"
+	case HighwayType_livingStreet:
+		return "livingStreet"
+	case HighwayType_service:
+		return "service"
+	case HighwayType_road:
+		return "road"
+	case HighwayType_other:
+		return "other"
+
+	default:
+		return ""
+	}
+}
+
+// HighwayTypeFromString returns the enum value with a name,
+// or the zero value if there's no such value.
+func HighwayTypeFromString(c string) HighwayType {
+	switch c {
+	case "unknown":
+		return HighwayType_unknown
+	case "motorway":
+		return HighwayType_motorway
+	case "motorwayLink":
+		return HighwayType_motorwayLink
+	case "trunk":
+		return HighwayType_trunk
+	case "trunkLink":
+		return HighwayType_trunkLink
+	case "primary":
+		return HighwayType_primary
+	case "primaryLink":
+		return HighwayType_primaryLink
+	case "secondary":
+		return HighwayType_secondary
+	case "secondaryLink":
+		return HighwayType_secondaryLink
+	case "tertiary":
+		return HighwayType_tertiary
+	case "tertiaryLink":
+		return HighwayType_tertiaryLink
+	case "unclassified":
+		return HighwayType_unclassified
+	case "residential":
+		return HighwayType_residential
+	case "livingStreet":
+		return HighwayType_livingStreet
+	case "service":
+		return HighwayType_service
+	case "road":
+		return HighwayType_road
+	case "other":
+		return HighwayType_other
+
+	default:
+		return 0
+	}
+}
+
+type HighwayType_List = capnp.EnumList[HighwayType]
+
+func NewHighwayType_List(s *capnp.Segment, sz int32) (HighwayType_List, error) {
+	return capnp.NewEnumList[HighwayType](s, sz)
+}
+
 type Coordinates capnp.Struct
 
 // Coordinates_TypeID is the unique identifier for the type Coordinates.
 const Coordinates_TypeID = 0x922b57c60c6a46d1
 
 func NewCoordinates(s *capnp.Segment) (Coordinates, error) {
-	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	st, err := capnp.NewStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
 	return Coordinates(st), err
 }
 
 func NewRootCoordinates(s *capnp.Segment) (Coordinates, error) {
-	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0})
+	st, err := capnp.NewRootStruct(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0})
 	return Coordinates(st), err
 }
 
@@ -293,12 +444,20 @@ func (s Coordinates) SetLongitude(v float64) {
 	capnp.Struct(s).SetUint64(8, math.Float64bits(v))
 }
 
+func (s Coordinates) NodeId() int64 {
+	return int64(capnp.Struct(s).Uint64(16))
+}
+
+func (s Coordinates) SetNodeId(v int64) {
+	capnp.Struct(s).SetUint64(16, uint64(v))
+}
+
 // Coordinates_List is a list of Coordinates.
 type Coordinates_List = capnp.StructList[Coordinates]
 
 // NewCoordinates creates a new list of Coordinates.
 func NewCoordinates_List(s *capnp.Segment, sz int32) (Coordinates_List, error) {
-	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 16, PointerCount: 0}, sz)
+	l, err := capnp.NewCompositeList(s, capnp.ObjectSize{DataSize: 24, PointerCount: 0}, sz)
 	return capnp.StructList[Coordinates](l), err
 }
 
@@ -437,56 +596,81 @@ func (f Offline_Future) Struct() (Offline, error) {
 	return Offline(p.Struct()), err
 }
 
-const schema_da3a0d9284ca402f = "x\xda\xa4\xd4\xcdk\\U\x18\x06\xf0\xe79\xe7\xceG" +
-	"23\xc6\xe1\\\xd0\x85!\xe0\xaaFj:\xd6\x85\x0c" +
-	"BK\x85\"A\xb0\xc7\xbb\x98\xa5\x1c2wttr" +
-	"\xefpgLf\x04\xb1J\x10\x95\x0am\x88\xd2B\x0b" +
-	"-D\xa8\xa0P\xa1H\x02\x06\xa2DA\xcd\xc6\x9d\xb8" +
-	"\xf2\x1fp\xe3\xce\x85^y\xef0\x1f\x01\x17BW3" +
-	"\xe7\xf7\xbe\xf7\x9c\x97s\x1f\xee\x99\xefx\xde\xabUb" +
-	"\x05e\x1f\xcb\xe5\xd3_.\xbe^\xfe\xa1\xf1\xe46\xec" +
-	"\"U\xbar\xfe\xa7\xad\xedJ\xfd7x\x05\xe0\xec\"" +
-	"Wij,\x00\xe647\xc1\x7f>\xfd\xfa\xfd\xed\xef" +
-	"\xf7w\xed\"\xe7\xa6\xad\xb9\xac\xf7\x1a\xe7i\xeeH\xef" +
-	"\xd9[|D\x83\xe9\xef\x83\x0d\x17\xfc\xf9\xca\xcf\xb2s" +
-	"n\xa6=\xeb\xf9\xc3[\xa6\xf9;{\xf2/\xafA\x9c" +
-	"N\xd7\xc2$t\x9d\x95\xd8k\xb5:\xed(\\\x89G" +
-	"\xbfO\xad\xb9n\xd4\xad?\x1f\xc7I\xb3\x1d\xb9~\xd8" +
-	"\x03.\x91\xb6\xa8=\xc0#P}b\x15\xb0\xa74\xed" +
-	"3\x8aU\xd2\xa7`\xede\xc0\x9e\xd1\xb4\xcf)\xa6\x1d" +
-	"\xd7o\xf7\xdfl\x86\x00X\x82b\x09L;q\xf4\xaa" +
-	" \x18Nl<\x82\xfe\xcf\x11\x1an8:\xfa\xd9\xf1" +
-	"\xd1f\xc8e \xe8S3\xb8\xcc\xe9\xe9\xe6m>\x0e" +
-	"\x04\x03\xf1-*R\xf9T\x80y\x97\xab@pY\xf8" +
-	"\x8a\xb4k\xfa\xd4\x80\xf9\x90u \xd8\x12\xbf*\xee)" +
-	"\x9f\x1e`>\xce\xfc\x03\xf1\x1d\xf1\x9c\xf6\x99\x03\xcc\xb5" +
-	"\xcc\xaf\x88_\x17\xcf{>\xf3\x80\xf9$\xf3\xab\xe27" +
-	"\xc5\x0b\xca\xcf^\xde\x0d>\x0d\x04;\xe2\xf7\xc4\x8b\xa7" +
-	"|\x16\x01\xf3e\xe6w\xc5\xef\x8b\xcf\xe5}\xce\x01\xe6" +
-	"+&@pO\xfc\x1b\xf1y\xeds\x1e0\xfb\xd9\xfe" +
-	"\xf7\xc5\x0f\xa9X+\xbd@\x9f%\xc0\x1cd\x85=)" +
-	"\x1c\xc9\x03\xe5\x82\xcf2`\xbe\xe5{@p(~," +
-	"^)\xfa\xac\x00\xe6G~\x04\x04\xc7\xe2\xbfRq!" +
-	"r\xeb!\xcbP,\x83\x85$l\x8d\xff\xa7\xebn\x10" +
-	"t\xc3\xb09\xf3\xe6\xce\xad\xb7\xa3\x17]\xff\xc42\x8e" +
-	"\xa6K78Qu\x83\x99\xeaR\x147\xc3\x1e\x1f\x02" +
-	"/i\xf2\xe1i\xf8A\xc1\xa5\x8e\x8b\xc2\x1e\xf3P\xcc" +
-	"\x83\xa9kn\xb4{q2\xc4R6\xc3d\xcf\xd7\xdc" +
-	"[.i\x8eg<\x17Ga\xc3\x0dI(rfd" +
-	"^\x8c\x93M\x974\xa7\x91\x9bT.\xb8\xb57\xb2\x12" +
-	"\xfeg\xf4^j\xb5\x16d)\xe9{t\x12\xfc\x1bu" +
-	"\xc0\xeeh\xda\xdb3\xc1\xbf%x]\xd3\xee*V\xd5" +
-	"(x\xd5;\x8275\xed]I\x9d\xceRW\xfdL" +
-	"\xf0\xb6\xa6\xfdB\x91^\x96\xb8\xea\xe7\xcb\x80\xdd\xd5\xb4" +
-	"\x87\x127/\x8b[\xf5\xe0\x02`\xf74\xed\x91z\xa0" +
-	"\xdb_\xd8t\xc3\xe9\xe5\x8f?&\xa3\xab\x7f'\xde\x08" +
-	"\x93\x8e\xeb\x8e{\xff\x0d\x00\x00\xff\xff\xb7\xa9\xf0K"
+const schema_da3a0d9284ca402f = "x\xda\xa4\x95Oh\\\xd5\x17\xc7\xbf\xdf{g2\xf9" +
+	"3\xc9\xe4\xf2\xde\x0f\xca\x0f\xc2T\xe9\xa2\x8d\xad\xfd\xa7" +
+	"\x8b\x86JKlK\x1a\xa6\x98\x97\xd7\x92\x0a\x8a^g" +
+	"^\x9a\xd7\x99\xbc\x97\xbe\x99\xfc\x19A\x82\xa2B\x85." +
+	",*\x16\xac\xb6P\xc1\x82\xe2J\xb1\x8b\x82\x0b\xab\xa0" +
+	"+\xc1\x95+\x17.t!T)h\xa5\xf5\xc9y1" +
+	"3)\x88.\xdc\xcc=\xf7s\xce\xb9\xf7\xcc\xbd\xf7{" +
+	"\xde\xae3\xea`n\xf7\xe0\x17\x0a\xca\xdb\x9c\xefI\xbf" +
+	">r\xba\xf8\xf9\xcc\x03\xe7\xe1\x8dP\xa7;\x0f~\xf9" +
+	"\xe2\xf9\xc1\xb1o\x91+\x00{\x8fr\x92\xce\x93,\x00" +
+	"\xce\xe3\xfc\x10LO~\xf3{\xf1\x91\xbb\x17\xdf\x86\x19" +
+	"Q\xddXp\xefm\x89\x1cT\x92\xd4\xa7\xde!\xf8\xc7" +
+	"\x1b\x1f\xbf|\xfe\xc6\xb5+\xde\x08\xfb\xbb\x91\xf9l\xd9" +
+	"m\xba\x9f\xce>-\xe6\xc3\xfaW\x0d\xa6\xdf\xad,Y" +
+	"\xff\x97\xa7\xbe\x92\"\xf2\x1b\xc2e\xeb\xbd\xcf\x15F\xe9" +
+	"\x9c+\x88y\xb60C\xecH\xabA\x12\xd8\xc6\xce8" +
+	"7;\xdb\x08\xa3`g\xbc6>X\xb5\x0b\xd1\xc2\xd8" +
+	"\xa3q\x9c\xd4\xc2\xc8\xb6\x82&0EzE\x9d\x03r" +
+	"\x04\xcc\xe1I\xc0;\xa4\xe9M)\x1a\xd2\xa5\xc0c\xd3" +
+	"\x80W\xd1\xf4N*\x1a\xa5\\*\xc0\x9c\x18\x03\xbc)" +
+	"M\xef\x09\xc5\xb4a[ak\xb1\x16\x00\xe0\x00\x14\x07" +
+	"\xc0\xb4\x11G\xa7\x04\x82\xc1:;\x10\xc5\xb5\xe0h\x8d" +
+	"y(\xe6\xc1\x7f)s\"<5\xb7l\xdb\xc7\xdb\x0b" +
+	"\xc1Z\x99\x87\xb2\x8do\x8c\x03\xa4\xb9>\x09P\x99k" +
+	"\xa7\x01j\xf3\xd1\x1e\x809\xf3\xc14\xc0\xbc\xb9*!" +
+	"=\xe6\xf23\x00\x0b\xe6\xa2\xc0^s!\x01\xd8g^" +
+	"\x97\xbc~\xf3\xaa\xe4\x0d\x98s2\x14\xcdY\x89\x1c4" +
+	"/\xc9l\xc8</\xe9%\xd3\x1e\x058l\xce\xec\x01" +
+	"V\x17\xa3z\x14/G\xe9|\xdc\x8a\x93e\xdb\x06\xd0" +
+	"\xb5K\x950\xaa\x97[\xc9bTO\xb3\xdfJ\x18\x81" +
+	"\xf5\xd5\x85$\x9c\xb7I;\xfdk\xac\xa0\x10F\xf5\xb4" +
+	"\x19T\xe3\xa8f\x13\xb0\xdd\xb5\xcbmY#m\x05I" +
+	"+\xb4I\xb6|\xc7\xce\x96O\x17\xa3j\xc36\x9b!" +
+	"J\xb3aPK\x93\xa0\x19\xd6\x82\xa8\x85Bh\x1bi" +
+	"#\\\x0a\xa3S~\x0b\xa5$\x08Z\xab\xcd Y\x0a" +
+	"\xabA)\x89m\xad\x1c\xb7\xe6\x82\xa4s\xd4\xfao\x8f" +
+	"z\xc6\xb6\xd7\x8exb\xfd%8?r\x14\xf0\xbf\xa7" +
+	"\xa6\x7f\x93\xdd\xc7\xe0\xfc\xc4\xfb\x01\xff\x07\xe1\xb7\xa8\xc8" +
+	"\xb5\xe7\xe0\xfc\xccI\xc0\xbf)\xf8\x8e\x84k\xba\xd4\x80" +
+	"s\x9bc\x80\x7f\x8b\x9a\xd3J\xd1\xe4\x94\xcb\x1c\xe0\xdc" +
+	"\xcd\xf0o\x12\x9e\x13\x9e\xd7.\xf3\x80C%\xfc\x8e\xf0" +
+	"^\xe1=9\x97=\x80\x93\x17>\xad4\xfd\xa2\xe0\x82" +
+	"r3\xd1\xf5\xa9=\x80\x9f\x13\xbeYx\xefV\x97\xbd" +
+	"\x803\x92\xf1M\xc2\xb7\x08\xef\xebq\xd9\x078\xf7\xa9" +
+	"\x04\xf07\x0b\xdf.\xbc_\xbb\xec\x07\x9cm\xd9\xb6[" +
+	"\x84\xefR\x8a\xbb\x07&\xe8r\x00pvd\x8e\xad\xe2" +
+	"xH\x12\x8a\x05\x97E\xc0\xd9\xad^\x00\xfc]\xc2\xf7" +
+	"\x0b\x1f\xecu9\x088\xfb\xd4+\x80\xbf_\xf8\x84\xf0" +
+	"\xa1\xff\xb9\x1c\x02\x9c\xc3j\x1c\xf0\x0f\x0a\xaf\x08/\xf5" +
+	"\xb9,\x01\xceQ\xf5\x7f\xc0?$|J\xf8\xf0v\x97" +
+	"\xc3\x80sL\x9d\x06\xfc\x8a\xf0\x93\xc2\xcd\x0e\x97\x06p" +
+	"Nd\x7f\xe0\xb8\xf0\xa7\x95b)\xb2\xf3\x01\x8bP," +
+	"\x82\x85$\x98]\xb7\xd3y\xbb\xe2/\x04Am\x83\"" +
+	"\x0f\xcc\x87Q\xc5\xb6\xee\x99\xc6QwjW\xee\xf1\xda" +
+	"\x95\x0d\xde\xb2(\xb7\xc9!pJ\x93\xc3\xdd\xa6\x08\x0a" +
+	",7l\x144\xd9\x03\xc5\x1e0\xb5\xb5\xa5\xb0\x19'" +
+	"m\x94\xb3\x1a:k\xce\xd9gmR[\xaf\xf1@\x1c" +
+	"\x053\xb6MB\x91\x1bJ\xe6\x11\x91UR\xeb\xb6\x92" +
+	"\x8eg\xdcV\xeb\x99\xab\xe3[\x9d[\xeb\x12,u{" +
+	"/\xc8\x12\xa8\xc3n\xa3\xc9\xca;\x12'(Iv\xa7" +
+	"\xce\x0c\x8f\xdb*\xca\xf5{\xf8?\x8b\xe5\xb1\xd9\xd9\x92" +
+	"LE/\x9b:\x9d\xf3\x82\xf4\xc3\xd74\xbdK\x1b:" +
+	"\xe7E\x81ojzW6t\xce\xcb\x02\xdf\xd2\xf4\xde" +
+	"\x13\x9d\xe8L'\xe6]\x81\x974\xbd\xf7\x15\x99\xcbD" +
+	"b\xae\x8e\x02\xde\x15M\xefSQH.S\x88\xb9>" +
+	"\x0ex\x9fhz\x9f\xa9\xfft\xa3\xa5e\xdb\xee^\xe8" +
+	"\xfa\xd7h\xed:W\xe3\xa5 i\xd8\x85\xf5\xd8?\x07" +
+	"\x00?<\x88T"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
 		String: schema_da3a0d9284ca402f,
 		Nodes: []uint64{
 			0x922b57c60c6a46d1,
+			0x9e9dfd3d0cfad458,
 			0xa4b9c59286b69600,
 			0xcb5ff253617678e0,
 		},
@@ -0,0 +1,359 @@
+package cereal
+
+import (
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"pfeifer.dev/mapd/cereal/log"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+)
+
+const (
+	SOURCE_GPS          = "gpsLocation"
+	SOURCE_GPS_EXTERNAL = "gpsLocationExternal"
+	SOURCE_LLK          = "liveLocationKalman"
+	SOURCE_LIVE_POSE    = "livePose"
+)
+
+// PositionFix is a reading from one of the position sources. Sources that
+// only know orientation and velocity, like livePose, set HasPosition to false.
+type PositionFix struct {
+	Source              string
+	HasPosition         bool
+	Latitude            float64
+	Longitude           float64
+	HorizontalAccuracy  float32 // meters
+	BearingDeg          float32
+	BearingAccuracyDeg  float32
+	Speed               float32 // meters/second
+	SpeedAccuracy       float32 // meters/second
+	UnixTimestampMillis int64
+	Valid               bool
+	ReceivedAt          time.Time
+}
+
+func (f *PositionFix) Location() m.Location {
+	return m.Location{
+		Pos:                m.NewPosition(f.Latitude, f.Longitude),
+		BearingDeg:         float64(f.BearingDeg),
+		HorizontalAccuracy: f.HorizontalAccuracy,
+		Speed:              f.Speed,
+		BearingAccuracyDeg: f.BearingAccuracyDeg,
+	}
+}
+
+// Score rates how much a fix should be trusted at now. Higher is better.
+// Fixes lose score as they age and when the source flags them as invalid.
+func (f *PositionFix) Score(now time.Time) float64 {
+	age := now.Sub(f.ReceivedAt).Seconds()
+	score := -float64(f.HorizontalAccuracy) - age*ms.POSITION_AGE_PENALTY
+	if !f.Valid {
+		score -= ms.POSITION_INVALID_PENALTY
+	}
+	return score
+}
+
+type PositionSource interface {
+	Name() string
+	// Receive waits for the next fix. It fails once done is closed.
+	Receive(done <-chan struct{}) (PositionFix, bool)
+	Close()
+}
+
+// subscriberSource converts the messages of a service to position fixes.
+type subscriberSource[T any] struct {
+	name    string
+	sub     Subscriber[T]
+	convert func(T) PositionFix
+}
+
+func (s *subscriberSource[T]) Name() string {
+	return s.name
+}
+
+func (s *subscriberSource[T]) Receive(done <-chan struct{}) (PositionFix, bool) {
+	msg, success := s.sub.Receive(done)
+	if !success {
+		return PositionFix{}, false
+	}
+	return s.convert(msg), true
+}
+
+func (s *subscriberSource[T]) Close() {
+	s.sub.Close()
+}
+
+// GpsFix converts a gpsLocation or gpsLocationExternal message from the named
+// source.
+func GpsFix(source string, loc log.GpsLocationData) PositionFix {
+	return PositionFix{
+		Source:              source,
+		HasPosition:         loc.Latitude() != 0 || loc.Longitude() != 0,
+		Latitude:            loc.Latitude(),
+		Longitude:           loc.Longitude(),
+		HorizontalAccuracy:  loc.HorizontalAccuracy(),
+		BearingDeg:          loc.BearingDeg(),
+		BearingAccuracyDeg:  loc.BearingAccuracyDeg(),
+		Speed:               loc.Speed(),
+		SpeedAccuracy:       loc.SpeedAccuracy(),
+		UnixTimestampMillis: loc.UnixTimestampMillis(),
+		Valid:               loc.HasFix() || loc.Flags()&1 == 1,
+	}
+}
+
+func LiveLocationKalmanFix(llk log.LiveLocationKalman) PositionFix {
+	fix := PositionFix{
+		Source:              SOURCE_LLK,
+		UnixTimestampMillis: llk.UnixTimestampMillis(),
+		Valid:               llk.Status() == log.LiveLocationKalman_Status_valid && llk.GpsOK() && llk.InputsOK(),
+	}
+
+	geodetic, gErr := llk.PositionGeodetic()
+	ecef, eErr := llk.PositionECEF()
+	if gErr == nil && eErr == nil {
+		value, vErr := geodetic.Value()
+		std, sErr := ecef.Std()
+		if vErr == nil && sErr == nil && value.Len() >= 2 && std.Len() >= 3 {
+			fix.HasPosition = true
+			fix.Latitude = value.At(0)
+			fix.Longitude = value.At(1)
+			fix.HorizontalAccuracy = float32(horizontalStd(fix.Latitude, fix.Longitude, [3]float64{std.At(0), std.At(1), std.At(2)}))
+			fix.Valid = fix.Valid && geodetic.Valid()
+		}
+	}
+
+	velocity, err := llk.VelocityNED()
+	if err == nil {
+		value, vErr := velocity.Value()
+		std, sErr := velocity.Std()
+		if vErr == nil && sErr == nil && value.Len() >= 2 && std.Len() >= 2 {
+			fix.Speed = float32(math.Hypot(value.At(0), value.At(1)))
+			fix.SpeedAccuracy = float32(math.Hypot(std.At(0), std.At(1)))
+		}
+	}
+
+	orientation, err := llk.CalibratedOrientationNED()
+	if err == nil {
+		value, vErr := orientation.Value()
+		std, sErr := orientation.Std()
+		if vErr == nil && sErr == nil && value.Len() >= 3 && std.Len() >= 3 {
+			fix.BearingDeg = float32(math.Mod(value.At(2)*ms.TO_DEGREES+360, 360))
+			fix.BearingAccuracyDeg = float32(std.At(2) * ms.TO_DEGREES)
+		}
+	}
+	return fix
+}
+
+// LivePoseFix only has orientation and velocity. It is used to improve the
+// bearing and speed of the selected position source.
+func LivePoseFix(pose log.LivePose) PositionFix {
+	fix := PositionFix{
+		Source: SOURCE_LIVE_POSE,
+		Valid:  pose.InputsOK() && pose.SensorsOK(),
+	}
+	orientation, err := pose.OrientationNED()
+	if err == nil {
+		fix.BearingDeg = float32(math.Mod(float64(orientation.Z())*ms.TO_DEGREES+360, 360))
+		fix.BearingAccuracyDeg = float32(float64(orientation.ZStd()) * ms.TO_DEGREES)
+		fix.Valid = fix.Valid && orientation.Valid()
+	}
+	velocity, err := pose.VelocityDevice()
+	if err == nil {
+		fix.Speed = velocity.X()
+		fix.SpeedAccuracy = velocity.XStd()
+		fix.Valid = fix.Valid && velocity.Valid()
+	}
+	return fix
+}
+
+// horizontalStd rotates the ecef position std at a latitude and longitude in
+// degrees into the local north and east axes and combines them. The ecef axes
+// don't line up with the ground, away from the equator part of the z std is
+// horizontal and part of the x and y std is vertical.
+func horizontalStd(lat float64, lon float64, ecefStd [3]float64) float64 {
+	sinLat, cosLat := math.Sincos(lat * ms.TO_RADIANS)
+	sinLon, cosLon := math.Sincos(lon * ms.TO_RADIANS)
+	north := [3]float64{-sinLat * cosLon, -sinLat * sinLon, cosLat}
+	east := [3]float64{-sinLon, cosLon, 0}
+	variance := 0.0
+	for i, std := range ecefStd {
+		variance += (north[i]*north[i] + east[i]*east[i]) * std * std
+	}
+	return math.Sqrt(variance)
+}
+
+// PositionSources reads every known position source and picks the best one
+// based on fix age, accuracy and validity, unless a source is forced through
+// the position_source setting.
+type PositionSources struct {
+	Clock    utils.Clock
+	sources  []PositionSource
+	latest   map[string]PositionFix
+	selected string
+	invalid  string // last forced source that was rejected, so it is only logged once
+}
+
+func GetPositionSources(transport Transport, clock utils.Clock) (PositionSources, error) {
+	gps, err := NewSubscriber(transport, SOURCE_GPS, GpsLocationReader, true, false)
+	if err != nil {
+		return PositionSources{}, err
+	}
+	gpsExternal, err := NewSubscriber(transport, SOURCE_GPS_EXTERNAL, GpsLocationExternalReader, true, false)
+	if err != nil {
+		return PositionSources{}, err
+	}
+	llk, err := NewSubscriber(transport, SOURCE_LLK, LiveLocationKalmanReader, true, false)
+	if err != nil {
+		return PositionSources{}, err
+	}
+	livePose, err := NewSubscriber(transport, SOURCE_LIVE_POSE, LivePoseReader, true, false)
+	if err != nil {
+		return PositionSources{}, err
+	}
+	return PositionSources{
+		Clock: clock,
+		sources: []PositionSource{
+			&subscriberSource[log.GpsLocationData]{name: SOURCE_GPS, sub: gps, convert: func(loc log.GpsLocationData) PositionFix {
+				return GpsFix(SOURCE_GPS, loc)
+			}},
+			&subscriberSource[log.GpsLocationData]{name: SOURCE_GPS_EXTERNAL, sub: gpsExternal, convert: func(loc log.GpsLocationData) PositionFix {
+				return GpsFix(SOURCE_GPS_EXTERNAL, loc)
+			}},
+			&subscriberSource[log.LiveLocationKalman]{name: SOURCE_LLK, sub: llk, convert: LiveLocationKalmanFix},
+			&subscriberSource[log.LivePose]{name: SOURCE_LIVE_POSE, sub: livePose, convert: LivePoseFix},
+		},
+		latest: map[string]PositionFix{},
+	}, nil
+}
+
+// Listen receives the fixes of every source in goroutines and sends them on
+// the returned channel until done is closed. The fixes have to be passed to
+// Push to pick the position. wg is marked done as each goroutine stops.
+func (p *PositionSources) Listen(done <-chan struct{}, wg *sync.WaitGroup) <-chan PositionFix {
+	fixes := make(chan PositionFix, len(p.sources))
+	for _, source := range p.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				fix, success := source.Receive(done)
+				if !success {
+					return
+				}
+				select {
+				case fixes <- fix:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	return fixes
+}
+
+// Push handles new fixes, from Listen or from a replayed log, and returns a
+// fix when the selected source has a new one.
+func (p *PositionSources) Push(fixes ...PositionFix) (PositionFix, bool) {
+	if p.latest == nil {
+		p.latest = map[string]PositionFix{}
+	}
+	now := p.Clock.Now()
+	updated := map[string]bool{}
+	for _, fix := range fixes {
+		fix.ReceivedAt = now
+		p.latest[fix.Source] = fix
+		updated[fix.Source] = true
+	}
+	return p.pick(updated)
+}
+
+func (p *PositionSources) pick(updated map[string]bool) (PositionFix, bool) {
+	now := p.Clock.Now()
+	best := p.selectSource(now)
+	if best != p.selected {
+		slog.Info("Switching position source", "from", p.selected, "to", best)
+		p.selected = best
+	}
+	if best == "" || !updated[best] {
+		return PositionFix{}, false
+	}
+
+	fix := p.latest[best]
+	if !fix.HasPosition {
+		return PositionFix{}, false
+	}
+	if ms.Settings.PositionSource == ms.POSITION_SOURCE_AUTO {
+		p.applyOrientation(&fix, now)
+	}
+	return fix, true
+}
+
+// Selected returns the name of the source currently used for positions.
+func (p *PositionSources) Selected() string {
+	return p.selected
+}
+
+func (p *PositionSources) selectSource(now time.Time) string {
+	forced := ms.Settings.PositionSource
+	if forced != "" && forced != ms.POSITION_SOURCE_AUTO {
+		if slices.Contains(ms.PositionSources, forced) {
+			return forced
+		}
+		// settings files aren't checked when loaded, unknown sources and ones
+		// without a position fall back to auto
+		if forced != p.invalid {
+			slog.Warn("position source can't be forced, using auto", "source", forced)
+			p.invalid = forced
+		}
+	}
+
+	// sorted so sources with the same score are always picked the same way
+	names := slices.Sorted(maps.Keys(p.latest))
+	best := ""
+	bestScore := math.Inf(-1)
+	for _, name := range names {
+		fix := p.latest[name]
+		if !fix.HasPosition || now.Sub(fix.ReceivedAt) > ms.POSITION_SOURCE_MAX_AGE {
+			continue
+		}
+		score := fix.Score(now)
+		// avoid flapping between sources of similar quality
+		if name == p.selected {
+			score += ms.POSITION_HYSTERESIS
+		}
+		if score > bestScore {
+			bestScore = score
+			best = name
+		}
+	}
+	return best
+}
+
+// applyOrientation replaces the bearing and speed of a fix with livePose
+// values when livePose is fresh, valid and more accurate.
+func (p *PositionSources) applyOrientation(fix *PositionFix, now time.Time) {
+	pose, ok := p.latest[SOURCE_LIVE_POSE]
+	if !ok || !pose.Valid || now.Sub(pose.ReceivedAt) > ms.POSITION_SOURCE_MAX_AGE {
+		return
+	}
+	if fix.BearingAccuracyDeg <= 0 || pose.BearingAccuracyDeg < fix.BearingAccuracyDeg {
+		fix.BearingDeg = pose.BearingDeg
+		fix.BearingAccuracyDeg = pose.BearingAccuracyDeg
+	}
+	if fix.SpeedAccuracy <= 0 || pose.SpeedAccuracy < fix.SpeedAccuracy {
+		fix.Speed = pose.Speed
+		fix.SpeedAccuracy = pose.SpeedAccuracy
+	}
+}
+
+func (p *PositionSources) Close() {
+	for _, source := range p.sources {
+		source.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package cereal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"pfeifer.dev/mapd/cereal/custom"
+	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+)
+
+func TestForceLivePose(t *testing.T) {
+	ms.Settings.Default()
+	t.Cleanup(ms.Settings.Default)
+
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := custom.NewRootMapdIn(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input.SetType(custom.MapdInputType_setPositionSource)
+	err = input.SetStr(SOURCE_LIVE_POSE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms.Settings.Handle(input)
+	if ms.Settings.PositionSource != ms.POSITION_SOURCE_AUTO {
+		t.Fatalf("position source %s was set from mapdIn", ms.Settings.PositionSource)
+	}
+
+	// a settings file can still hold it, the sources fall back to auto
+	ms.Settings.PositionSource = SOURCE_LIVE_POSE
+	sources := PositionSources{Clock: utils.NewManualClock(time.Unix(1700000000, 0))}
+	gps := PositionFix{Source: SOURCE_GPS, HasPosition: true, Latitude: 40, Longitude: -80, HorizontalAccuracy: 5, Valid: true}
+	pose := PositionFix{Source: SOURCE_LIVE_POSE, BearingDeg: 90, BearingAccuracyDeg: 1, Valid: true}
+	fix, success := sources.Push(gps, pose)
+	if !success {
+		t.Fatal("no fix with livePose forced")
+	}
+	if fix.Source != SOURCE_GPS || sources.Selected() != SOURCE_GPS {
+		t.Errorf("got a fix from %s, selected %s, want %s", fix.Source, sources.Selected(), SOURCE_GPS)
+	}
+}
+
+func TestHorizontalStd(t *testing.T) {
+	std := [3]float64{3, 4, 12}
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     float64
+	}{
+		// ecef x points up at 0, 0 so y and z are horizontal
+		{"equator", 0, 0, math.Hypot(4, 12)},
+		{"equator east", 0, 90, math.Hypot(3, 12)},
+		// z points up at the pole so x and y are horizontal
+		{"pole", 90, 0, math.Hypot(3, 4)},
+	}
+	for _, tt := range tests {
+		got := horizontalStd(tt.lat, tt.lon, std)
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: got %f, want %f", tt.name, got, tt.want)
+		}
+	}
+}
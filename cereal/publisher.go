@@ -1,16 +1,17 @@
 package cereal
 
 import (
+	"io"
+
 	"capnproto.org/go/capnp/v3"
-	"github.com/pfeiferj/gomsgq"
+	"github.com/pkg/errors"
 	"pfeifer.dev/mapd/cereal/log"
-	"pfeifer.dev/mapd/settings"
 )
 
 type MessageCreator[T any] func(log.Event) (T, error)
 
 type Publisher[T any] struct {
-	Pub     gomsgq.MsgqPublisher
+	Sock    PubSocket
 	creator MessageCreator[T]
 }
 
@@ -19,8 +20,11 @@ func (p *Publisher[T]) Send(msg *capnp.Message) error {
 	if err != nil {
 		return err
 	}
-	p.Pub.Send(b)
-	return nil
+	return p.Sock.Send(b)
+}
+
+func (p *Publisher[T]) Close() error {
+	return p.Sock.Close()
 }
 
 func (p *Publisher[T]) NewMessage(valid bool) (msg *capnp.Message, obj T) {
@@ -47,16 +51,33 @@ func (p *Publisher[T]) NewMessage(valid bool) (msg *capnp.Message, obj T) {
 	return msg, obj
 }
 
-func NewPublisher[T any](name string, creator MessageCreator[T]) (publisher Publisher[T]) {
-	msgq := gomsgq.Msgq{}
-	err := msgq.Init(name, settings.GetSegmentSize(name))
+func NewPublisher[T any](transport Transport, name string, creator MessageCreator[T]) (publisher Publisher[T], err error) {
+	sock, err := transport.Publish(name)
 	if err != nil {
-		panic(err)
+		return publisher, errors.Wrapf(err, "could not publish %s", name)
 	}
-	pub := gomsgq.MsgqPublisher{}
-	pub.Init(msgq)
+	publisher.Sock = sock
+	publisher.creator = creator
+	return publisher, nil
+}
 
-	publisher.Pub = pub
+// NewWriterPublisher creates a publisher that writes each marshaled event to w
+// instead of a transport. Every event is passed to w in a single Write call.
+func NewWriterPublisher[T any](w io.Writer, creator MessageCreator[T]) (publisher Publisher[T]) {
+	publisher.Sock = writerSocket{w: w}
 	publisher.creator = creator
 	return publisher
 }
+
+type writerSocket struct {
+	w io.Writer
+}
+
+func (s writerSocket) Send(data []byte) error {
+	_, err := s.w.Write(data)
+	return err
+}
+
+func (s writerSocket) Close() error {
+	return nil
+}
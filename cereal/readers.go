@@ -33,3 +33,15 @@ func GpsLocationReader(evt log.Event) (log.GpsLocationData, error) {
 func GpsLocationExternalReader(evt log.Event) (log.GpsLocationData, error) {
 	return evt.GpsLocationExternal()
 }
+
+func LivePoseReader(evt log.Event) (log.LivePose, error) {
+	return evt.LivePose()
+}
+
+func LiveLocationKalmanReader(evt log.Event) (log.LiveLocationKalman, error) {
+	return evt.LiveLocationKalmanDEPRECATED()
+}
+
+func LiveParametersReader(evt log.Event) (log.LiveParametersData, error) {
+	return evt.LiveParameters()
+}
@@ -2,22 +2,78 @@ package cereal
 
 import (
 	"math"
+	"sync"
 
 	"capnproto.org/go/capnp/v3"
-	"github.com/pfeiferj/gomsgq"
+	"github.com/pkg/errors"
 	"pfeifer.dev/mapd/cereal/log"
-	"pfeifer.dev/mapd/settings"
 )
 
 type Reader[T any] func(log.Event) (T, error)
 
 type Subscriber[T any] struct {
-	Sub    gomsgq.MsgqSubscriber
-	reader Reader[T]
+	Sock     SubSocket
+	reader   Reader[T]
+	conflate bool
 }
 
 func (s *Subscriber[T]) Read() (obj T, success bool) {
-	data := s.Sub.Read()
+	return s.decode(s.Sock.Read())
+}
+
+// Receive waits for the next event. It fails once done is closed.
+func (s *Subscriber[T]) Receive(done <-chan struct{}) (obj T, success bool) {
+	for {
+		data := s.Sock.Receive(done)
+		if data == nil {
+			return obj, false
+		}
+		obj, success = s.decode(data)
+		if success {
+			return obj, true
+		}
+	}
+}
+
+// Listen receives events in a goroutine and sends them on the returned
+// channel until done is closed. A conflated subscriber replaces an event that
+// hasn't been taken off the channel yet, otherwise every event is delivered.
+// wg is marked done when the goroutine stops.
+func (s *Subscriber[T]) Listen(done <-chan struct{}, wg *sync.WaitGroup) <-chan T {
+	events := make(chan T, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			event, success := s.Receive(done)
+			if !success {
+				return
+			}
+			if s.conflate {
+				replace(events, event)
+				continue
+			}
+			select {
+			case events <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// replace sends an event on a channel with a buffer of one, dropping the event
+// in the buffer if there is one. It must be the only sender on the channel.
+func replace[T any](events chan T, event T) {
+	select {
+	case <-events:
+	default:
+	}
+	events <- event
+}
+
+func (s *Subscriber[T]) decode(data []byte) (obj T, success bool) {
 	if len(data) == 0 {
 		return obj, false
 	}
@@ -41,18 +97,17 @@ func (s *Subscriber[T]) Read() (obj T, success bool) {
 	return obj, true
 }
 
-func NewSubscriber[T any](name string, reader Reader[T], conflate bool, shadow bool) (subscriber Subscriber[T]) {
-	msgq := gomsgq.Msgq{}
-	err := msgq.Init(name, settings.GetSegmentSize(name))
+func (s *Subscriber[T]) Close() error {
+	return s.Sock.Close()
+}
+
+func NewSubscriber[T any](transport Transport, name string, reader Reader[T], conflate bool, shadow bool) (subscriber Subscriber[T], err error) {
+	sock, err := transport.Subscribe(name, conflate, shadow)
 	if err != nil {
-		panic(err)
+		return subscriber, errors.Wrapf(err, "could not subscribe to %s", name)
 	}
-	sub := gomsgq.MsgqSubscriber{}
-	sub.Conflate = conflate
-	sub.Shadow = shadow
-	sub.Init(msgq)
-
-	subscriber.Sub = sub
+	subscriber.Sock = sock
 	subscriber.reader = reader
-	return subscriber
+	subscriber.conflate = conflate
+	return subscriber, nil
 }
@@ -11,6 +11,20 @@ static unsigned long long get_nsecs(void)
 */
 import "C"
 
-func GetTime() uint64 {
+var timeSource = func() uint64 {
 	return uint64(C.get_nsecs())
 }
+
+// GetTime returns the monotonic time in nanoseconds used for logMonoTime.
+func GetTime() uint64 {
+	return timeSource()
+}
+
+// SetTimeSource replaces the monotonic clock, like when replaying a log.
+// Passing nil restores the system clock.
+func SetTimeSource(f func() uint64) {
+	if f == nil {
+		f = func() uint64 { return uint64(C.get_nsecs()) }
+	}
+	timeSource = f
+}
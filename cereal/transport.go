@@ -0,0 +1,98 @@
+package cereal
+
+import (
+	"fmt"
+
+	ms "pfeifer.dev/mapd/settings"
+)
+
+const (
+	TRANSPORT_MSGQ = "msgq"
+	TRANSPORT_TCP  = "tcp"
+)
+
+// Transport moves marshaled events between processes. Publishers and
+// subscribers are created from a transport so mapd can run on msgq on the
+// device, over tcp on a laptop, or in process in tests and replays.
+type Transport interface {
+	Publish(service string) (PubSocket, error)
+	// Subscribe opens a socket for reading service. A conflated socket only
+	// keeps the newest event, a shadow socket reads without taking one of the
+	// publisher's reader slots where the transport has them.
+	Subscribe(service string, conflate bool, shadow bool) (SubSocket, error)
+}
+
+type PubSocket interface {
+	Send(data []byte) error
+	Close() error
+}
+
+// SubSocket returns the next event, or nil when there is no new event.
+type SubSocket interface {
+	Read() []byte
+	// Receive waits for the next event. It returns nil once done is closed.
+	Receive(done <-chan struct{}) []byte
+	Close() error
+}
+
+// TransportConfig selects the transport mapd and the cli use.
+type TransportConfig struct {
+	Type string
+	// Host is the address the tcp transport connects to for subscriptions
+	Host string
+	// Bind is the address the tcp transport publishes on
+	Bind string
+}
+
+func (c TransportConfig) Transport() (Transport, error) {
+	switch c.Type {
+	case "", TRANSPORT_MSGQ:
+		return MsgqTransport{}, nil
+	case TRANSPORT_TCP:
+		return NewTCPTransport(c.Host, c.Bind), nil
+	}
+	return nil, fmt.Errorf("unknown transport: %s", c.Type)
+}
+
+// queue buffers events for a subscriber. When it is full the oldest event is
+// dropped, like msgq does for readers that fall behind.
+type queue chan []byte
+
+func newQueue(conflate bool) queue {
+	if conflate {
+		return make(queue, 1)
+	}
+	return make(queue, ms.TRANSPORT_QUEUE_SIZE)
+}
+
+func (q queue) push(data []byte) {
+	for {
+		select {
+		case q <- data:
+			return
+		default:
+		}
+		select {
+		case <-q:
+		default:
+		}
+	}
+}
+
+func (q queue) pop() []byte {
+	select {
+	case data := <-q:
+		return data
+	default:
+		return nil
+	}
+}
+
+func (q queue) wait(done <-chan struct{}) []byte {
+	select {
+	case data := <-q:
+		return data
+	case <-done:
+		return nil
+	}
+}
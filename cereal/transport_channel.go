@@ -0,0 +1,73 @@
+package cereal
+
+import (
+	"slices"
+	"sync"
+)
+
+// ChannelTransport passes events between publishers and subscribers in the
+// same process, like in tests or when replaying logs.
+type ChannelTransport struct {
+	mu          sync.Mutex
+	subscribers map[string][]*channelSubSocket
+}
+
+func NewChannelTransport() *ChannelTransport {
+	return &ChannelTransport{subscribers: map[string][]*channelSubSocket{}}
+}
+
+func (t *ChannelTransport) Publish(service string) (PubSocket, error) {
+	return &channelPubSocket{transport: t, service: service}, nil
+}
+
+func (t *ChannelTransport) Subscribe(service string, conflate bool, shadow bool) (SubSocket, error) {
+	sub := &channelSubSocket{transport: t, service: service, queue: newQueue(conflate)}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers[service] = append(t.subscribers[service], sub)
+	return sub, nil
+}
+
+type channelPubSocket struct {
+	transport *ChannelTransport
+	service   string
+}
+
+func (s *channelPubSocket) Send(data []byte) error {
+	t := s.transport
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, sub := range t.subscribers[s.service] {
+		// each subscriber gets its own copy as the publisher may reuse data
+		sub.queue.push(slices.Clone(data))
+	}
+	return nil
+}
+
+func (s *channelPubSocket) Close() error {
+	return nil
+}
+
+type channelSubSocket struct {
+	transport *ChannelTransport
+	service   string
+	queue     queue
+}
+
+func (s *channelSubSocket) Read() []byte {
+	return s.queue.pop()
+}
+
+func (s *channelSubSocket) Receive(done <-chan struct{}) []byte {
+	return s.queue.wait(done)
+}
+
+func (s *channelSubSocket) Close() error {
+	t := s.transport
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.subscribers[s.service] = slices.DeleteFunc(t.subscribers[s.service], func(sub *channelSubSocket) bool {
+		return sub == s
+	})
+	return nil
+}
@@ -0,0 +1,129 @@
+package cereal
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pfeiferj/gomsgq"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// MsgqTransport uses the openpilot shared memory queues.
+type MsgqTransport struct{}
+
+func openMsgq(service string) (gomsgq.Msgq, error) {
+	msgq := gomsgq.Msgq{}
+	err := msgq.Init(service, ms.GetSegmentSize(service))
+	return msgq, err
+}
+
+func (MsgqTransport) Publish(service string) (PubSocket, error) {
+	msgq, err := openMsgq(service)
+	if err != nil {
+		return nil, err
+	}
+	pub := gomsgq.MsgqPublisher{}
+	pub.Init(msgq)
+	return &msgqPubSocket{pub: pub}, nil
+}
+
+func (MsgqTransport) Subscribe(service string, conflate bool, shadow bool) (SubSocket, error) {
+	msgq, err := openMsgq(service)
+	if err != nil {
+		return nil, err
+	}
+	sub := gomsgq.MsgqSubscriber{}
+	sub.Conflate = conflate
+	sub.Shadow = shadow
+	sub.Init(msgq)
+	wait := ms.MSGQ_WAIT_TIMEOUT
+	if shadow {
+		wait = ms.MSGQ_SHADOW_POLL_INTERVAL
+	}
+	return &msgqSubSocket{sub: sub, wait: wait}, nil
+}
+
+type msgqPubSocket struct {
+	pub gomsgq.MsgqPublisher
+}
+
+func (s *msgqPubSocket) Send(data []byte) error {
+	s.pub.Send(data)
+	return nil
+}
+
+func (s *msgqPubSocket) Close() error {
+	return closeMsgq(&s.pub.Msgq)
+}
+
+type msgqSubSocket struct {
+	sub  gomsgq.MsgqSubscriber
+	wait time.Duration // how long to wait for a signal before checking the queue
+}
+
+func (s *msgqSubSocket) Read() []byte {
+	return s.sub.Read()
+}
+
+// Receive waits for a publisher to signal that there is a new event. msgq
+// publishers send SIGUSR2 to every reader after writing, a reader only checks
+// its queue without a signal every MSGQ_WAIT_TIMEOUT in case one was missed.
+// Shadow readers are never signaled and check every MSGQ_SHADOW_POLL_INTERVAL.
+func (s *msgqSubSocket) Receive(done <-chan struct{}) []byte {
+	timer := time.NewTimer(s.wait)
+	defer timer.Stop()
+	for {
+		// take the wakeup before reading so a signal sent in between isn't lost
+		wakeup := msgqWakeup()
+		data := s.sub.Read()
+		if data != nil {
+			return data
+		}
+		select {
+		case <-done:
+			return nil
+		case <-wakeup:
+		case <-timer.C:
+		}
+		timer.Reset(s.wait)
+	}
+}
+
+var (
+	msgqSignalOnce sync.Once
+	msgqSignalLock sync.Mutex
+	msgqSignalWait = make(chan struct{})
+)
+
+// msgqWakeup returns a channel that is closed on the next msgq signal. The
+// signal is sent to the process, so one goroutine wakes every waiting reader.
+func msgqWakeup() <-chan struct{} {
+	msgqSignalOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGUSR2)
+		go func() {
+			for range signals {
+				msgqSignalLock.Lock()
+				close(msgqSignalWait)
+				msgqSignalWait = make(chan struct{})
+				msgqSignalLock.Unlock()
+			}
+		}()
+	})
+	msgqSignalLock.Lock()
+	defer msgqSignalLock.Unlock()
+	return msgqSignalWait
+}
+
+func (s *msgqSubSocket) Close() error {
+	return closeMsgq(&s.sub.Msgq)
+}
+
+func closeMsgq(msgq *gomsgq.Msgq) error {
+	unmapErr, closeErr := msgq.Close()
+	return errors.Join(unmapErr, closeErr)
+}
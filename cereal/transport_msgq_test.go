@@ -0,0 +1,57 @@
+package cereal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pfeiferj/gomsgq"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// TestMsgqShadowReceive checks that a shadow subscriber, which publishers never
+// signal, still receives an event within one poll interval.
+func TestMsgqShadowReceive(t *testing.T) {
+	service := fmt.Sprintf("mapdTestShadow%d", os.Getpid())
+	t.Cleanup(func() {
+		for _, dir := range []string{gomsgq.PATH_PREFIX, gomsgq.ALT_PATH_PREFIX} {
+			paths, _ := filepath.Glob(filepath.Join(dir, "*"+service))
+			for _, path := range paths {
+				os.Remove(path)
+			}
+		}
+	})
+	transport := MsgqTransport{}
+	pub, err := transport.Publish(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pub.Close()
+	sub, err := transport.Subscribe(service, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	sent := make(chan time.Time, 1)
+	go func() {
+		time.Sleep(ms.MSGQ_SHADOW_POLL_INTERVAL * 3)
+		sent <- time.Now()
+		_ = pub.Send([]byte("carState"))
+	}()
+
+	done := make(chan struct{})
+	timeout := time.AfterFunc(time.Second, func() { close(done) })
+	defer timeout.Stop()
+	data := sub.Receive(done)
+	received := time.Now()
+	if string(data) != "carState" {
+		t.Fatalf("received %q, want carState", data)
+	}
+	latency := received.Sub(<-sent)
+	if latency > ms.MSGQ_WAIT_TIMEOUT/2 {
+		t.Errorf("received after %s, want within the %s shadow poll interval", latency, ms.MSGQ_SHADOW_POLL_INTERVAL)
+	}
+}
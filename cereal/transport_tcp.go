@@ -0,0 +1,217 @@
+package cereal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// TCPTransport sends events over tcp between mapd processes, like mapd and its
+// cli. Each service is published on its own port derived from the service name
+// on Bind and subscribers connect to that port on Host. Events are sent as a 4
+// byte big endian length followed by the marshaled event, which is not zmq so
+// it can't talk to openpilot running with ZMQ=1.
+type TCPTransport struct {
+	Host string
+	Bind string
+}
+
+func NewTCPTransport(host string, bind string) *TCPTransport {
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if bind == "" {
+		bind = "127.0.0.1"
+	}
+	return &TCPTransport{Host: host, Bind: bind}
+}
+
+// ServicePort returns the port a service is published on. It is picked the
+// same way openpilot picks its zmq ports.
+func ServicePort(service string) int {
+	h := fnv.New64a()
+	h.Write([]byte(service))
+	return ms.TCP_START_PORT + int(h.Sum64()%uint64(ms.TCP_MAX_PORT-ms.TCP_START_PORT))
+}
+
+func (t *TCPTransport) Publish(service string) (PubSocket, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(t.Bind, fmt.Sprint(ServicePort(service))))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not publish %s", service)
+	}
+	s := &tcpPubSocket{listener: listener, conns: map[net.Conn]queue{}}
+	go s.accept()
+	return s, nil
+}
+
+func (t *TCPTransport) Subscribe(service string, conflate bool, shadow bool) (SubSocket, error) {
+	s := &tcpSubSocket{
+		addr:   net.JoinHostPort(t.Host, fmt.Sprint(ServicePort(service))),
+		queue:  newQueue(conflate),
+		closed: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+type tcpPubSocket struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]queue
+}
+
+func (s *tcpPubSocket) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		q := newQueue(false)
+		s.mu.Lock()
+		s.conns[conn] = q
+		s.mu.Unlock()
+		go s.write(conn, q)
+	}
+}
+
+// write sends queued events to a subscriber until the connection fails.
+func (s *tcpPubSocket) write(conn net.Conn, q queue) {
+	defer s.drop(conn)
+	header := make([]byte, 4)
+	for data := range q {
+		binary.BigEndian.PutUint32(header, uint32(len(data)))
+		_, err := conn.Write(append(header, data...))
+		if err != nil {
+			slog.Debug("tcp subscriber disconnected", "addr", conn.RemoteAddr(), "error", err)
+			return
+		}
+	}
+}
+
+func (s *tcpPubSocket) drop(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q, ok := s.conns[conn]; ok {
+		delete(s.conns, conn)
+		close(q)
+	}
+	conn.Close()
+}
+
+func (s *tcpPubSocket) Send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range s.conns {
+		q.push(slices.Clone(data))
+	}
+	return nil
+}
+
+func (s *tcpPubSocket) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	conns := make([]net.Conn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+	for _, conn := range conns {
+		s.drop(conn)
+	}
+	return err
+}
+
+type tcpSubSocket struct {
+	addr   string
+	queue  queue
+	mu     sync.Mutex
+	conn   net.Conn
+	closed chan struct{}
+}
+
+// run connects to the publisher and reads events, reconnecting until the
+// socket is closed.
+func (s *tcpSubSocket) run() {
+	for {
+		conn, err := net.DialTimeout("tcp", s.addr, ms.TCP_RECONNECT_INTERVAL)
+		if err == nil {
+			s.mu.Lock()
+			if s.isClosed() {
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conn = conn
+			s.mu.Unlock()
+			err = s.read(conn)
+			conn.Close()
+			slog.Debug("tcp publisher disconnected", "addr", s.addr, "error", err)
+		}
+		select {
+		case <-s.closed:
+			return
+		case <-time.After(ms.TCP_RECONNECT_INTERVAL):
+		}
+	}
+}
+
+func (s *tcpSubSocket) read(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	header := make([]byte, 4)
+	for {
+		_, err := io.ReadFull(r, header)
+		if err != nil {
+			return err
+		}
+		size := binary.BigEndian.Uint32(header)
+		if size > ms.TCP_MAX_MESSAGE_SIZE {
+			return fmt.Errorf("message too large: %d bytes", size)
+		}
+		data := make([]byte, size)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return err
+		}
+		s.queue.push(data)
+	}
+}
+
+func (s *tcpSubSocket) Read() []byte {
+	return s.queue.pop()
+}
+
+func (s *tcpSubSocket) Receive(done <-chan struct{}) []byte {
+	return s.queue.wait(done)
+}
+
+func (s *tcpSubSocket) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isClosed() {
+		return nil
+	}
+	close(s.closed)
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func (s *tcpSubSocket) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
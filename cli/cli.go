@@ -7,22 +7,70 @@ import (
 	"os"
 
 	"github.com/urfave/cli/v3"
+	"pfeifer.dev/mapd/cereal"
 	"pfeifer.dev/mapd/maps"
 	m "pfeifer.dev/mapd/math"
+	"pfeifer.dev/mapd/overrides"
 	"pfeifer.dev/mapd/params"
 )
 
-func Handle() {
+// ReplayOptions configures a replay of recorded openpilot logs.
+type ReplayOptions struct {
+	Logs             []string
+	OutputFile       string
+	Format           string
+	SettingsFile     string
+	TilesDirectory   string
+	WayOverridesFile string
+}
+
+const (
+	REPLAY_FORMAT_JSON  = "json"
+	REPLAY_FORMAT_CAPNP = "capnp"
+)
+
+// Handle runs the cli commands and returns the transport mapd should use when
+// no command was given. replay is passed in because replaying logs needs the
+// mapd loop from the main package.
+func Handle(replay func(ReplayOptions) error) cereal.TransportConfig {
 	shouldExit := true
+	transportConfig := cereal.TransportConfig{}
 	cmd := &cli.Command{
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "transport",
+				Usage:   "How mapd sends and receives messages: msgq for openpilot's shared memory queues, or tcp",
+				Sources: cli.EnvVars("MAPD_TRANSPORT"),
+				Value:   cereal.TRANSPORT_MSGQ,
+			},
+			&cli.StringFlag{
+				Name:    "transport-host",
+				Usage:   "The host the tcp transport subscribes to messages from",
+				Sources: cli.EnvVars("MAPD_TRANSPORT_HOST"),
+				Value:   "127.0.0.1",
+			},
+			&cli.StringFlag{
+				Name:    "transport-bind",
+				Usage:   "The address the tcp transport publishes messages on",
+				Sources: cli.EnvVars("MAPD_TRANSPORT_BIND"),
+				Value:   "127.0.0.1",
+			},
+		},
+		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+			transportConfig = cereal.TransportConfig{
+				Type: cmd.String("transport"),
+				Host: cmd.String("transport-host"),
+				Bind: cmd.String("transport-bind"),
+			}
+			return ctx, nil
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "interactive",
 				Aliases: []string{"i"},
 				Usage:   "Send commands to an active mapd instance",
 				Action: func(ctx context.Context, cmd *cli.Command) error {
-					interactive()
-					return nil
+					return interactive(transportConfig)
 				},
 			},
 			{
@@ -103,6 +151,96 @@ func Handle() {
 					return nil
 				},
 			},
+			{
+				Name:    "overrides",
+				Aliases: []string{"o"},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Usage:   "The output format of the override events: text, geojson, or gpx",
+						Aliases: []string{"f"},
+						Value:   overrides.FORMAT_TEXT,
+					},
+					&cli.StringFlag{
+						Name:    "output-file",
+						Usage:   "The file to write the override events to instead of stdout",
+						Aliases: []string{"o"},
+					},
+					&cli.BoolFlag{
+						Name:  "clear",
+						Usage: "Removes all logged override events",
+						Value: false,
+					},
+				},
+				Usage: "Lists or exports the places where the driver overrode the speed limit",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					if cmd.Bool("clear") {
+						return overrides.Clear()
+					}
+					events, err := overrides.Read()
+					if err != nil {
+						return err
+					}
+					out := os.Stdout
+					if cmd.String("output-file") != "" {
+						out, err = os.Create(cmd.String("output-file"))
+						if err != nil {
+							return err
+						}
+						defer out.Close()
+					}
+					return overrides.Export(out, events, cmd.String("format"))
+				},
+			},
+			{
+				Name:      "replay",
+				Aliases:   []string{"r"},
+				ArgsUsage: "<rlog or qlog files in order>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output-file",
+						Usage:   "The file to write the mapd outputs to",
+						Aliases: []string{"o"},
+						Value:   "./replay.jsonl",
+					},
+					&cli.StringFlag{
+						Name:    "format",
+						Usage:   "The output format: json for one event per line, or capnp for a raw event stream like an rlog",
+						Aliases: []string{"f"},
+						Value:   REPLAY_FORMAT_JSON,
+					},
+					&cli.StringFlag{
+						Name:    "settings-file",
+						Usage:   "A json settings file to replay with instead of the default settings",
+						Aliases: []string{"s"},
+					},
+					&cli.StringFlag{
+						Name:    "tiles-directory",
+						Usage:   "The base directory of the offline map files",
+						Aliases: []string{"t"},
+						Value:   maps.DEFAULT_SETTINGS.OutputDirectory,
+					},
+					&cli.StringFlag{
+						Name:    "way-overrides-file",
+						Usage:   "A way overrides file to replay with",
+						Aliases: []string{"w"},
+					},
+				},
+				Usage: "Replays recorded openpilot logs through mapd and writes every output to a file",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					if cmd.Args().Len() == 0 {
+						return fmt.Errorf("no log files to replay")
+					}
+					return replay(ReplayOptions{
+						Logs:             cmd.Args().Slice(),
+						OutputFile:       cmd.String("output-file"),
+						Format:           cmd.String("format"),
+						SettingsFile:     cmd.String("settings-file"),
+						TilesDirectory:   cmd.String("tiles-directory"),
+						WayOverridesFile: cmd.String("way-overrides-file"),
+					})
+				},
+			},
 		},
 		Name:  "Mapd",
 		Usage: "Start an instance of mapd",
@@ -119,4 +257,5 @@ func Handle() {
 	if shouldExit {
 		os.Exit(0)
 	}
+	return transportConfig
 }
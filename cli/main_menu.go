@@ -56,7 +56,7 @@ func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.title }
 
-func initialModel() uiModel {
+func initialModel(transport cereal.Transport) (uiModel, error) {
 	items := []list.Item{
 		item{title: "Settings", desc: "Modify settings of an active instance of mapd", state: showSettings},
 		item{title: "Download", desc: "Trigger a download of maps in an active instance of mapd", state: showDownload},
@@ -65,12 +65,21 @@ func initialModel() uiModel {
 	}
 
 	listDelegate := list.NewDefaultDelegate()
-	pub := cereal.NewPublisher("mapdIn", cereal.MapdInCreator)
-	sub := cereal.NewSubscriber("mapdOut", cereal.MapdOutReader, true, false)
-	extendedSub := cereal.NewSubscriber("mapdExtendedOut", cereal.MapdExtendedOutReader, true, false)
+	pub, err := cereal.NewPublisher(transport, "mapdIn", cereal.MapdInCreator)
+	if err != nil {
+		return uiModel{}, err
+	}
+	sub, err := cereal.NewSubscriber(transport, "mapdOut", cereal.MapdOutReader, true, false)
+	if err != nil {
+		return uiModel{}, err
+	}
+	extendedSub, err := cereal.NewSubscriber(transport, "mapdExtendedOut", cereal.MapdExtendedOutReader, true, false)
+	if err != nil {
+		return uiModel{}, err
+	}
 	m := uiModel{list: list.New(items, listDelegate, 0, 0), settings: getSettingsModel(), pub: &pub, sub: &sub, extendedSub: &extendedSub, download: getDownloadModel()}
 	m.list.Title = "Mapd Actions"
-	return m
+	return m, nil
 }
 
 func (m uiModel) Init() tea.Cmd {
@@ -140,10 +149,19 @@ func (m uiModel) View() string {
 	return docStyle.Render(m.list.View())
 }
 
-func interactive() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+func interactive(config cereal.TransportConfig) error {
+	transport, err := config.Transport()
+	if err != nil {
+		return err
+	}
+	m, err := initialModel(transport)
+	if err != nil {
+		return err
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	return nil
 }
@@ -100,10 +100,13 @@ func (m outputModel) View() string {
 	}
 
 	roadname, _ := m.output.RoadName()
+	activeLimiter, _ := m.output.ActiveSpeedLimiter()
 	return docStyle.Render(fmt.Sprintf(
-		"name: %s\nsuggested speed: %f\nspeed limit: %f\nspeed limit suggested speed: %f\nnext speed limit: %f\nnext speed limit distance: %f\nvision curve speed: %f\nmap curve speed: %f\ndistance from center: %f\nlanes: %d\nselection type: %s\n\n%s",
+		"name: %s\nsuggested speed: %f\nactive speed limiter: %s\nsuggested speed reason: %s\nspeed limit: %f\nspeed limit suggested speed: %f\nnext speed limit: %f\nnext speed limit distance: %f\nvision curve speed: %f\nmap curve speed: %f\ndistance from center: %f\nlanes: %d\nselection type: %s\n\n%s",
 		roadname,
 		m.output.SuggestedSpeed(),
+		activeLimiter,
+		m.output.SuggestedSpeedReason().String(),
 		m.output.SpeedLimit(),
 		m.output.SpeedLimitSuggestedSpeed(),
 		m.output.NextSpeedLimit(),
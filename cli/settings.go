@@ -241,6 +241,108 @@ var settingsList = []list.Item{
 		state:       settingsInput,
 		value:       func() string { return fmt.Sprintf("%f meters", ms.Settings.DefaultLaneWidth) },
 	},
+	settingsItem{
+		title:       "Drive On Left",
+		desc:        "Set when traffic drives on the left side of the road, like in the UK or Japan",
+		MessageType: custom.MapdInputType_setDriveOnLeft,
+		Type:        Bool,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%t", ms.Settings.DriveOnLeft) },
+	},
+	settingsItem{
+		title:       "Way Matching Mode",
+		desc:        "Sets how mapd matches the gps position to the current road",
+		MessageType: custom.MapdInputType_setWayMatchingMode,
+		Type:        Options,
+		state:       settingsInput,
+		options: []list.Item{
+			settingsItem{title: "heuristic", value: func() string { return "" }},
+			settingsItem{title: "hmm", value: func() string { return "" }},
+		},
+		value: func() string { return fmt.Sprintf("%s", ms.Settings.WayMatchingMode) },
+	},
+	settingsItem{
+		title:       "Position Filter",
+		desc:        "Smooths the gps position with the car's speed and yaw rate using a kalman filter",
+		MessageType: custom.MapdInputType_setPositionFilterEnabled,
+		Type:        Bool,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%t", ms.Settings.PositionFilterEnabled) },
+	},
+	settingsItem{
+		title:       "Dead Reckoning Max Distance",
+		desc:        "How far mapd will follow the road using the car's speed after losing gps before the position is considered stale. 0 disables dead reckoning",
+		MessageType: custom.MapdInputType_setDeadReckoningMaxDistance,
+		Type:        Float,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%f meters", ms.Settings.DeadReckoningMaxDistance) },
+	},
+	settingsItem{
+		title:       "Position Source",
+		desc:        "Sets where mapd gets its position from. auto picks the most accurate source",
+		MessageType: custom.MapdInputType_setPositionSource,
+		Type:        Options,
+		state:       settingsInput,
+		options: []list.Item{
+			settingsItem{title: "auto", value: func() string { return "" }},
+			settingsItem{title: "gpsLocation", value: func() string { return "" }},
+			settingsItem{title: "gpsLocationExternal", value: func() string { return "" }},
+			settingsItem{title: "liveLocationKalman", value: func() string { return "" }},
+		},
+		value: func() string { return fmt.Sprintf("%s", ms.Settings.PositionSource) },
+	},
+	settingsItem{
+		title:       "Curve Speed Mode",
+		desc:        "Sets how the map and vision curve speeds are combined. minimum uses the lowest, fusion weights them by confidence",
+		MessageType: custom.MapdInputType_setCurveSpeedMode,
+		Type:        Options,
+		state:       settingsInput,
+		options: []list.Item{
+			settingsItem{title: "minimum", value: func() string { return "" }},
+			settingsItem{title: "fusion", value: func() string { return "" }},
+		},
+		value: func() string { return fmt.Sprintf("%s", ms.Settings.CurveSpeedMode) },
+	},
+	settingsItem{
+		title:       "Curve Learning Enabled",
+		desc:        "Learns how fast you take each curve and blends it into the map curve speed",
+		MessageType: custom.MapdInputType_setCurveLearningEnabled,
+		Type:        Enable,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%t", ms.Settings.CurveLearningEnabled) },
+	},
+	settingsItem{
+		title:       "Web Server Enabled",
+		desc:        "Serves a page for viewing map data and live mapd output in a browser",
+		MessageType: custom.MapdInputType_setWebServerEnabled,
+		Type:        Enable,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%t", ms.Settings.WebServerEnabled) },
+	},
+	settingsItem{
+		title:       "Web Server Port",
+		desc:        "The port the web server listens on",
+		MessageType: custom.MapdInputType_setWebServerPort,
+		Type:        Float,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%.0f", ms.Settings.WebServerPort) },
+	},
+	settingsItem{
+		title:       "Web Server Address",
+		desc:        "The address the web server listens on. 0.0.0.0 makes it reachable from the local network",
+		MessageType: custom.MapdInputType_setWebServerAddress,
+		Type:        String,
+		state:       settingsInput,
+		value:       func() string { return ms.Settings.WebServerAddress },
+	},
+	settingsItem{
+		title:       "Output Rate",
+		desc:        "How many times per second mapd publishes its output",
+		MessageType: custom.MapdInputType_setOutputRate,
+		Type:        Float,
+		state:       settingsInput,
+		value:       func() string { return fmt.Sprintf("%.0f", ms.Settings.OutputRate) },
+	},
 	settingsItem{
 		title:       "Set Log Level",
 		desc:        "Modify how verbose logging will be for the mapd system",
@@ -271,6 +373,12 @@ var settingsList = []list.Item{
 		state:       settingsInput,
 		value:       func() string { return fmt.Sprintf("%t", ms.Settings.LogSource) },
 	},
+	settingsItem{
+		title: "Reset Learned Curves",
+		desc:  "Removes all learned curve speeds",
+		state: resetLearnedCurves,
+		value: func() string { return "" },
+	},
 	settingsItem{
 		title: "Load Default Settings",
 		desc:  "Loads the default settings",
@@ -363,6 +471,7 @@ const (
 	saveSettings
 	defaultSettings
 	recommendedSettings
+	resetLearnedCurves
 )
 
 type settingsItem struct {
@@ -447,6 +556,16 @@ func (m settingsModel) Update(msg tea.Msg, mm *uiModel) (settingsModel, tea.Cmd)
 				}
 
 				m.saveSettings(mm)
+			case resetLearnedCurves:
+				m.state = showSettingsMenu
+				msg, input := mm.pub.NewMessage(true)
+
+				input.SetType(custom.MapdInputType_resetLearnedCurves)
+
+				err := mm.pub.Send(msg)
+				if err != nil {
+					panic(err)
+				}
 			case unitsInput:
 				m.list.SetItems(unitsList)
 				m.list.Title = "Select Units"
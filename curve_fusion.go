@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// CurveFusion combines the map and vision curve speeds into a single target.
+// The targets are averaged as curvatures (1/v^2) weighted by how much each
+// source can be trusted, a map that confidently sees a straight road counts
+// against a vision curve. A confident source that wants to be slower than the
+// average is followed so its curve isn't washed out by the other source.
+type CurveFusion struct {
+	Target           float32 // m/s, 0 when neither source wants to slow down
+	MapWeight        float32 // share of the target from the map curve speed
+	VisionWeight     float32 // share of the target from the vision curve speed
+	MapConfidence    float32
+	VisionConfidence float32
+	visionMean       float64
+	visionVariance   float64
+	visionStarted    bool
+}
+
+// UpdateVisionConsistency tracks how much the raw vision curve speed jumps
+// between model frames. Steady predictions are trusted more.
+func (c *CurveFusion) UpdateVisionConsistency(vTarget float32) {
+	v := float64(vTarget)
+	if !c.visionStarted {
+		c.visionMean = v
+		c.visionVariance = 0
+		c.visionStarted = true
+	}
+	delta := v - c.visionMean
+	c.visionMean += ms.FUSION_VISION_ALPHA * delta
+	c.visionVariance = (1 - ms.FUSION_VISION_ALPHA) * (c.visionVariance + ms.FUSION_VISION_ALPHA*delta*delta)
+	c.VisionConfidence = float32(math.Exp(-math.Sqrt(c.visionVariance) / ms.FUSION_VISION_STD))
+}
+
+// Update computes the fused target from the current state.
+func (c *CurveFusion) Update(s *State) {
+	c.MapConfidence = mapConfidence(s)
+
+	mapActive := ms.Settings.MapCurveSpeedControlEnabled && (!ms.Settings.MapCurveUseEnableSpeed || s.Car.EnableSpeedActive)
+	visionActive := ms.Settings.VisionCurveSpeedControlEnabled && s.VisionCurveSpeed > 0 && (!ms.Settings.VisionCurveUseEnableSpeed || s.Car.EnableSpeedActive)
+
+	mapWeight := 0.0
+	if mapActive {
+		mapWeight = float64(c.MapConfidence)
+	}
+	visionWeight := 0.0
+	if visionActive {
+		visionWeight = float64(c.VisionConfidence)
+	}
+	total := mapWeight + visionWeight
+	if total <= 0 {
+		c.Target = 0
+		c.MapWeight = 0
+		c.VisionWeight = 0
+		return
+	}
+
+	curvature := (mapWeight*targetCurvature(s.MapCurveSpeed) + visionWeight*targetCurvature(s.VisionCurveSpeed)) / total
+	c.MapWeight = float32(mapWeight / total)
+	c.VisionWeight = float32(visionWeight / total)
+	target := float32(1 / math.Sqrt(curvature))
+	if target >= ms.MAX_OP_SPEED {
+		target = 0
+	}
+	c.Target = target
+
+	if mapWeight >= ms.FUSION_CONFIDENT && c.slowerThanTarget(s.MapCurveSpeed) {
+		c.Target, c.MapWeight, c.VisionWeight = s.MapCurveSpeed, 1, 0
+	}
+	if visionWeight >= ms.FUSION_CONFIDENT && c.slowerThanTarget(s.VisionCurveSpeed) {
+		c.Target, c.MapWeight, c.VisionWeight = s.VisionCurveSpeed, 0, 1
+	}
+}
+
+// slowerThanTarget reports whether a curve speed would slow the car down more
+// than the current target.
+func (c *CurveFusion) slowerThanTarget(speed float32) bool {
+	return speed > 0 && speed < ms.MAX_OP_SPEED && (c.Target == 0 || speed < c.Target)
+}
+
+// targetCurvature converts a curve speed into a value proportional to the
+// curvature. No curve is treated as the max openpilot speed.
+func targetCurvature(speed float32) float64 {
+	if speed <= 0 || speed > ms.MAX_OP_SPEED {
+		speed = ms.MAX_OP_SPEED
+	}
+	return 1 / float64(speed*speed)
+}
+
+// mapConfidence rates the map curve speed by how the current way was
+// selected, the gps accuracy, and whether the loaded tiles cover the position.
+func mapConfidence(s *State) float32 {
+	selection := float32(0)
+	switch s.CurrentWay.SelectionType {
+	case custom.WaySelectionType_current, custom.WaySelectionType_predicted:
+		selection = 1
+	case custom.WaySelectionType_hmm:
+		selection = s.CurrentWay.Confidence
+	case custom.WaySelectionType_extended:
+		selection = 0.6
+	case custom.WaySelectionType_possible, custom.WaySelectionType_deadReckoning:
+		selection = 0.5
+	}
+
+	accuracy := 1 - (s.PositionAccuracy-ms.FUSION_GOOD_ACCURACY)/(ms.FUSION_BAD_ACCURACY-ms.FUSION_GOOD_ACCURACY)
+	accuracy = min(max(accuracy, 0), 1)
+
+	tile := float32(0)
+	if s.Data.Loaded {
+		box := s.Data.Box()
+		tile = ms.FUSION_OVERLAP_TILE
+		if box.PosInside(s.Position) {
+			tile = 1
+		}
+	}
+	return selection * accuracy * tile
+}
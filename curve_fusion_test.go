@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+func TestCurveFusion(t *testing.T) {
+	ms.Settings.Default()
+	ms.Settings.MapCurveSpeedControlEnabled = true
+	ms.Settings.VisionCurveSpeedControlEnabled = true
+	t.Cleanup(ms.Settings.Default)
+
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 500), 100)...).ID(1)
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name             string
+		mapSpeed         float32
+		visionSpeed      float32
+		positionAccuracy float32 // meters, lowers the map confidence
+		visionConfidence float32
+		wantTarget       float32
+		wantMapWeight    float32
+		wantVisionWeight float32
+	}{
+		{
+			name: "vision curve, map straight", mapSpeed: 0, visionSpeed: 15,
+			positionAccuracy: 1, visionConfidence: 0.9,
+			wantTarget: 15, wantVisionWeight: 1,
+		},
+		{
+			name: "map curve, vision straight", mapSpeed: 12, visionSpeed: 0,
+			positionAccuracy: 1, visionConfidence: 0.9,
+			wantTarget: 12, wantMapWeight: 1,
+		},
+		{
+			name: "both curves", mapSpeed: 12, visionSpeed: 15,
+			positionAccuracy: 1, visionConfidence: 0.9,
+			wantTarget: 12, wantMapWeight: 1,
+		},
+		{
+			// the confident straight map damps the vision curve
+			name: "unsteady vision curve, map straight", mapSpeed: 0, visionSpeed: 15,
+			positionAccuracy: 1, visionConfidence: 0.2,
+			wantTarget: 28.22, wantMapWeight: 0.83, wantVisionWeight: 0.17,
+		},
+		{
+			// 20 m accuracy rates the map at 0.4, both are averaged
+			name: "neither confident", mapSpeed: 10, visionSpeed: 20,
+			positionAccuracy: 20, visionConfidence: 0.4,
+			wantTarget: 12.65, wantMapWeight: 0.5, wantVisionWeight: 0.5,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := State{}
+			s.Init()
+			s.Data = offline
+			s.Position = b.Position(tb.Pt(0, 250))
+			s.PositionAccuracy = c.positionAccuracy
+			s.CurrentWay.SelectionType = custom.WaySelectionType_current
+			s.MapCurveSpeed = c.mapSpeed
+			s.VisionCurveSpeed = c.visionSpeed
+			s.CurveFusion.VisionConfidence = c.visionConfidence
+
+			s.CurveFusion.Update(&s)
+			got := s.CurveFusion
+			if !near(got.Target, c.wantTarget) || !near(got.MapWeight, c.wantMapWeight) || !near(got.VisionWeight, c.wantVisionWeight) {
+				t.Errorf("got target %.2f map weight %.2f vision weight %.2f, want %.2f %.2f %.2f",
+					got.Target, got.MapWeight, got.VisionWeight, c.wantTarget, c.wantMapWeight, c.wantVisionWeight)
+			}
+		})
+	}
+}
+
+func near(got float32, want float32) bool {
+	return got-want < 0.01 && want-got < 0.01
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal/custom"
+	m "pfeifer.dev/mapd/math"
+	"pfeifer.dev/mapd/params"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+var LEARNED_CURVES_PATH = filepath.Join(params.BasePath, "learned_curves.json")
+
+// LearnedCurve is how the driver takes the curve at a node of a way, averaged
+// over recent passes.
+type LearnedCurve struct {
+	WayId  int64   `json:"way_id"`
+	NodeId int64   `json:"node_id"`
+	LatA   float64 `json:"lat_a"` // m/s^2, peak lateral acceleration near the node
+	Speed  float64 `json:"speed"` // m/s, average speed near the node
+	Passes int     `json:"passes"`
+}
+
+type curvePass struct {
+	wayId     int64
+	nodeId    int64
+	maxLatA   float64
+	speedSum  float64
+	samples   int
+	unlearned bool
+}
+
+// CurveLearner records the lateral acceleration the driver actually uses at
+// each curve node so map curve targets can be personalized. Data is kept in a
+// json file on the device.
+type CurveLearner struct {
+	curves   map[int64]map[int64]*LearnedCurve // way id -> node id -> curve
+	loaded   bool
+	dirty    bool
+	lastSave time.Time
+	pass     *curvePass
+}
+
+// Record adds a carState sample to the pass of the nearest curve node.
+func (l *CurveLearner) Record(s *State, yawRate float32) {
+	if !ms.Settings.CurveLearningEnabled {
+		l.pass = nil
+		return
+	}
+	l.load()
+
+	nodeId := int64(0)
+	nearest := float32(ms.LEARN_NODE_RADIUS)
+	for _, tv := range s.TargetVelocities {
+		if tv.Velocity <= 0 || tv.Pos.NodeID() == 0 {
+			continue
+		}
+		d := s.Position.DistanceTo(tv.Pos)
+		if d < nearest {
+			nearest = d
+			nodeId = tv.Pos.NodeID()
+		}
+	}
+
+	wayId := s.CurrentWay.Way.ID()
+	if l.pass != nil && (l.pass.nodeId != nodeId || l.pass.wayId != wayId) {
+		l.finishPass()
+	}
+	if nodeId == 0 || wayId == 0 {
+		l.save(s.Clock.Now())
+		return
+	}
+	if l.pass == nil {
+		l.pass = &curvePass{wayId: wayId, nodeId: nodeId}
+	}
+	l.pass.maxLatA = math.Max(l.pass.maxLatA, math.Abs(float64(s.Car.VEgo*yawRate)))
+	l.pass.speedSum += float64(s.Car.VEgo)
+	l.pass.samples++
+	// passes where the driver sped up past the suggestion or drove too slowly
+	// to tell anything about the curve are not learned, neither are passes
+	// where the car followed a mapd curve speed instead of the driver
+	l.pass.unlearned = l.pass.unlearned || s.Car.GasPressed || s.Car.VEgo < ms.LEARN_MIN_SPEED || followingCurveSpeed(s)
+}
+
+// followingCurveSpeed reports whether a curve speed was the reason for the
+// last suggested speed.
+func followingCurveSpeed(s *State) bool {
+	switch s.ActiveReason {
+	case custom.SpeedReason_curve, custom.SpeedReason_mapCurve, custom.SpeedReason_visionCurve:
+		return true
+	}
+	return false
+}
+
+// PathCurves are the learned curves of the current and next ways.
+type PathCurves struct {
+	wayIds []int64                           // current way first, then the next ways in order
+	curves map[int64]map[int64]*LearnedCurve // way id -> node id -> curve
+}
+
+// Find returns the learned curve at a node of the path. A node shared by two
+// ways of the path uses the curve learned on the earlier way.
+func (p PathCurves) Find(nodeId int64) (LearnedCurve, bool) {
+	if nodeId == 0 {
+		return LearnedCurve{}, false
+	}
+	for _, wayId := range p.wayIds {
+		if curve, ok := p.curves[wayId][nodeId]; ok {
+			return *curve, true
+		}
+	}
+	return LearnedCurve{}, false
+}
+
+// PathCurves returns the learned curves on the current and next ways.
+func (l *CurveLearner) PathCurves(s *State) PathCurves {
+	if !ms.Settings.CurveLearningEnabled {
+		return PathCurves{}
+	}
+	l.load()
+	wayIds := []int64{s.CurrentWay.Way.ID()}
+	for _, next := range s.NextWays {
+		wayIds = append(wayIds, next.Way.ID())
+	}
+	return PathCurves{wayIds: wayIds, curves: l.curves}
+}
+
+// Reset removes all learned curves from memory and disk.
+func (l *CurveLearner) Reset() {
+	l.curves = map[int64]map[int64]*LearnedCurve{}
+	l.loaded = true
+	l.dirty = false
+	l.pass = nil
+	err := os.Remove(LEARNED_CURVES_PATH)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Warn("could not remove learned curves", "error", err)
+		return
+	}
+	slog.Info("Reset learned curves")
+}
+
+func (l *CurveLearner) finishPass() {
+	pass := l.pass
+	l.pass = nil
+	if pass.unlearned || pass.samples < ms.LEARN_MIN_SAMPLES {
+		return
+	}
+	ways, ok := l.curves[pass.wayId]
+	if !ok {
+		ways = map[int64]*LearnedCurve{}
+		l.curves[pass.wayId] = ways
+	}
+	curve, ok := ways[pass.nodeId]
+	if !ok {
+		curve = &LearnedCurve{WayId: pass.wayId, NodeId: pass.nodeId}
+		ways[pass.nodeId] = curve
+	}
+	// capping the passes keeps the average following recent driving
+	curve.Passes = min(curve.Passes+1, ms.LEARN_MAX_PASSES)
+	curve.LatA += (pass.maxLatA - curve.LatA) / float64(curve.Passes)
+	curve.Speed += (pass.speedSum/float64(pass.samples) - curve.Speed) / float64(curve.Passes)
+	l.dirty = true
+}
+
+func (l *CurveLearner) load() {
+	if l.loaded {
+		return
+	}
+	l.loaded = true
+	l.curves = map[int64]map[int64]*LearnedCurve{}
+	data, err := os.ReadFile(LEARNED_CURVES_PATH)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		slog.Warn("could not read learned curves", "error", err)
+		return
+	}
+	curves := []LearnedCurve{}
+	err = json.Unmarshal(data, &curves)
+	if err != nil {
+		slog.Warn("could not parse learned curves", "error", err)
+		return
+	}
+	for _, curve := range curves {
+		if _, ok := l.curves[curve.WayId]; !ok {
+			l.curves[curve.WayId] = map[int64]*LearnedCurve{}
+		}
+		l.curves[curve.WayId][curve.NodeId] = &curve
+	}
+	slog.Info("Loaded learned curves", "count", len(curves))
+}
+
+// save writes the learned curves to disk at most once per save interval.
+func (l *CurveLearner) save(now time.Time) {
+	if !l.dirty || now.Sub(l.lastSave) < ms.LEARN_SAVE_INTERVAL {
+		return
+	}
+	l.lastSave = now
+	err := l.write()
+	if err != nil {
+		slog.Warn("could not save learned curves", "error", err)
+		return
+	}
+	l.dirty = false
+}
+
+func (l *CurveLearner) write() error {
+	curves := []LearnedCurve{}
+	for _, ways := range l.curves {
+		for _, curve := range ways {
+			curves = append(curves, *curve)
+		}
+	}
+	data, err := json.Marshal(curves)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal learned curves")
+	}
+	err = os.MkdirAll(filepath.Dir(LEARNED_CURVES_PATH), 0o775)
+	if err != nil {
+		return errors.Wrap(err, "could not make learned curves directory")
+	}
+	// write to a temporary file first so a power loss can't corrupt the data
+	tmp := LEARNED_CURVES_PATH + ".tmp"
+	err = os.WriteFile(tmp, data, 0o664)
+	if err != nil {
+		return errors.Wrap(err, "could not write learned curves")
+	}
+	return errors.Wrap(os.Rename(tmp, LEARNED_CURVES_PATH), "could not replace learned curves")
+}
+
+// learnedLatA blends the target lateral acceleration with the learned value
+// for a node. The learned value is trusted more as passes accumulate.
+func learnedLatA(targetLatA float64, pos m.Position, learned PathCurves) float64 {
+	curve, ok := learned.Find(pos.NodeID())
+	if !ok || curve.Passes == 0 {
+		return targetLatA
+	}
+	latA := min(max(curve.LatA, ms.LEARN_MIN_LAT_A), ms.LEARN_MAX_LAT_A)
+	weight := float64(curve.Passes) / float64(curve.Passes+ms.LEARN_PRIOR_PASSES)
+	return targetLatA + weight*(latA-targetLatA)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+func learnerTest(t *testing.T) {
+	t.Helper()
+	ms.Settings.Default()
+	ms.Settings.CurveLearningEnabled = true
+	path := LEARNED_CURVES_PATH
+	LEARNED_CURVES_PATH = filepath.Join(t.TempDir(), "learned_curves.json")
+	t.Cleanup(func() {
+		LEARNED_CURVES_PATH = path
+		ms.Settings.Default()
+	})
+}
+
+func TestCurveLearnerFollowingCurveSpeed(t *testing.T) {
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 500), 100)...).ID(1)
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	way := offline.Ways()[0]
+	node := way.Nodes()[2]
+
+	cases := []struct {
+		name   string
+		reason custom.SpeedReason
+		want   bool
+	}{
+		{name: "driver choosing the speed", reason: custom.SpeedReason_none, want: true},
+		{name: "following the map curve speed", reason: custom.SpeedReason_mapCurve, want: false},
+		{name: "following the fused curve speed", reason: custom.SpeedReason_curve, want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			learnerTest(t)
+			s := State{}
+			s.Init()
+			s.CurrentWay.Way = way
+			s.TargetVelocities = []Velocity{{Pos: node, Velocity: 15}}
+			s.Car.VEgo = 15
+			s.ActiveReason = c.reason
+
+			learner := CurveLearner{}
+			s.Position = node
+			for range ms.LEARN_MIN_SAMPLES {
+				learner.Record(&s, 0.1)
+			}
+			// leaving the node finishes the pass
+			s.Position = b.Position(tb.Pt(0, 400))
+			learner.Record(&s, 0)
+
+			_, learned := learner.curves[way.ID()][node.NodeID()]
+			if learned != c.want {
+				t.Errorf("learned the pass: %v, want %v", learned, c.want)
+			}
+		})
+	}
+}
+
+func TestPathCurvesJunction(t *testing.T) {
+	learnerTest(t)
+
+	// the fork node at (0, 0) is on all three ways
+	offline, err := tb.Fork().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ways := offline.Ways()
+	junction := ways[0].Nodes()[len(ways[0].Nodes())-1]
+
+	learner := CurveLearner{loaded: true, curves: map[int64]map[int64]*LearnedCurve{}}
+	for i, way := range ways {
+		learner.curves[way.ID()] = map[int64]*LearnedCurve{
+			junction.NodeID(): {WayId: way.ID(), NodeId: junction.NodeID(), LatA: float64(i + 1), Passes: 1},
+		}
+	}
+
+	s := State{}
+	s.CurrentWay.Way = ways[0]
+	s.NextWays = []maps.NextWayResult{{Way: ways[2], IsForward: true}}
+	curve, ok := learner.PathCurves(&s).Find(junction.NodeID())
+	if !ok || curve.WayId != ways[0].ID() {
+		t.Errorf("found the curve of way %d, want the current way %d", curve.WayId, ways[0].ID())
+	}
+
+	// a junction learned only on a way off of the path is not used
+	delete(learner.curves[ways[0].ID()], junction.NodeID())
+	delete(learner.curves[ways[2].ID()], junction.NodeID())
+	curve, ok = learner.PathCurves(&s).Find(junction.NodeID())
+	if ok {
+		t.Errorf("found the curve of way %d, which is not on the path", curve.WayId)
+	}
+}
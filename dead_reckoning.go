@@ -0,0 +1,142 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/maps"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// DeadReckoning moves the vehicle along the current and next ways using the
+// car's speed while there are no gps fixes, e.g. in tunnels. The car's yaw
+// rate is used to detect when we have left the predicted path.
+type DeadReckoning struct {
+	Active   bool
+	Stale    bool
+	Distance float32 // meters driven since the last gps fix
+	heading  float64 // degrees, integrated from the car's yaw rate
+	lastFix  time.Time
+	wayStart float32    // value of Distance when we were at start
+	start    m.Position // position on the current way that walking starts from
+	lastPos  m.Position
+}
+
+// Fix resets dead reckoning when a gps fix is received.
+func (d *DeadReckoning) Fix(loc m.Location, now time.Time) {
+	*d = DeadReckoning{
+		heading: loc.BearingDeg,
+		lastFix: now,
+	}
+}
+
+// Update advances the dead reckoned position. It returns true when the
+// position is being dead reckoned and State has been updated with it.
+func (d *DeadReckoning) Update(s *State, dt float64, yawRate float32) bool {
+	if d.lastFix.IsZero() || d.Stale {
+		return false
+	}
+	d.Distance += float32(dt) * s.Car.VEgo
+	d.heading -= float64(yawRate) * dt * ms.TO_DEGREES
+
+	if s.Clock.Now().Sub(d.lastFix) < ms.DR_START_DELAY || ms.Settings.DeadReckoningMaxDistance <= 0 {
+		return false
+	}
+	if !d.Active {
+		if len(s.CurrentWay.Way.Nodes()) < 2 {
+			return false
+		}
+		d.Active = true
+		d.start = s.CurrentWay.Distance.LinePosition.Pos
+		d.lastPos = d.start
+		slog.Info("gps lost, dead reckoning along current way", "way", s.CurrentWay.Way.ID())
+	}
+
+	if d.Distance > ms.Settings.DeadReckoningMaxDistance {
+		d.expire(s, "max distance reached")
+		return false
+	}
+
+	pos, leftover, err := s.CurrentWay.Way.PositionAlong(d.start, s.CurrentWay.OnWay.IsForward, d.Distance-d.wayStart)
+	for err == nil && leftover > 0 {
+		if len(s.NextWays) == 0 {
+			// hold at the end of the known path until something better comes along
+			break
+		}
+		d.nextWay(s, d.Distance-leftover)
+		pos, leftover, err = s.CurrentWay.Way.PositionAlong(d.start, s.CurrentWay.OnWay.IsForward, d.Distance-d.wayStart)
+	}
+	if err != nil {
+		d.expire(s, err.Error())
+		return false
+	}
+
+	if d.lastPos.DistanceTo(pos) > ms.DR_HEADING_CHECK_DISTANCE {
+		vec := d.lastPos.VectorTo(pos)
+		pathBearing := vec.Bearing() * ms.TO_DEGREES
+		delta := math.Abs(math.Mod(pathBearing-d.heading+540, 360) - 180)
+		if delta > ms.DR_MAX_HEADING_DELTA {
+			d.expire(s, "yaw rate no longer matches the path")
+			return false
+		}
+		d.heading = pathBearing
+		d.lastPos = pos
+	}
+
+	onWay := s.CurrentWay.OnWay
+	distance, err := s.CurrentWay.Way.DistanceFrom(pos)
+	if err == nil {
+		onWay.Distance = distance
+		s.CurrentWay.Distance = distance
+	}
+	s.CurrentWay.OnWay = onWay
+	s.CurrentWay.SelectionType = custom.WaySelectionType_deadReckoning
+	s.Position = pos
+	s.DistanceSinceLastPosition = 0
+	return true
+}
+
+// nextWay moves the current way forward to the first of the next ways.
+// reachedAt is the value of Distance when the start of the next way was
+// reached.
+func (d *DeadReckoning) nextWay(s *State, reachedAt float32) {
+	next := s.NextWays[0]
+	onWay := s.CurrentWay.OnWay
+	onWay.IsForward = next.IsForward
+	s.CurrentWay = CurrentWay{
+		Way:               next.Way,
+		OnWay:             onWay,
+		StartPosition:     next.StartPosition,
+		EndPosition:       next.EndPosition,
+		ConfidenceCounter: 1,
+		LastChangeTime:    s.Clock.Now(),
+		SelectionType:     custom.WaySelectionType_deadReckoning,
+	}
+	d.start = next.StartPosition
+	d.wayStart = reachedAt
+
+	loc := m.Location{Pos: next.StartPosition, BearingDeg: d.heading, Speed: s.Car.VEgo}
+	nextWays, err := NextWays(loc, s.CurrentWay, &s.Data, next.IsForward)
+	if err != nil {
+		slog.Debug("could not get next way while dead reckoning", "error", err)
+	}
+	s.NextWays = nextWays
+	s.Curvatures, err = GetStateCurvatures(s)
+	if err != nil {
+		slog.Debug("could not get curvatures while dead reckoning", "error", err)
+	}
+	s.UpdateTargetVelocities()
+}
+
+func (d *DeadReckoning) expire(s *State, reason string) {
+	slog.Info("dead reckoning expired, position is stale", "reason", reason, "distance", d.Distance)
+	d.Active = false
+	d.Stale = true
+	s.CurrentWay = CurrentWay{SelectionType: custom.WaySelectionType_fail}
+	s.NextWays = []maps.NextWayResult{}
+	s.Curvatures = []m.Curvature{}
+	s.TargetVelocities = []Velocity{}
+}
@@ -14,17 +14,20 @@ import (
 type ExtendedState struct {
 	DownloadProgress ms.DownloadProgress
 	Pub              cereal.Publisher[custom.MapdExtendedOut]
+	Timings          PhaseTimings
 	lastSend         time.Time
 	state            *State
 }
 
 func (s *ExtendedState) Send() error {
-	if time.Since(s.lastSend) > 1*time.Second {
-		s.lastSend = time.Now()
+	now := s.state.Clock.Now()
+	if now.Sub(s.lastSend) > 1*time.Second {
+		s.lastSend = now
 		msg, out := s.Pub.NewMessage(true)
 		s.setDownloadProgress(out)
 		s.setSettings(out)
 		s.setPath(out)
+		s.setTimings(out)
 		return s.Pub.Send(msg)
 	}
 	return nil
@@ -35,6 +38,7 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 	num_points := len(nodes)
 	all_nodes := [][]m.Position{nodes}
 	all_nodes_direction := []bool{s.state.CurrentWay.OnWay.IsForward}
+	all_way_ids := []int64{s.state.CurrentWay.Way.ID()}
 	for _, nextWay := range s.state.NextWays {
 		nwNodes := nextWay.Way.Nodes()
 		if len(nwNodes) > 0 {
@@ -42,6 +46,7 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 		}
 		all_nodes = append(all_nodes, nwNodes)
 		all_nodes_direction = append(all_nodes_direction, nextWay.IsForward)
+		all_way_ids = append(all_way_ids, nextWay.Way.ID())
 	}
 
 	path, err := out.NewPath(int32(num_points))
@@ -50,6 +55,7 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 		return
 	}
 
+	path_nodes := make([]m.Position, num_points)
 	all_nodes_idx := 0
 	nodes_idx := 0
 	for i := 0; i < num_points; i++ {
@@ -67,9 +73,12 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 			}
 		}
 		node := all_nodes[all_nodes_idx][index]
+		path_nodes[i] = node
 		point := path.At(i)
 		point.SetLatitude(node.Lat())
 		point.SetLongitude(node.Lon())
+		point.SetNodeId(node.NodeID())
+		point.SetWayId(all_way_ids[all_nodes_idx])
 		nodes_idx += 1
 		if nodes_idx == len(all_nodes[all_nodes_idx]) || (nodes_idx == len(all_nodes[all_nodes_idx])-1 && all_nodes_idx > 0) {
 			all_nodes_idx += 1
@@ -79,9 +88,8 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 	point_idx := 0
 	for _, curvature := range s.state.Curvatures {
 		for ; point_idx < path.Len(); point_idx++ {
-			point := path.At(point_idx)
-			pos := m.NewPosition(point.Latitude(), point.Longitude())
-			if curvature.Pos.Equals(pos) {
+			if curvature.Pos.SameNode(path_nodes[point_idx]) {
+				point := path.At(point_idx)
 				point.SetCurvature(float32(curvature.Curvature))
 				point_idx++
 				break
@@ -91,9 +99,8 @@ func (s *ExtendedState) setPath(out custom.MapdExtendedOut) {
 	point_idx = 0
 	for _, velocity := range s.state.TargetVelocities {
 		for ; point_idx < path.Len(); point_idx++ {
-			point := path.At(point_idx)
-			pos := m.NewPosition(point.Latitude(), point.Longitude())
-			if velocity.Pos.Equals(pos) {
+			if velocity.Pos.SameNode(path_nodes[point_idx]) {
+				point := path.At(point_idx)
 				point.SetTargetVelocity(float32(velocity.Velocity))
 				point_idx++
 				break
@@ -113,6 +120,12 @@ func (s *ExtendedState) setSettings(out custom.MapdExtendedOut) {
 	}
 }
 
+func (s *ExtendedState) setTimings(out custom.MapdExtendedOut) {
+	if err := s.Timings.Write(out); err != nil {
+		slog.Warn("failed to set timings in extended state", "error", err)
+	}
+}
+
 func (s *ExtendedState) setDownloadProgress(out custom.MapdExtendedOut) {
 	p, err := out.NewDownloadProgress()
 	if err != nil {
@@ -8,6 +8,7 @@ require (
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/gofrs/flock v0.13.0
+	github.com/klauspost/compress v1.18.0
 	github.com/paulmach/osm v0.8.0
 	github.com/pfeiferj/gomsgq v0.1.10
 	github.com/pkg/errors v0.9.1
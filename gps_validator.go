@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/custom"
+	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+)
+
+// GpsValidator rejects position fixes that can't be trusted before they reach
+// way selection, such as multipath jumps or fixes without a lock. Fixes that
+// are only suspect are accepted with a worse accuracy so the position filter
+// and way matching trust them less.
+type GpsValidator struct {
+	Clock          utils.Clock
+	Accepted       uint32
+	Rejected       uint32
+	Status         custom.GpsFixStatus
+	lastFix        cereal.PositionFix
+	hasLast        bool
+	rejectingSince time.Time
+}
+
+// Check validates a fix against the last accepted fix and the car's speed.
+// It returns false when the fix should be ignored. Down weighted fixes are
+// modified in place.
+func (v *GpsValidator) Check(fix *cereal.PositionFix, vEgo float32) bool {
+	status := v.validate(fix, vEgo)
+	if status != custom.GpsFixStatus_ok && status != custom.GpsFixStatus_bearingMismatch {
+		if v.Status != status {
+			slog.Debug("rejecting gps fix", "reason", status, "source", fix.Source)
+		}
+		v.Status = status
+		v.Rejected++
+		if v.rejectingSince.IsZero() {
+			v.rejectingSince = v.Clock.Now()
+		}
+		return false
+	}
+	v.Status = status
+	v.Accepted++
+	v.rejectingSince = time.Time{}
+	v.lastFix = *fix
+	v.hasLast = true
+	return true
+}
+
+// FixAge returns the seconds since the last accepted fix or -1 when no fix
+// has been accepted yet.
+func (v *GpsValidator) FixAge() float32 {
+	if !v.hasLast {
+		return -1
+	}
+	return float32(v.Clock.Now().Sub(v.lastFix.ReceivedAt).Seconds())
+}
+
+func (v *GpsValidator) validate(fix *cereal.PositionFix, vEgo float32) custom.GpsFixStatus {
+	if !fix.Valid {
+		return custom.GpsFixStatus_noFix
+	}
+	if fix.HorizontalAccuracy > ms.GPS_MAX_ACCURACY {
+		return custom.GpsFixStatus_poorAccuracy
+	}
+	if !v.hasLast {
+		return custom.GpsFixStatus_ok
+	}
+
+	last := v.lastFix.Location()
+	loc := fix.Location()
+	travelled := float64(last.Pos.DistanceTo(loc.Pos))
+	dt := fix.ReceivedAt.Sub(v.lastFix.ReceivedAt).Seconds()
+	speed := float64(max(vEgo, fix.Speed, v.lastFix.Speed))
+	allowed := speed*dt*ms.GPS_JUMP_SPEED_FACTOR + ms.GPS_JUMP_MARGIN + float64(fix.HorizontalAccuracy+v.lastFix.HorizontalAccuracy)
+	if travelled > allowed {
+		// if every fix disagrees for long enough the last accepted fix is the
+		// one that was wrong
+		if v.rejectingSince.IsZero() || v.Clock.Now().Sub(v.rejectingSince) < ms.GPS_MAX_REJECT_TIME {
+			return custom.GpsFixStatus_jump
+		}
+		slog.Info("accepting gps jump after rejecting fixes", "distance", travelled)
+		return custom.GpsFixStatus_ok
+	}
+
+	if fix.Speed > ms.GPS_MIN_BEARING_SPEED && travelled > ms.GPS_BEARING_CHECK_DISTANCE {
+		vec := last.Pos.VectorTo(loc.Pos)
+		travelBearing := vec.Bearing() * ms.TO_DEGREES
+		delta := math.Abs(math.Mod(travelBearing-float64(fix.BearingDeg)+540, 360) - 180)
+		if delta > ms.GPS_MAX_BEARING_DELTA {
+			fix.HorizontalAccuracy *= ms.GPS_DOWNWEIGHT_FACTOR
+			fix.BearingDeg = float32(math.Mod(travelBearing+360, 360))
+			fix.BearingAccuracyDeg = max(fix.BearingAccuracyDeg, ms.GPS_MAX_BEARING_DELTA)
+			return custom.GpsFixStatus_bearingMismatch
+		}
+	}
+	return custom.GpsFixStatus_ok
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/maps"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+type hmmCandidate struct {
+	Way      maps.Way
+	OnWay    maps.OnWayResult
+	emission float64
+}
+
+// hmmOdometry is how far the car drove according to carState when a fix was
+// added.
+type hmmOdometry struct {
+	distance float64 // meters
+	valid    bool
+}
+
+type hmmLayer struct {
+	position   m.Position
+	odometry   hmmOdometry
+	candidates []hmmCandidate
+	// transitions[i][j] is the log probability of moving from candidate i of
+	// the previous layer to candidate j of this layer. nil for the first layer.
+	transitions [][]float64
+}
+
+// WayMatcher matches gps fixes to ways with a hidden Markov model. Each fix
+// adds a layer of candidate ways and the most likely sequence is decoded with
+// Viterbi over a sliding window of the most recent fixes.
+type WayMatcher struct {
+	layers []hmmLayer
+}
+
+func (h *WayMatcher) Reset() {
+	h.layers = nil
+}
+
+// Update adds a gps fix to the model and returns the most likely current way
+// along with the probability of it being correct.
+func (h *WayMatcher) Update(currentWay CurrentWay, offline *maps.Offline, location m.Location, odometry hmmOdometry, now time.Time) (CurrentWay, error) {
+	layer := hmmLayer{
+		position:   location.Pos,
+		odometry:   odometry,
+		candidates: hmmCandidates(offline, location),
+	}
+	if len(layer.candidates) == 0 {
+		h.Reset()
+		return CurrentWay{SelectionType: custom.WaySelectionType_fail}, errors.New("no hmm candidates near position")
+	}
+
+	if len(h.layers) > 0 {
+		layer.transitions = hmmTransitions(h.layers[len(h.layers)-1], layer)
+	}
+	h.layers = append(h.layers, layer)
+	if len(h.layers) > ms.HMM_WINDOW {
+		h.layers = h.layers[len(h.layers)-ms.HMM_WINDOW:]
+		h.layers[0].transitions = nil
+	}
+
+	scores := h.viterbi()
+	bestIdx := 0
+	for i, score := range scores {
+		if score > scores[bestIdx] {
+			bestIdx = i
+		}
+	}
+	total := 0.0
+	for _, score := range scores {
+		total += math.Exp(score - scores[bestIdx])
+	}
+	confidence := float32(1 / total)
+
+	best := layer.candidates[bestIdx]
+	start, end := best.Way.GetStartEnd(best.OnWay.IsForward)
+	res := CurrentWay{
+		Way:               best.Way,
+		Distance:          best.OnWay.Distance,
+		OnWay:             best.OnWay,
+		StartPosition:     start,
+		EndPosition:       end,
+		ConfidenceCounter: 1,
+		LastChangeTime:    now,
+		StableDistance:    best.OnWay.Distance.Distance,
+		SelectionType:     custom.WaySelectionType_hmm,
+		Confidence:        confidence,
+	}
+	if currentWay.Way.SameWay(&best.Way) {
+		res.ConfidenceCounter = currentWay.ConfidenceCounter + 1
+		res.LastChangeTime = currentWay.LastChangeTime
+	}
+	return res, nil
+}
+
+// viterbi returns the log probability of the best path ending in each
+// candidate of the newest layer.
+func (h *WayMatcher) viterbi() []float64 {
+	scores := make([]float64, len(h.layers[0].candidates))
+	for j, c := range h.layers[0].candidates {
+		scores[j] = c.emission
+	}
+	for _, layer := range h.layers[1:] {
+		next := make([]float64, len(layer.candidates))
+		for j, c := range layer.candidates {
+			best := math.Inf(-1)
+			for i, prev := range scores {
+				best = max(best, prev+layer.transitions[i][j])
+			}
+			next[j] = best + c.emission
+		}
+		scores = next
+	}
+	return scores
+}
+
+func hmmCandidates(offline *maps.Offline, location m.Location) []hmmCandidate {
+	pos := location.Pos
+	sigma := float64(max(location.HorizontalAccuracy, 5))
+	radius := float32(sigma*ms.HMM_MAX_SIGMAS) + ms.MAX_ROAD_WIDTH
+	// a bearing from a stopped or slow car can point anywhere, it shouldn't
+	// rule out driving the right way on a one way road
+	bearingTrusted := location.Speed > ms.HMM_MIN_BEARING_SPEED && location.BearingAccuracyDeg <= ms.HMM_MAX_BEARING_ACCURACY
+	candidates := []hmmCandidate{}
+	for _, way := range offline.WaysNear(pos, radius) {
+		d, err := way.DistanceFrom(pos)
+		if err != nil {
+			continue
+		}
+		// positions anywhere on the road surface are equally likely
+		offRoad := math.Max(float64(d.Distance-way.Width()/2), 0)
+		if offRoad > sigma*ms.HMM_MAX_SIGMAS {
+			continue
+		}
+		isForward := maps.IsForward(d.LineStart, d.LineEnd, location.BearingDeg)
+		if !isForward && way.OneWay() && bearingTrusted {
+			continue
+		}
+
+		emission := -0.5 * math.Pow(offRoad/sigma, 2)
+		if bearingTrusted {
+			alignment, err := way.BearingAlignment(location)
+			if err == nil {
+				emission -= float64(alignment) * ms.HMM_BEARING_WEIGHT
+			}
+		}
+
+		candidates = append(candidates, hmmCandidate{
+			Way:      way,
+			OnWay:    maps.OnWayResult{OnWay: true, Distance: d, IsForward: isForward},
+			emission: emission,
+		})
+	}
+	return candidates
+}
+
+func hmmTransitions(prev hmmLayer, cur hmmLayer) [][]float64 {
+	// the distance driven according to carState doesn't cut corners like the
+	// straight line between fixes does
+	travelled := float64(prev.position.DistanceTo(cur.position))
+	if prev.odometry.valid && cur.odometry.valid {
+		travelled = cur.odometry.distance - prev.odometry.distance
+	}
+	transitions := make([][]float64, len(prev.candidates))
+	for i := range prev.candidates {
+		transitions[i] = make([]float64, len(cur.candidates))
+		for j := range cur.candidates {
+			route, connected := hmmRouteDistance(&prev.candidates[i], &cur.candidates[j])
+			logProb := -math.Abs(route-travelled) / ms.HMM_BETA
+			if !connected {
+				logProb += ms.HMM_UNCONNECTED_LOG_PROB
+			}
+			transitions[i][j] = logProb
+		}
+	}
+	return transitions
+}
+
+// hmmRouteDistance returns the distance along the road network between two
+// candidates. Only the same way or directly connected ways are considered
+// connected, otherwise the straight line distance is returned.
+func hmmRouteDistance(from *hmmCandidate, to *hmmCandidate) (float64, bool) {
+	fromAlong := float64(from.OnWay.Distance.AlongDistance)
+	toAlong := float64(to.OnWay.Distance.AlongDistance)
+	if from.Way.SameWay(&to.Way) {
+		return math.Abs(toAlong - fromAlong), true
+	}
+
+	fromNodes := from.Way.Nodes()
+	toNodes := to.Way.Nodes()
+	if len(fromNodes) > 1 && len(toNodes) > 1 {
+		fromEnds := []float64{fromAlong, float64(from.Way.Distance()) - fromAlong}
+		toEnds := []float64{toAlong, float64(to.Way.Distance()) - toAlong}
+		fromNodeEnds := []m.Position{fromNodes[0], fromNodes[len(fromNodes)-1]}
+		toNodeEnds := []m.Position{toNodes[0], toNodes[len(toNodes)-1]}
+		for a, fromNode := range fromNodeEnds {
+			for b, toNode := range toNodeEnds {
+				if fromNode.SameNode(toNode) {
+					return fromEnds[a] + toEnds[b], true
+				}
+			}
+		}
+	}
+
+	fromPos := from.OnWay.Distance.LinePosition.Pos
+	return float64(fromPos.DistanceTo(to.OnWay.Distance.LinePosition.Pos)), false
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+)
+
+// TestHmmFork drives up the right branch of a fork. The branches start on the
+// same node, only the fixes after it can tell them apart.
+func TestHmmFork(t *testing.T) {
+	b := tb.Fork()
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matcher := WayMatcher{}
+	currentWay := CurrentWay{}
+	now := time.Unix(1700000000, 0)
+	driven := 0.0
+	prev := tb.Pt(0, -300)
+	bearing := math.Atan2(60, 600) * 180 / math.Pi
+	for y := -300.0; y <= 600; y += 25 {
+		p := tb.Pt(0, y)
+		heading := 0.0
+		if y > 0 {
+			p = tb.Pt(60*y/600, y)
+			heading = bearing
+		}
+		driven += math.Hypot(p.X-prev.X, p.Y-prev.Y)
+		prev = p
+		now = now.Add(time.Second)
+
+		loc := b.Location(p, heading, 25)
+		currentWay, err = matcher.Update(currentWay, &offline, loc, hmmOdometry{distance: driven, valid: true}, now)
+		if err != nil {
+			t.Fatalf("at y=%.0f: %v", y, err)
+		}
+		want := int64(1)
+		if y >= 100 {
+			want = 3
+		}
+		// right after the fork the branches are within the gps accuracy
+		if (y < 0 || y >= 100) && currentWay.Way.ID() != want {
+			t.Errorf("at y=%.0f matched way %d, want %d", y, currentWay.Way.ID(), want)
+		}
+	}
+	if !currentWay.OnWay.IsForward {
+		t.Error("matched Airport Road backwards")
+	}
+}
+
+func TestHmmOneWayBearing(t *testing.T) {
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 500), 100)...).ID(1).OneWay()
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name            string
+		speed           float32
+		bearingAccuracy float32
+		want            int
+	}{
+		{name: "stopped", speed: 0, bearingAccuracy: 5, want: 1},
+		{name: "inaccurate bearing", speed: 10, bearingAccuracy: 90, want: 1},
+		{name: "driving against the one way", speed: 10, bearingAccuracy: 5, want: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// the bearing points south on a way that only goes north
+			loc := b.Location(tb.Pt(0, 250), 180, c.speed)
+			loc.BearingAccuracyDeg = c.bearingAccuracy
+			got := len(hmmCandidates(&offline, loc))
+			if got != c.want {
+				t.Errorf("got %d candidates, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestHmmOdometry(t *testing.T) {
+	// a corner, the straight line between the fixes cuts it
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 100), 10)...).ID(1)
+	b.Way(tb.Line(tb.Pt(0, 100), tb.Pt(100, 100), 10)...).ID(2)
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layer := func(p tb.Point, bearing float64, odometry hmmOdometry) hmmLayer {
+		loc := b.Location(p, bearing, 10)
+		return hmmLayer{position: loc.Pos, odometry: odometry, candidates: hmmCandidates(&offline, loc)}
+	}
+	candidate := func(l hmmLayer, id int64) int {
+		for i, c := range l.candidates {
+			if c.Way.ID() == id {
+				return i
+			}
+		}
+		t.Fatalf("no candidate for way %d", id)
+		return -1
+	}
+
+	prev := layer(tb.Pt(0, 50), 0, hmmOdometry{distance: 1000, valid: true})
+	driven := layer(tb.Pt(50, 100), 90, hmmOdometry{distance: 1100, valid: true})
+	straight := layer(tb.Pt(50, 100), 90, hmmOdometry{})
+
+	from := candidate(prev, 1)
+	to := candidate(driven, 2)
+	withOdometry := hmmTransitions(prev, driven)[from][to]
+	withoutOdometry := hmmTransitions(prev, straight)[from][to]
+	if withOdometry < -0.5 {
+		t.Errorf("driving the 100 m around the corner scored %f, want about 0", withOdometry)
+	}
+	if withOdometry <= withoutOdometry {
+		t.Errorf("odometry scored %f, no better than the straight line %f", withOdometry, withoutOdometry)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"pfeifer.dev/mapd/cereal/log"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// visionLanes is the number of lanes openpilot sees between our lane and each
+// road edge.
+type visionLanes struct {
+	lanesLeft  int
+	lanesRight int
+	confidence float64
+	updated    time.Time
+}
+
+// LaneEstimator estimates which lane we are in on the current way. The
+// lateral offset from the way's center line gives a rough estimate which is
+// refined with the road edges and lane lines from modelV2. Traffic drives on
+// the right unless the drive_on_left setting is set.
+type LaneEstimator struct {
+	LaneFromLeft  uint8 // 1 is the leftmost lane in our direction, 0 when unknown
+	LaneFromRight uint8 // 1 is the rightmost lane in our direction, 0 when unknown
+	Confidence    float32
+	probs         []float64
+	wayId         int64
+	vision        visionLanes
+}
+
+func (l *LaneEstimator) Reset() {
+	l.LaneFromLeft = 0
+	l.LaneFromRight = 0
+	l.Confidence = 0
+	l.probs = nil
+	l.wayId = 0
+}
+
+// UpdateVision stores the lanes to the left and right of us from modelV2.
+func (l *LaneEstimator) UpdateVision(model log.ModelDataV2, now time.Time) {
+	lines, err := model.LaneLines()
+	if err != nil || lines.Len() < 4 {
+		return
+	}
+	lineProbs, err := model.LaneLineProbs()
+	if err != nil || lineProbs.Len() < 4 {
+		return
+	}
+	edges, err := model.RoadEdges()
+	if err != nil || edges.Len() < 2 {
+		return
+	}
+	edgeStds, err := model.RoadEdgeStds()
+	if err != nil || edgeStds.Len() < 2 {
+		return
+	}
+
+	leftLine, lOk := nearestY(lines.At(1))
+	rightLine, rOk := nearestY(lines.At(2))
+	leftEdge, leOk := nearestY(edges.At(0))
+	rightEdge, reOk := nearestY(edges.At(1))
+	if !lOk || !rOk || !leOk || !reOk {
+		return
+	}
+
+	// y is positive to the right of the car
+	lineProb := float64(min(lineProbs.At(1), lineProbs.At(2)))
+	laneWidth := float64(ms.Settings.DefaultLaneWidth)
+	measured := float64(rightLine - leftLine)
+	if lineProb > ms.LANE_MIN_LINE_PROB && measured > ms.LANE_MIN_WIDTH && measured < ms.LANE_MAX_WIDTH {
+		laneWidth = measured
+	}
+	if laneWidth <= 0 {
+		return
+	}
+
+	edgeStd := float64(max(edgeStds.At(0), edgeStds.At(1)))
+	l.vision = visionLanes{
+		lanesLeft:  max(int(math.Round(float64(leftLine-leftEdge)/laneWidth)), 0),
+		lanesRight: max(int(math.Round(float64(rightEdge-rightLine)/laneWidth)), 0),
+		confidence: math.Max(math.Min(1-edgeStd/laneWidth, 1), 0) * lineProb,
+		updated:    now,
+	}
+}
+
+// Update estimates the current lane from the position on the current way.
+func (l *LaneEstimator) Update(currentWay CurrentWay, loc m.Location, now time.Time) {
+	way := currentWay.Way
+	isForward := currentWay.OnWay.IsForward
+	n := way.DirectionLanes(isForward)
+	if n == 0 || len(way.Nodes()) < 2 {
+		l.Reset()
+		return
+	}
+
+	laneWidth := float64(ms.Settings.DefaultLaneWidth)
+	total := max(way.Lanes(), n)
+	// left edge of the lanes in our direction relative to the center line,
+	// the lanes of the other direction are on the side we don't drive on
+	leftEdge := -float64(total) * laneWidth / 2
+	if !ms.Settings.DriveOnLeft {
+		leftEdge += float64(total-n) * laneWidth
+	}
+	offset := float64(currentWay.Distance.LateralOffset(loc.Pos, isForward))
+	sigma := math.Max(float64(loc.HorizontalAccuracy), ms.LANE_MIN_POSITION_STD)
+
+	probs := make([]float64, n)
+	for i := range n {
+		center := leftEdge + (float64(i)+0.5)*laneWidth
+		probs[i] = math.Exp(-0.5 * math.Pow((offset-center)/sigma, 2))
+	}
+
+	if now.Sub(l.vision.updated) < ms.LANE_VISION_MAX_AGE {
+		// only the road edge on the side we drive on is past lanes in our
+		// direction, unless the road is one way
+		if !ms.Settings.DriveOnLeft || way.OneWay() {
+			applyVisionLane(probs, n-1-l.vision.lanesRight, l.vision.confidence)
+		}
+		if ms.Settings.DriveOnLeft || way.OneWay() {
+			applyVisionLane(probs, l.vision.lanesLeft, l.vision.confidence)
+		}
+	}
+	normalize(probs)
+
+	if l.wayId == way.ID() && len(l.probs) == n {
+		for i := range probs {
+			probs[i] = ms.LANE_SMOOTHING*l.probs[i] + (1-ms.LANE_SMOOTHING)*probs[i]
+		}
+	}
+	l.probs = probs
+	l.wayId = way.ID()
+
+	best := 0
+	for i, p := range probs {
+		if p > probs[best] {
+			best = i
+		}
+	}
+	l.LaneFromLeft = uint8(best + 1)
+	l.LaneFromRight = uint8(n - best)
+	l.Confidence = float32(probs[best])
+}
+
+// applyVisionLane weights the lane probabilities by a vision estimate of the
+// lane index from the left.
+func applyVisionLane(probs []float64, idx int, confidence float64) {
+	if len(probs) < 2 {
+		return
+	}
+	idx = min(max(idx, 0), len(probs)-1)
+	other := (1 - confidence) / float64(len(probs)-1)
+	for i := range probs {
+		if i == idx {
+			probs[i] *= math.Max(confidence, other)
+		} else {
+			probs[i] *= other
+		}
+	}
+}
+
+func normalize(probs []float64) {
+	total := 0.0
+	for _, p := range probs {
+		total += p
+	}
+	if total <= 0 {
+		for i := range probs {
+			probs[i] = 1 / float64(len(probs))
+		}
+		return
+	}
+	for i := range probs {
+		probs[i] /= total
+	}
+}
+
+// nearestY returns the lateral position of a model line closest to the car.
+func nearestY(data log.XYZTData) (float32, bool) {
+	y, err := data.Y()
+	if err != nil || y.Len() == 0 {
+		return 0, false
+	}
+	return y.At(0), true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+func TestLaneTrafficSide(t *testing.T) {
+	ms.Settings.Default()
+	t.Cleanup(ms.Settings.Default)
+
+	// two lanes each way, driving north
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 500), 100)...).ID(1).Lanes(4)
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	way := offline.Ways()[0]
+
+	cases := []struct {
+		name        string
+		driveOnLeft bool
+		x           float64 // meters east of the center line
+		wantLeft    uint8
+		wantRight   uint8
+	}{
+		{name: "right hand traffic, outer lane", x: 5.5, wantLeft: 2, wantRight: 1},
+		{name: "right hand traffic, inner lane", x: 1.9, wantLeft: 1, wantRight: 2},
+		{name: "left hand traffic, outer lane", driveOnLeft: true, x: -5.5, wantLeft: 1, wantRight: 2},
+		{name: "left hand traffic, inner lane", driveOnLeft: true, x: -1.9, wantLeft: 2, wantRight: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ms.Settings.DriveOnLeft = c.driveOnLeft
+			loc := b.Location(tb.Pt(c.x, 250), 0, 10)
+			d, err := way.DistanceFrom(loc.Pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			currentWay := CurrentWay{Way: way, Distance: d, OnWay: maps.OnWayResult{OnWay: true, Distance: d, IsForward: true}}
+
+			lane := LaneEstimator{}
+			lane.Update(currentWay, loc, time.Unix(1700000000, 0))
+			if lane.LaneFromLeft != c.wantLeft || lane.LaneFromRight != c.wantRight {
+				t.Errorf("got lane %d from the left and %d from the right, want %d and %d",
+					lane.LaneFromLeft, lane.LaneFromRight, c.wantLeft, c.wantRight)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/car"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
+	"pfeifer.dev/mapd/maps"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// LoopInputs is where an iteration of the mapd loop reads its messages from.
+// Each method returns the newest message since the last iteration.
+type LoopInputs interface {
+	Inputs() []custom.MapdIn
+	CarState() (car.CarState, bool)
+	LiveParameters() (log.LiveParametersData, bool)
+	Model() (log.ModelDataV2, bool)
+	Position() (cereal.PositionFix, bool)
+}
+
+// Loop runs the mapd logic one iteration at a time. The service and log
+// replays share it so a replay behaves the same as a drive.
+type Loop struct {
+	State    *State
+	Extended *ExtendedState
+	Tiles    *maps.TileLoader
+}
+
+// Send publishes the outputs. The service and replays call it at the output
+// rate.
+func (l *Loop) Send() {
+	defer l.Extended.Timings.Measure(PHASE_SEND, l.Extended.Timings.Start())
+	progress, success := ms.Settings.GetDownloadProgress()
+	if success {
+		l.Extended.DownloadProgress = progress
+	}
+
+	err := l.State.Send()
+	if err != nil {
+		slog.Error("Failed to send update", "error", err)
+	}
+	err = l.Extended.Send() // this send is internally rate limited to 1 hz
+	if err != nil {
+		slog.Error("Failed to send extended update", "error", err)
+	}
+}
+
+// Step handles the messages of one iteration in a fixed order, so replays and
+// tests that batch their messages always get the same results.
+func (l *Loop) Step(in LoopInputs) {
+	for _, input := range in.Inputs() {
+		l.HandleInput(input)
+	}
+
+	carData, carStateSuccess := in.CarState()
+	if carStateSuccess {
+		l.HandleCarState(carData)
+	}
+
+	paramsData, paramsSuccess := in.LiveParameters()
+	if paramsSuccess {
+		l.HandleLiveParameters(paramsData)
+	}
+
+	modelData, modelSuccess := in.Model()
+	if modelSuccess {
+		l.HandleModel(modelData)
+	}
+
+	fix, positionSuccess := in.Position()
+	if positionSuccess {
+		l.HandlePosition(fix)
+	}
+}
+
+// HandleInput handles a settings input from openpilot or the cli.
+func (l *Loop) HandleInput(input custom.MapdIn) {
+	defer l.Extended.Timings.Measure(PHASE_INPUTS, l.Extended.Timings.Start())
+	ms.Settings.Handle(input)
+	HandleWayOverrideInput(l.State, input)
+	if ms.Settings.TakeLearnedCurvesReset() {
+		l.State.CurveLearner.Reset()
+	}
+}
+
+func (l *Loop) HandleCarState(carData car.CarState) {
+	defer l.Extended.Timings.Measure(PHASE_CAR_STATE, l.Extended.Timings.Start())
+	l.State.UpdateCarState(carData)
+	UpdateCurveSpeed(l.State)
+}
+
+func (l *Loop) HandleLiveParameters(params log.LiveParametersData) {
+	defer l.Extended.Timings.Measure(PHASE_LIVE_PARAMETERS, l.Extended.Timings.Start())
+	l.State.UpdateLiveParameters(params)
+}
+
+func (l *Loop) HandleModel(model log.ModelDataV2) {
+	defer l.Extended.Timings.Measure(PHASE_MODEL, l.Extended.Timings.Start())
+	state := l.State
+	state.PathAgreement.Update(model, state)
+	state.VisionCurveSpeed = calcVisionCurveSpeed(model, state)
+	state.Lane.UpdateVision(model, state.Clock.Now())
+}
+
+// HandlePosition handles a fix picked by the position sources.
+func (l *Loop) HandlePosition(fix cereal.PositionFix) {
+	defer l.Extended.Timings.Measure(PHASE_POSITION, l.Extended.Timings.Start())
+	if l.State.GpsValidator.Check(&fix, l.State.Car.VEgo) {
+		l.updatePosition(fix)
+	}
+}
+
+func (l *Loop) updatePosition(fix cereal.PositionFix) {
+	state := l.State
+	loc := state.UpdatePosition(fix)
+	l.Tiles.Request(loc.Pos, loc.BearingDeg, float64(loc.Speed))
+	tiles, tilesReady := l.Tiles.Result()
+	if tilesReady {
+		state.Data = tiles.Data
+		if tiles.Err != nil {
+			slog.Debug("", "error", errors.Wrap(tiles.Err, "Could not find ways around location"))
+		}
+	}
+	// neighbouring tiles stay usable while the loader re-centers on a new tile
+	box := state.Data.OverlapBox()
+	if len(state.Data.Ways()) == 0 || !box.PosInside(state.Position) {
+		return
+	}
+
+	var err error
+	state.CurrentWay, err = MatchCurrentWay(state, loc)
+	if err != nil {
+		slog.Debug("could not get current way", "error", err)
+	}
+	state.Lane.Update(state.CurrentWay, loc, state.Clock.Now())
+
+	state.NextWays, err = NextWays(loc, state.CurrentWay, &state.Data, state.CurrentWay.OnWay.IsForward)
+	if err != nil {
+		slog.Debug("could not get next way", "error", err)
+	}
+
+	state.Curvatures, err = GetStateCurvatures(state)
+	if err != nil {
+		slog.Debug("could not get curvatures from current state", "error", err)
+	}
+	state.UpdateTargetVelocities()
+}
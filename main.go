@@ -2,123 +2,190 @@ package main
 
 import (
 	"log/slog"
+	"os"
+	"sync"
 	"time"
 
-	"github.com/pkg/errors"
 	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/car"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
 	"pfeifer.dev/mapd/cli"
 	"pfeifer.dev/mapd/maps"
-	m "pfeifer.dev/mapd/math"
 	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
 )
 
 func main() {
 	ms.Settings.Default() // set defaults so settings not already in param are defaulted
 	settingsLoaded := ms.Settings.Load() // try loading settings before cli
 
-	cli.Handle()
+	transportConfig := cli.Handle(Replay)
 
 	if !settingsLoaded {
 		ms.Settings.LoadWithRetries(5)
 	}
 
+	transport, err := transportConfig.Transport()
+	if err != nil {
+		slog.Error("could not set up transport", "error", err)
+		os.Exit(1)
+	}
+	err = run(transport, nil)
+	if err != nil {
+		slog.Error("mapd stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run is the mapd service. It reads and publishes its messages on transport
+// and runs until done is closed, or forever if done is nil.
+func run(transport cereal.Transport, done <-chan struct{}) error {
 	state := State{}
 	state.Init()
 
+	extendedPub, err := cereal.NewPublisher(transport, "mapdExtendedOut", cereal.MapdExtendedOutCreator)
+	if err != nil {
+		return err
+	}
+	defer extendedPub.Close()
 	extendedState := ExtendedState{
-		Pub:   cereal.NewPublisher("mapdExtendedOut", cereal.MapdExtendedOutCreator),
+		Pub:   extendedPub,
 		state: &state,
 	}
-	defer extendedState.Pub.Pub.Msgq.Close()
 
-	pub := cereal.NewPublisher("mapdOut", cereal.MapdOutCreator)
-	defer pub.Pub.Msgq.Close()
+	pub, err := cereal.NewPublisher(transport, "mapdOut", cereal.MapdOutCreator)
+	if err != nil {
+		return err
+	}
+	defer pub.Close()
 	state.Publisher = &pub
 
-	sub := cereal.NewSubscriber("mapdIn", cereal.MapdInReader, false, false)
-	defer sub.Sub.Msgq.Close()
-
-	cli := cereal.NewSubscriber("mapdCli", cereal.MapdInReader, false, false)
-	defer cli.Sub.Msgq.Close()
+	inputs, err := newLiveInputs(transport, state.Clock)
+	if err != nil {
+		return err
+	}
+	defer inputs.Close()
 
-	gps := cereal.GetGpsSub()
-	defer gps.Close()
+	tileLoader := maps.NewTileLoader(maps.NewTileManager(maps.DEFAULT_SETTINGS, ms.TILE_CACHE_SIZE))
+	tileLoader.Start()
+	maps.WayOverrides.Load()
 
-	// shadow carState as stock openpilot uses nearly every subscriber slot
-	car := cereal.NewSubscriber("carState", cereal.CarStateReader, true, true)
-	defer car.Sub.Msgq.Close()
-
-	model := cereal.NewSubscriber("modelV2", cereal.ModelV2Reader, true, false)
-	defer model.Sub.Msgq.Close()
+	webServer := NewWebServer(&state)
+	defer webServer.Close()
+	state.Web = webServer
 
+	loop := Loop{State: &state, Extended: &extendedState, Tiles: tileLoader}
+	events := inputs.Listen()
+	interval := ms.Settings.OutputInterval()
+	output := time.NewTicker(interval)
+	defer output.Stop()
 	for {
-		err := state.Send() // send beginning of each loop to ensure it happens at the correct rate
-		if err != nil {
-			slog.Error("Failed to send update", "error", err)
-		}
-		err = extendedState.Send() // this send is internally rate limited to 1 hz
-		if err != nil {
-			slog.Error("Failed to send extended update", "error", err)
-		}
-		time.Sleep(ms.LOOP_DELAY)
-
-		// handle settings inputs from openpilot/cli
-		input, inputSuccess := sub.Read()
-		if inputSuccess {
-			ms.Settings.Handle(input)
-		}
-		cliInput, cliSuccess := cli.Read()
-		if cliSuccess {
-			ms.Settings.Handle(cliInput)
-		}
-
-		progress, success := ms.Settings.GetDownloadProgress()
-		if success {
-			extendedState.DownloadProgress = progress
-		}
-
-		carData, carStateSuccess := car.Read()
-		if carStateSuccess {
-			state.UpdateCarState(carData)
-			UpdateCurveSpeed(&state)
-		}
-
-		modelData, modelSuccess := model.Read()
-		if modelSuccess {
-			state.VisionCurveSpeed = calcVisionCurveSpeed(modelData, &state)
-		}
-
-		location, gpsSuccess := gps.Read()
-		if gpsSuccess {
-			state.DistanceSinceLastPosition = 0
-			state.Position = m.PosFromLocation(location)
-			box := state.Data.Box()
-			pos := m.PosFromLocation(location)
-			if len(state.Data.Ways()) == 0 || !box.PosInside(pos) {
-				state.Data, err = maps.FindWaysAroundPosition(pos)
-				if err != nil {
-					slog.Debug("", "error", errors.Wrap(err, "Could not find ways around location"))
-					continue
-				}
+		// inputs are handled as soon as they arrive, the outputs go out at a
+		// steady rate
+		select {
+		case <-done:
+			return nil
+		case input := <-events.inputs:
+			loop.HandleInput(input)
+		case input := <-events.cli:
+			loop.HandleInput(input)
+		case carData := <-events.car:
+			loop.HandleCarState(carData)
+		case params := <-events.liveParams:
+			loop.HandleLiveParameters(params)
+		case model := <-events.model:
+			loop.HandleModel(model)
+		case fix := <-events.positions:
+			fix, success := inputs.positions.Push(fix)
+			if success {
+				loop.HandlePosition(fix)
 			}
-
-			state.CurrentWay, err = GetCurrentWay(state.CurrentWay, state.NextWays, &state.Data, location)
-			if err != nil {
-				slog.Debug("could not get current way", "error", err)
+		case <-output.C:
+			loop.Send()
+			start := extendedState.Timings.Start()
+			webServer.Update(ms.Settings.WebServerEnabled, ms.Settings.WebServerAddress, int(ms.Settings.WebServerPort))
+			webServer.Process()
+			extendedState.Timings.Measure(PHASE_WEB, start)
+			if next := ms.Settings.OutputInterval(); next != interval {
+				interval = next
+				output.Reset(interval)
 			}
+		}
+	}
+}
 
-			state.NextWays, err = NextWays(location, state.CurrentWay, &state.Data, state.CurrentWay.OnWay.IsForward)
-			if err != nil {
-				slog.Debug("could not get next way", "error", err)
-			}
+// liveInputs reads the loop inputs from the openpilot cereal services.
+type liveInputs struct {
+	sub        cereal.Subscriber[custom.MapdIn]
+	cli        cereal.Subscriber[custom.MapdIn]
+	positions  cereal.PositionSources
+	car        cereal.Subscriber[car.CarState]
+	model      cereal.Subscriber[log.ModelDataV2]
+	liveParams cereal.Subscriber[log.LiveParametersData]
+	done       chan struct{}
+	listeners  sync.WaitGroup
+}
 
-			state.Curvatures, err = GetStateCurvatures(&state)
-			if err != nil {
-				slog.Debug("could not get curvatures from current state", "error", err)
-			}
-			state.TargetVelocities = GetTargetVelocities(state.Curvatures, state.TargetVelocities)
-		}
+// liveEvents are the messages of the live inputs as they arrive.
+type liveEvents struct {
+	inputs     <-chan custom.MapdIn
+	cli        <-chan custom.MapdIn
+	car        <-chan car.CarState
+	liveParams <-chan log.LiveParametersData
+	model      <-chan log.ModelDataV2
+	positions  <-chan cereal.PositionFix
+}
 
-		// send at beginning of next loop
+func newLiveInputs(transport cereal.Transport, clock utils.Clock) (inputs *liveInputs, err error) {
+	inputs = &liveInputs{done: make(chan struct{})}
+	inputs.sub, err = cereal.NewSubscriber(transport, "mapdIn", cereal.MapdInReader, false, false)
+	if err != nil {
+		return inputs, err
 	}
+	inputs.cli, err = cereal.NewSubscriber(transport, "mapdCli", cereal.MapdInReader, false, false)
+	if err != nil {
+		return inputs, err
+	}
+	inputs.positions, err = cereal.GetPositionSources(transport, clock)
+	if err != nil {
+		return inputs, err
+	}
+	// shadow carState as stock openpilot uses nearly every subscriber slot
+	inputs.car, err = cereal.NewSubscriber(transport, "carState", cereal.CarStateReader, true, true)
+	if err != nil {
+		return inputs, err
+	}
+	inputs.model, err = cereal.NewSubscriber(transport, "modelV2", cereal.ModelV2Reader, true, false)
+	if err != nil {
+		return inputs, err
+	}
+	inputs.liveParams, err = cereal.NewSubscriber(transport, "liveParameters", cereal.LiveParametersReader, true, false)
+	return inputs, err
+}
+
+// Listen starts reading every input in its own goroutine until the inputs are
+// closed.
+func (i *liveInputs) Listen() liveEvents {
+	return liveEvents{
+		inputs:     i.sub.Listen(i.done, &i.listeners),
+		cli:        i.cli.Listen(i.done, &i.listeners),
+		car:        i.car.Listen(i.done, &i.listeners),
+		liveParams: i.liveParams.Listen(i.done, &i.listeners),
+		model:      i.model.Listen(i.done, &i.listeners),
+		positions:  i.positions.Listen(i.done, &i.listeners),
+	}
+}
+
+// Close stops the listeners before closing the subscribers so no goroutine
+// reads from a closed queue.
+func (i *liveInputs) Close() {
+	close(i.done)
+	i.listeners.Wait()
+	i.sub.Close()
+	i.cli.Close()
+	i.positions.Close()
+	i.car.Close()
+	i.model.Close()
+	i.liveParams.Close()
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/car"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	"pfeifer.dev/mapd/overrides"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// TestRun starts the service on a channel transport and checks that the
+// inputs it is sent end up in mapdOut.
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	ms.Settings.Default()
+	ms.Settings.ResetSpeedLimitAccepted()
+	overridesPath, learnedCurvesPath := overrides.OVERRIDES_PATH, LEARNED_CURVES_PATH
+	wayOverridesPath, offlineSettings := maps.WAY_OVERRIDES_PATH, maps.DEFAULT_SETTINGS
+	overrides.OVERRIDES_PATH = filepath.Join(dir, "overrides.jsonl")
+	LEARNED_CURVES_PATH = filepath.Join(dir, "learned_curves.json")
+	maps.WAY_OVERRIDES_PATH = filepath.Join(dir, "way_overrides.json")
+	maps.DEFAULT_SETTINGS = maps.OfflineSettings{OutputDirectory: filepath.Join(dir, "offline")}
+	t.Cleanup(func() {
+		overrides.OVERRIDES_PATH, LEARNED_CURVES_PATH = overridesPath, learnedCurvesPath
+		maps.WAY_OVERRIDES_PATH, maps.DEFAULT_SETTINGS = wayOverridesPath, offlineSettings
+		ms.Settings.Default()
+		ms.Settings.ResetSpeedLimitAccepted()
+	})
+
+	road := scenarioRoad()
+	err := road.Write(maps.DEFAULT_SETTINGS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := cereal.NewChannelTransport()
+	out, err := cereal.NewSubscriber(transport, "mapdOut", cereal.MapdOutReader, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	carPub, err := cereal.NewPublisher(transport, "carState", func(e log.Event) (car.CarState, error) { return e.NewCarState() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpsPub, err := cereal.NewPublisher(transport, cereal.SOURCE_GPS, func(e log.Event) (log.GpsLocationData, error) { return e.NewGpsLocation() })
+	if err != nil {
+		t.Fatal(err)
+	}
+	inPub, err := cereal.NewPublisher(transport, "mapdIn", cereal.MapdInCreator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan error)
+	go func() {
+		stopped <- run(transport, done)
+	}()
+	defer func() {
+		close(done)
+		err := <-stopped
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	pos := road.Position(tb.Pt(0, 100))
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		// run subscribes after it starts, so keep sending until the inputs
+		// show up in the outputs
+		msg, carState := carPub.NewMessage(true)
+		carState.SetVEgo(10)
+		carState.SetVCruise(80)
+		err = carPub.Send(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg, gps := gpsPub.NewMessage(true)
+		gps.SetHasFix(true)
+		gps.SetLatitude(pos.Lat())
+		gps.SetLongitude(pos.Lon())
+		gps.SetHorizontalAccuracy(1)
+		gps.SetSpeed(10)
+		gps.SetSpeedAccuracy(0.1)
+		err = gpsPub.Send(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg, input := inPub.NewMessage(true)
+		input.SetType(custom.MapdInputType_setCurveSpeedMode)
+		err = input.SetStr(ms.CURVE_MODE_FUSION)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = inPub.Send(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(ms.LOOP_DELAY)
+		output, success := out.Read()
+		if success && runOutputDone(t, output) {
+			return
+		}
+	}
+	t.Fatal("mapdOut never showed the inputs")
+}
+
+// runOutputDone checks that the gps fix matched the road and the mapdIn input
+// switched on the fused curve speed.
+func runOutputDone(t *testing.T, output custom.MapdOut) bool {
+	t.Helper()
+	wayName, err := output.WayName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := output.PositionSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiters, err := output.SpeedLimiters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fusionEnabled := false
+	for i := range limiters.Len() {
+		name, err := limiters.At(i).Name()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == LIMITER_CURVE_FUSION {
+			fusionEnabled = limiters.At(i).Enabled()
+		}
+	}
+	return wayName == "First Street" && source == cereal.SOURCE_GPS && output.GpsAccepted() > 0 && fusionEnabled
+}
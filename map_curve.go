@@ -5,6 +5,13 @@ import (
 	ms "pfeifer.dev/mapd/settings"
 )
 
+// UpdateTargetVelocities calculates the target velocities for the curvatures
+// of the current path.
+func (s *State) UpdateTargetVelocities() {
+	s.TargetVelocities = GetTargetVelocities(s.Curvatures, s.TargetVelocities, s.CurveLearner.PathCurves(s))
+	applyCurveSpeedOverrides(s)
+}
+
 func UpdateCurveSpeed(s *State) {
 	distances := make([]float32, len(s.TargetVelocities))
 	match_idx := -1
@@ -27,10 +34,10 @@ func UpdateCurveSpeed(s *State) {
 		distances[i] = d
 
 		// find index of the most recent node we have driven past based on which node was used to calculate if we are on the way
-		if tv.Pos.Equals(s.CurrentWay.Distance.LineStart) && match_idx == -1 {
+		if tv.Pos.SameNode(s.CurrentWay.Distance.LineStart) && match_idx == -1 {
 			match_idx = i + 1
 		}
-		if tv.Pos.Equals(s.CurrentWay.Distance.LineEnd) && match_idx == -1 {
+		if tv.Pos.SameNode(s.CurrentWay.Distance.LineEnd) && match_idx == -1 {
 			match_idx = i + 1
 		}
 	}
@@ -50,6 +57,7 @@ func UpdateCurveSpeed(s *State) {
 	}
 
 	minValidV := float32(1000)
+	minValidD := float32(0)
 	for i, d := range forwardDistances {
 		tv := forwardPoints[i]
 		if tv.Velocity > float64(s.Car.VEgo)+ms.CURVE_CALC_OFFSET {
@@ -68,12 +76,15 @@ func UpdateCurveSpeed(s *State) {
 			}
 			if float32(tv.Velocity) < minValidV {
 				minValidV = float32(tv.Velocity)
+				minValidD = float32(d)
 			}
 		}
 	}
 	if minValidV == float32(1000) {
 		s.MapCurveSpeed = 0
+		s.MapCurveDistance = 0
 	} else {
 		s.MapCurveSpeed = minValidV
+		s.MapCurveDistance = minValidD
 	}
 }
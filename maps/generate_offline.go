@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -17,12 +18,12 @@ import (
 	m "pfeifer.dev/mapd/math"
 	"pfeifer.dev/mapd/params"
 	ms "pfeifer.dev/mapd/settings"
-	"pfeifer.dev/mapd/utils"
 )
 
 type TmpNode struct {
 	Latitude  float64
 	Longitude float64
+	ID        int64
 }
 type TmpWay struct {
 	Name             string
@@ -33,6 +34,10 @@ type TmpWay struct {
 	MaxSpeedBackward float64
 	MaxSpeedAdvisory float64
 	Lanes            uint8
+	LanesForward     uint8
+	LanesBackward    uint8
+	Highway          offline.HighwayType
+	ID               int64
 	Box              m.Box
 	OneWay           bool
 	Nodes            []TmpNode
@@ -135,6 +140,8 @@ func GenerateOffline(s OfflineSettings) {
 		if way != nil && len(way.Nodes) > 1 {
 			tags := way.TagMap()
 			lanes, _ := strconv.ParseUint(tags["lanes"], 10, 8)
+			lanesForward, _ := strconv.ParseUint(tags["lanes:forward"], 10, 8)
+			lanesBackward, _ := strconv.ParseUint(tags["lanes:backward"], 10, 8)
 			tmpWay := TmpWay{
 				Nodes:            make([]TmpNode, len(way.Nodes)),
 				Name:             tags["name"],
@@ -145,6 +152,10 @@ func GenerateOffline(s OfflineSettings) {
 				MaxSpeedBackward: ParseMaxSpeed(tags["maxspeed:backward"]),
 				MaxSpeedAdvisory: ParseMaxSpeed(tags["maxspeed:advisory"]),
 				Lanes:            uint8(lanes),
+				LanesForward:     uint8(lanesForward),
+				LanesBackward:    uint8(lanesBackward),
+				Highway:          ParseHighwayType(tags["highway"]),
+				ID:               int64(way.ID),
 				OneWay:           tags["oneway"] == "yes",
 			}
 			index++
@@ -168,6 +179,7 @@ func GenerateOffline(s OfflineSettings) {
 				}
 				tmpWay.Nodes[i].Latitude = n.Lat
 				tmpWay.Nodes[i].Longitude = n.Lon
+				tmpWay.Nodes[i].ID = int64(n.ID)
 			}
 			tmpWay.Box.MinPos = m.NewPosition(minLat, minLon)
 			tmpWay.Box.MaxPos = m.NewPosition(maxLat, maxLon)
@@ -199,18 +211,6 @@ func GenerateOffline(s OfflineSettings) {
 			continue
 		}
 
-		arena := capnp.MultiSegment([][]byte{})
-		msg, seg, err := capnp.NewMessage(arena)
-		if err != nil {
-			slog.Error("could not create capnp arena for offline data", "error", err)
-			panic("unexpected capnp error, exiting")
-		}
-		rootOffline, err := offline.NewRootOffline(seg)
-		if err != nil {
-			slog.Error("could not create capnp root for offline data", "error", err)
-			panic("unexpected capnp error, exiting")
-		}
-
 		for _, way := range scannedWays {
 
 			overlaps := way.Box.Overlapping(area.OverlapBox(s.Overlap))
@@ -220,69 +220,10 @@ func GenerateOffline(s OfflineSettings) {
 		}
 
 		slog.Info("Writing Area")
-		ways, err := rootOffline.NewWays(int32(len(area.Ways)))
+		err := WriteArea(area, s)
 		if err != nil {
-			slog.Error("could not create ways in offline data", "error", err)
-			panic("unexpected capnp error, exiting")
-		}
-		rootOffline.SetMinLat(area.Box.MinPos.Lat())
-		rootOffline.SetMinLon(area.Box.MinPos.Lon())
-		rootOffline.SetMaxLat(area.Box.MaxPos.Lat())
-		rootOffline.SetMaxLon(area.Box.MaxPos.Lon())
-		rootOffline.SetOverlap(s.Overlap)
-		for i, way := range area.Ways {
-			w := ways.At(i)
-			w.SetMinLat(way.Box.MinPos.Lat())
-			w.SetMinLon(way.Box.MinPos.Lon())
-			w.SetMaxLat(way.Box.MaxPos.Lat())
-			w.SetMaxLon(way.Box.MaxPos.Lon())
-			err := w.SetName(way.Name)
-			if err != nil {
-				slog.Error("could not set way name", "error", err)
-				panic("unexpected capnp error, exiting")
-			}
-			err = w.SetRef(way.Ref)
-			if err != nil {
-				slog.Error("could not set way ref", "error", err)
-				panic("unexpected capnp error, exiting")
-			}
-			err = w.SetHazard(way.Hazard)
-			if err != nil {
-				slog.Error("could not set way hazard", "error", err)
-				panic("unexpected capnp error, exiting")
-			}
-			w.SetMaxSpeed(way.MaxSpeed)
-			w.SetMaxSpeedForward(way.MaxSpeedForward)
-			w.SetMaxSpeedBackward(way.MaxSpeedBackward)
-			w.SetAdvisorySpeed(way.MaxSpeedAdvisory)
-			w.SetLanes(way.Lanes)
-			w.SetOneWay(way.OneWay)
-			nodes, err := w.NewNodes(int32(len(way.Nodes)))
-			if err != nil {
-				slog.Error("could not create way nodes", "error", err)
-				panic("unexpected capnp error, exiting")
-			}
-			for j, node := range way.Nodes {
-				n := nodes.At(j)
-				n.SetLatitude(node.Latitude)
-				n.SetLongitude(node.Longitude)
-			}
-		}
-
-		data, err := msg.MarshalPacked()
-		if err != nil {
-			slog.Error("could not marshal offline data", "error", err)
-			panic("unexpected capnp error, exiting")
-		}
-		err = CreateBoundsDir(area, s)
-		if err != nil {
-			slog.Error("could not create bounds directory", "error", err)
-			panic("unexpected file error, exiting")
-		}
-		err = os.WriteFile(GenerateBoundsFileName(area, s), data, 0o644)
-		if err != nil {
-			slog.Error("could not write offline data to file", "error", err)
-			panic("unexpected file error, exiting")
+			slog.Error("could not write area", "error", err)
+			panic("unexpected error, exiting")
 		}
 	}
 	f, err := os.Open(s.OutputDirectory)
@@ -304,42 +245,118 @@ func GenerateOffline(s OfflineSettings) {
 	slog.Info("Done Generating Offline Map")
 }
 
-var AREAS = generateAreas()
+// MarshalArea encodes the ways of an area in the offline data file format.
+func MarshalArea(area Area, overlap float64) ([]byte, error) {
+	arena := capnp.MultiSegment(nil)
+	msg, seg, err := capnp.NewMessage(arena)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create capnp arena for offline data")
+	}
+	rootOffline, err := offline.NewRootOffline(seg)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create capnp root for offline data")
+	}
 
-func FindWaysAroundPosition(pos m.Position) (Offline, error) {
-	for _, area := range AREAS {
-		inBox := area.Box.PosInside(pos)
-		if inBox {
-			boundsName := GenerateBoundsFileName(area, DEFAULT_SETTINGS)
-			slog.Info("Loading bounds file", "filename", boundsName)
-			data, err := os.ReadFile(boundsName)
-			o := ReadOffline(data)
-			if !o.Loaded {
-				area := Area{}
-				areas := generateAreas()
-				for _, a := range areas {
-					if a.Box.PosInside(pos) {
-						area = a
-					}
-				}
-				o.box.Set(area.Box)
-			}
-			return o, errors.Wrap(err, "could not read current offline data file")
-		}
+	ways, err := rootOffline.NewWays(int32(len(area.Ways)))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create ways in offline data")
 	}
-	area := Area{}
-	areas := generateAreas()
-	for _, a := range areas {
-		if a.Box.PosInside(pos) {
-			area = a
+	rootOffline.SetMinLat(area.Box.MinPos.Lat())
+	rootOffline.SetMinLon(area.Box.MinPos.Lon())
+	rootOffline.SetMaxLat(area.Box.MaxPos.Lat())
+	rootOffline.SetMaxLon(area.Box.MaxPos.Lon())
+	rootOffline.SetOverlap(overlap)
+	for i, way := range area.Ways {
+		w := ways.At(i)
+		w.SetMinLat(way.Box.MinPos.Lat())
+		w.SetMinLon(way.Box.MinPos.Lon())
+		w.SetMaxLat(way.Box.MaxPos.Lat())
+		w.SetMaxLon(way.Box.MaxPos.Lon())
+		err := w.SetName(way.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not set way name")
 		}
+		err = w.SetRef(way.Ref)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not set way ref")
+		}
+		err = w.SetHazard(way.Hazard)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not set way hazard")
+		}
+		w.SetMaxSpeed(way.MaxSpeed)
+		w.SetMaxSpeedForward(way.MaxSpeedForward)
+		w.SetMaxSpeedBackward(way.MaxSpeedBackward)
+		w.SetAdvisorySpeed(way.MaxSpeedAdvisory)
+		w.SetLanes(way.Lanes)
+		w.SetHighway(way.Highway)
+		w.SetId(way.ID)
+		w.SetLanesForward(way.LanesForward)
+		w.SetLanesBackward(way.LanesBackward)
+		w.SetOneWay(way.OneWay)
+		nodes, err := w.NewNodes(int32(len(way.Nodes)))
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create way nodes")
+		}
+		for j, node := range way.Nodes {
+			n := nodes.At(j)
+			n.SetLatitude(node.Latitude)
+			n.SetLongitude(node.Longitude)
+			n.SetNodeId(node.ID)
+		}
+	}
+
+	data, err := msg.MarshalPacked()
+	return data, errors.Wrap(err, "could not marshal offline data")
+}
+
+// WriteArea writes the offline data file for an area.
+func WriteArea(area Area, s OfflineSettings) error {
+	data, err := MarshalArea(area, s.Overlap)
+	if err != nil {
+		return err
+	}
+	err = CreateBoundsDir(area, s)
+	if err != nil {
+		return err
 	}
-	cBox := utils.Curry[m.Box]{}
-	cBox.Set(area.Box)
-	return Offline{
-		Loaded: false,
-		box:    cBox,
-	}, nil
+	err = os.WriteFile(GenerateBoundsFileName(area, s), data, 0o644)
+	return errors.Wrap(err, "could not write offline data to file")
+}
+
+// AreaAround returns the area that the position falls in without scanning
+// every generated area.
+func AreaAround(pos m.Position) Area {
+	lat := math.Floor(pos.Lat()/ms.AREA_BOX_DEGREES) * ms.AREA_BOX_DEGREES
+	lon := math.Floor(pos.Lon()/ms.AREA_BOX_DEGREES) * ms.AREA_BOX_DEGREES
+	return Area{
+		Box: m.Box{
+			MinPos: m.NewPosition(lat, lon),
+			MaxPos: m.NewPosition(lat+ms.AREA_BOX_DEGREES, lon+ms.AREA_BOX_DEGREES),
+		},
+	}
+}
+
+func FindWaysAroundPosition(pos m.Position) (Offline, error) {
+	return ReadArea(AreaAround(pos), DEFAULT_SETTINGS)
+}
+
+// ReadArea loads the offline data file for an area. If the file could not be
+// read the returned Offline is not loaded but still reports the area box.
+func ReadArea(area Area, s OfflineSettings) (Offline, error) {
+	boundsName := GenerateBoundsFileName(area, s)
+	slog.Info("Loading bounds file", "filename", boundsName)
+	data, err := os.ReadFile(boundsName)
+	if err != nil {
+		o := Offline{Loaded: false}
+		o.box.Set(area.Box)
+		return o, errors.Wrap(err, "could not read current offline data file")
+	}
+	o := ReadOffline(data)
+	if !o.Loaded {
+		o.box.Set(area.Box)
+	}
+	return o, nil
 }
 
 func ParseMaxSpeed(maxspeed string) float64 {
@@ -367,3 +384,41 @@ func ParseMaxSpeed(maxspeed string) float64 {
 
 	return 0
 }
+
+func ParseHighwayType(highway string) offline.HighwayType {
+	switch highway {
+	case "":
+		return offline.HighwayType_unknown
+	case "motorway":
+		return offline.HighwayType_motorway
+	case "motorway_link":
+		return offline.HighwayType_motorwayLink
+	case "trunk":
+		return offline.HighwayType_trunk
+	case "trunk_link":
+		return offline.HighwayType_trunkLink
+	case "primary":
+		return offline.HighwayType_primary
+	case "primary_link":
+		return offline.HighwayType_primaryLink
+	case "secondary":
+		return offline.HighwayType_secondary
+	case "secondary_link":
+		return offline.HighwayType_secondaryLink
+	case "tertiary":
+		return offline.HighwayType_tertiary
+	case "tertiary_link":
+		return offline.HighwayType_tertiaryLink
+	case "unclassified":
+		return offline.HighwayType_unclassified
+	case "residential":
+		return offline.HighwayType_residential
+	case "living_street":
+		return offline.HighwayType_livingStreet
+	case "service":
+		return offline.HighwayType_service
+	case "road":
+		return offline.HighwayType_road
+	}
+	return offline.HighwayType_other
+}
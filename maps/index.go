@@ -0,0 +1,140 @@
+package maps
+
+import (
+	"math"
+
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+type gridCell struct {
+	lat int
+	lon int
+}
+
+type coordKey struct {
+	lat float64
+	lon float64
+}
+
+// wayIndex holds lookup tables built once per loaded tile. All slices hold
+// indexes into Offline.Ways().
+type wayIndex struct {
+	grid        map[gridCell][]int
+	nodes       map[int64][]int
+	coordinates map[coordKey][]int
+}
+
+func cellFor(lat, lon float64) gridCell {
+	return gridCell{
+		lat: int(math.Floor(lat / ms.WAY_INDEX_CELL_DEGREES)),
+		lon: int(math.Floor(lon / ms.WAY_INDEX_CELL_DEGREES)),
+	}
+}
+
+func (o *Offline) _index() wayIndex {
+	idx := wayIndex{
+		grid:        map[gridCell][]int{},
+		nodes:       map[int64][]int{},
+		coordinates: map[coordKey][]int{},
+	}
+	ways := o.Ways()
+	for i := range ways {
+		way := &ways[i]
+		nodes := way.Nodes()
+		if len(nodes) == 0 {
+			continue
+		}
+
+		box := way.Box()
+		minCell := cellFor(box.MinPos.Lat(), box.MinPos.Lon())
+		maxCell := cellFor(box.MaxPos.Lat(), box.MaxPos.Lon())
+		for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+			for lon := minCell.lon; lon <= maxCell.lon; lon++ {
+				cell := gridCell{lat: lat, lon: lon}
+				idx.grid[cell] = append(idx.grid[cell], i)
+			}
+		}
+
+		ends := []m.Position{nodes[0]}
+		if len(nodes) > 1 {
+			ends = append(ends, nodes[len(nodes)-1])
+		}
+		for _, end := range ends {
+			if end.NodeID() != 0 {
+				idx.nodes[end.NodeID()] = append(idx.nodes[end.NodeID()], i)
+			}
+			key := coordKey{lat: end.Lat(), lon: end.Lon()}
+			idx.coordinates[key] = append(idx.coordinates[key], i)
+		}
+	}
+	return idx
+}
+
+func (o *Offline) wayIndex() wayIndex {
+	return o.index.Value(o._index)
+}
+
+func (o *Offline) waysFromIndexes(indexes []int) []Way {
+	ways := o.Ways()
+	res := make([]Way, 0, len(indexes))
+	seen := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		res = append(res, ways[i])
+	}
+	return res
+}
+
+// WaysNear returns ways whose bounding box is within radius meters of pos.
+// The result is a superset of the ways actually within radius, so callers
+// still need to check the distance to the way itself.
+func (o *Offline) WaysNear(pos m.Position, radius float32) []Way {
+	idx := o.wayIndex()
+	latDelta := float64(radius) / ms.R * ms.TO_DEGREES
+	lonDelta := latDelta / max(math.Cos(pos.LatRad()), 0.01)
+	query := m.Box{
+		MinPos: m.NewPosition(pos.Lat()-latDelta, pos.Lon()-lonDelta),
+		MaxPos: m.NewPosition(pos.Lat()+latDelta, pos.Lon()+lonDelta),
+	}
+
+	ways := o.Ways()
+	indexes := []int{}
+	minCell := cellFor(query.MinPos.Lat(), query.MinPos.Lon())
+	maxCell := cellFor(query.MaxPos.Lat(), query.MaxPos.Lon())
+	for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+		for lon := minCell.lon; lon <= maxCell.lon; lon++ {
+			for _, i := range idx.grid[gridCell{lat: lat, lon: lon}] {
+				box := ways[i].Box()
+				if boxesIntersect(box, query) {
+					indexes = append(indexes, i)
+				}
+			}
+		}
+	}
+	return o.waysFromIndexes(indexes)
+}
+
+// WaysAtNode returns the ways that start or end at the OSM node with the
+// given id.
+func (o *Offline) WaysAtNode(id int64) []Way {
+	return o.waysFromIndexes(o.wayIndex().nodes[id])
+}
+
+// WaysAtEndpoint returns the ways that start or end at the given node. The
+// node id is used when present, otherwise ways are matched by coordinates.
+func (o *Offline) WaysAtEndpoint(node m.Position) []Way {
+	if node.NodeID() != 0 {
+		return o.WaysAtNode(node.NodeID())
+	}
+	key := coordKey{lat: node.Lat(), lon: node.Lon()}
+	return o.waysFromIndexes(o.wayIndex().coordinates[key])
+}
+
+func boxesIntersect(a, b m.Box) bool {
+	return a.MinPos.Lat() <= b.MaxPos.Lat() && a.MaxPos.Lat() >= b.MinPos.Lat() &&
+		a.MinPos.Lon() <= b.MaxPos.Lon() && a.MaxPos.Lon() >= b.MinPos.Lon()
+}
@@ -0,0 +1,146 @@
+package maps
+
+import (
+	"log/slog"
+	"time"
+
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+	u "pfeifer.dev/mapd/utils"
+)
+
+type loadRequest struct {
+	pos     m.Position
+	bearing float64
+	speed   float64
+}
+
+// TileResult is a merged map produced by the TileLoader.
+type TileResult struct {
+	Data     Offline
+	Err      error
+	Duration time.Duration
+}
+
+// TileLoader reads and decodes tiles on a separate goroutine so tile changes
+// don't stall the main loop. It also prefetches the tile we are heading
+// towards so it is already cached when we cross into it.
+type TileLoader struct {
+	tiles    *TileManager
+	requests chan loadRequest
+	results  chan TileResult
+	sync     bool
+	// Clock is used for the retry interval, replays replace it with the log time
+	Clock u.Clock
+
+	// only accessed by the loader goroutine
+	center     Area
+	hasCenter  bool
+	lastLoaded bool
+	lastLoad   time.Time
+	prefetched Area
+}
+
+func NewTileLoader(tiles *TileManager) *TileLoader {
+	return &TileLoader{
+		tiles:    tiles,
+		requests: make(chan loadRequest, 1),
+		results:  make(chan TileResult, 1),
+		Clock:    u.SystemClock{},
+	}
+}
+
+func (l *TileLoader) Start() {
+	go l.run()
+}
+
+// StartSync makes requests load tiles on the calling goroutine so the result
+// is ready as soon as Request returns. Used where loading must be
+// deterministic, like replaying logs.
+func (l *TileLoader) StartSync() {
+	l.sync = true
+}
+
+// Request updates the position the loader works from. Unless the loader was
+// started with StartSync it never blocks; if the loader is busy the pending
+// request is replaced with the newest one.
+func (l *TileLoader) Request(pos m.Position, bearingDeg float64, speed float64) {
+	req := loadRequest{pos: pos, bearing: bearingDeg, speed: speed}
+	if l.sync {
+		l.handle(req)
+		return
+	}
+	select {
+	case l.requests <- req:
+		return
+	default:
+	}
+	select {
+	case <-l.requests:
+	default:
+	}
+	select {
+	case l.requests <- req:
+	default:
+	}
+}
+
+// Result returns a newly merged map if one is ready.
+func (l *TileLoader) Result() (TileResult, bool) {
+	select {
+	case res := <-l.results:
+		return res, true
+	default:
+		return TileResult{}, false
+	}
+}
+
+func (l *TileLoader) run() {
+	for req := range l.requests {
+		l.handle(req)
+	}
+}
+
+func (l *TileLoader) handle(req loadRequest) {
+	center := AreaAround(req.pos)
+	centerChanged := !l.hasCenter || !center.Box.Equals(l.center.Box)
+	retry := !l.lastLoaded && l.Clock.Now().Sub(l.lastLoad) > ms.TILE_RETRY_INTERVAL
+	if centerChanged || retry {
+		start := time.Now()
+		data, err := l.tiles.Around(req.pos)
+		// build the way index here, otherwise the first lookup after the swap
+		// builds it on the main loop
+		data.wayIndex()
+		res := TileResult{Data: data, Err: err, Duration: time.Since(start)}
+		slog.Info("Loaded tiles", "center", GenerateBoundsFileName(center, l.tiles.Settings), "ways", len(data.Ways()), "duration", res.Duration)
+
+		l.center = center
+		l.hasCenter = true
+		l.lastLoaded = data.Loaded
+		l.lastLoad = l.Clock.Now()
+		// drop a result the main loop hasn't picked up yet, it is outdated
+		select {
+		case <-l.results:
+		default:
+		}
+		l.results <- res
+	}
+	l.prefetch(req, center)
+}
+
+func (l *TileLoader) prefetch(req loadRequest, center Area) {
+	distance := max(req.speed*ms.TILE_PREFETCH_TIME.Seconds(), ms.TILE_PREFETCH_MIN_DISTANCE)
+	ahead := req.pos.Project(distance, req.bearing)
+	next := AreaAround(ahead)
+	if next.Box.Equals(center.Box) || next.Box.Equals(l.prefetched.Box) {
+		return
+	}
+
+	start := time.Now()
+	l.tiles.Tile(next)
+	for _, area := range neighbourAreas(next) {
+		l.tiles.Tile(area)
+	}
+	l.prefetched = next
+	slog.Debug("Prefetched tiles", "center", GenerateBoundsFileName(next, l.tiles.Settings), "duration", time.Since(start))
+}
@@ -0,0 +1,37 @@
+package maps
+
+import (
+	"testing"
+
+	m "pfeifer.dev/mapd/math"
+)
+
+func TestTileLoaderIndexesTile(t *testing.T) {
+	s := OfflineSettings{OutputDirectory: t.TempDir()}
+	pos := m.NewPosition(40.1, -79.9)
+	area := AreaAround(pos)
+	area.Ways = []TmpWay{{
+		ID:    1,
+		Name:  "Test Street",
+		Box:   m.Box{MinPos: m.NewPosition(40.09, -79.9), MaxPos: m.NewPosition(40.11, -79.9)},
+		Nodes: []TmpNode{{Latitude: 40.09, Longitude: -79.9, ID: 1}, {Latitude: 40.11, Longitude: -79.9, ID: 2}},
+	}}
+	err := WriteArea(area, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewTileLoader(NewTileManager(s, 9))
+	loader.StartSync()
+	loader.Request(pos, 0, 0)
+	res, ok := loader.Result()
+	if !ok || !res.Data.Loaded {
+		t.Fatalf("expected a loaded tile, got result %v loaded %v", ok, res.Data.Loaded)
+	}
+	if !res.Data.index.IsSet() {
+		t.Fatal("expected the way index to be built before the tile is handed to the main loop")
+	}
+	if ways := res.Data.WaysNear(pos, 100); len(ways) != 1 {
+		t.Errorf("expected the indexed tile to find 1 way, got %d", len(ways))
+	}
+}
@@ -35,6 +35,7 @@ type Offline struct {
 	overlapBox u.Curry[m.Box]
 	ways       u.Curry[[]Way]
 	overlap    u.Curry[float64]
+	index      u.Curry[wayIndex]
 }
 
 func (o *Offline) _box() m.Box {
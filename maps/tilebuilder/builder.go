@@ -0,0 +1,250 @@
+// Package tilebuilder creates offline map tiles from a description of the
+// roads instead of an OSM extract. Roads are laid out in local metres around
+// an origin, x to the east and y to the north, so tests can describe a map by
+// its shape.
+package tilebuilder
+
+import (
+	"math"
+
+	"pfeifer.dev/mapd/cereal/offline"
+	"pfeifer.dev/mapd/maps"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// Point is a position in metres east (X) and north (Y) of the origin.
+type Point struct {
+	X float64
+	Y float64
+}
+
+func Pt(x, y float64) Point {
+	return Point{X: x, Y: y}
+}
+
+// Builder collects ways for a tile. Ways that pass through the same point
+// share a node there, which is how ways are connected.
+type Builder struct {
+	origin  m.Position
+	box     m.Box
+	overlap float64
+	ways    []*WayBuilder
+	nodes   map[Point]int64
+	nextWay int64
+}
+
+// New starts a tile with its origin at a position. The tile covers the area
+// the origin is in, like a tile from GenerateOffline would.
+func New(origin m.Position) *Builder {
+	return &Builder{
+		origin:  origin,
+		box:     maps.AreaAround(origin).Box,
+		nodes:   map[Point]int64{},
+		nextWay: 1,
+	}
+}
+
+// Box sets the bounds of the tile.
+func (b *Builder) Box(box m.Box) *Builder {
+	b.box = box
+	return b
+}
+
+// Overlap sets how far in degrees the ways of the tile reach past its bounds.
+func (b *Builder) Overlap(overlap float64) *Builder {
+	b.overlap = overlap
+	return b
+}
+
+// Way adds a way through points. Its attributes are set on the returned way.
+func (b *Builder) Way(points ...Point) *WayBuilder {
+	w := &WayBuilder{
+		builder: b,
+		points:  points,
+		tmp: maps.TmpWay{
+			ID:      b.nextWay,
+			Highway: offline.HighwayType_residential,
+		},
+	}
+	b.nextWay++
+	b.ways = append(b.ways, w)
+	return w
+}
+
+// Position converts a local point to a latitude and longitude.
+func (b *Builder) Position(p Point) m.Position {
+	lat := b.origin.Lat() + p.Y/ms.R*ms.TO_DEGREES
+	lon := b.origin.Lon() + p.X/(ms.R*math.Cos(b.origin.LatRad()))*ms.TO_DEGREES
+	return m.NewPosition(lat, lon)
+}
+
+// Location is where a car at p driving on bearingDeg at speed (m/s) is.
+func (b *Builder) Location(p Point, bearingDeg float64, speed float32) m.Location {
+	return m.Location{
+		Pos:                b.Position(p),
+		BearingDeg:         bearingDeg,
+		HorizontalAccuracy: 1,
+		Speed:              speed,
+	}
+}
+
+// Area returns the ways of the tile in the form GenerateOffline writes them.
+// Like GenerateOffline only the ways that overlap the tile are kept.
+func (b *Builder) Area() maps.Area {
+	area := maps.Area{Box: b.box}
+	for _, w := range b.ways {
+		way := w.build()
+		if way.Box.Overlapping(area.OverlapBox(b.overlap)) {
+			area.Ways = append(area.Ways, way)
+		}
+	}
+	return area
+}
+
+// Build returns the tile as mapd reads it from disk.
+func (b *Builder) Build() (maps.Offline, error) {
+	data, err := maps.MarshalArea(b.Area(), b.overlap)
+	if err != nil {
+		return maps.Offline{}, err
+	}
+	return maps.ReadOffline(data), nil
+}
+
+// Write saves the tile where the tile loader looks for it.
+func (b *Builder) Write(s maps.OfflineSettings) error {
+	s.Overlap = b.overlap
+	return maps.WriteArea(b.Area(), s)
+}
+
+// node returns the id of the node at a point. Node ids are handed out in the
+// order points are first used so a tile is the same every time it is built.
+func (b *Builder) node(p Point) int64 {
+	id, ok := b.nodes[p]
+	if !ok {
+		id = int64(len(b.nodes) + 1)
+		b.nodes[p] = id
+	}
+	return id
+}
+
+// WayBuilder sets the attributes of a way. Speeds are in m/s like the speeds
+// GenerateOffline parses out of the OSM tags.
+type WayBuilder struct {
+	builder *Builder
+	points  []Point
+	tmp     maps.TmpWay
+}
+
+func (w *WayBuilder) ID(id int64) *WayBuilder {
+	w.tmp.ID = id
+	return w
+}
+
+func (w *WayBuilder) Name(name string) *WayBuilder {
+	w.tmp.Name = name
+	return w
+}
+
+func (w *WayBuilder) Ref(ref string) *WayBuilder {
+	w.tmp.Ref = ref
+	return w
+}
+
+func (w *WayBuilder) Hazard(hazard string) *WayBuilder {
+	w.tmp.Hazard = hazard
+	return w
+}
+
+func (w *WayBuilder) MaxSpeed(speed float64) *WayBuilder {
+	w.tmp.MaxSpeed = speed
+	return w
+}
+
+func (w *WayBuilder) MaxSpeedForward(speed float64) *WayBuilder {
+	w.tmp.MaxSpeedForward = speed
+	return w
+}
+
+func (w *WayBuilder) MaxSpeedBackward(speed float64) *WayBuilder {
+	w.tmp.MaxSpeedBackward = speed
+	return w
+}
+
+func (w *WayBuilder) AdvisorySpeed(speed float64) *WayBuilder {
+	w.tmp.MaxSpeedAdvisory = speed
+	return w
+}
+
+func (w *WayBuilder) Lanes(lanes uint8) *WayBuilder {
+	w.tmp.Lanes = lanes
+	return w
+}
+
+func (w *WayBuilder) LanesForward(lanes uint8) *WayBuilder {
+	w.tmp.LanesForward = lanes
+	return w
+}
+
+func (w *WayBuilder) LanesBackward(lanes uint8) *WayBuilder {
+	w.tmp.LanesBackward = lanes
+	return w
+}
+
+func (w *WayBuilder) Highway(highway offline.HighwayType) *WayBuilder {
+	w.tmp.Highway = highway
+	return w
+}
+
+func (w *WayBuilder) OneWay() *WayBuilder {
+	w.tmp.OneWay = true
+	return w
+}
+
+// Done returns to the tile so more ways can be chained.
+func (w *WayBuilder) Done() *Builder {
+	return w.builder
+}
+
+func (w *WayBuilder) build() maps.TmpWay {
+	tmp := w.tmp
+	tmp.Nodes = make([]maps.TmpNode, len(w.points))
+	minLat, minLon := float64(90), float64(180)
+	maxLat, maxLon := float64(-90), float64(-180)
+	for i, p := range w.points {
+		pos := w.builder.Position(p)
+		tmp.Nodes[i] = maps.TmpNode{Latitude: pos.Lat(), Longitude: pos.Lon(), ID: w.builder.node(p)}
+		minLat, minLon = min(minLat, pos.Lat()), min(minLon, pos.Lon())
+		maxLat, maxLon = max(maxLat, pos.Lat()), max(maxLon, pos.Lon())
+	}
+	tmp.Box = m.Box{MinPos: m.NewPosition(minLat, minLon), MaxPos: m.NewPosition(maxLat, maxLon)}
+	return tmp
+}
+
+// Line returns points from start to end no more than spacing metres apart.
+func Line(start Point, end Point, spacing float64) []Point {
+	steps := max(1, int(math.Ceil(math.Hypot(end.X-start.X, end.Y-start.Y)/spacing)))
+	points := make([]Point, 0, steps+1)
+	for i := range steps + 1 {
+		f := float64(i) / float64(steps)
+		points = append(points, round(Point{X: start.X + f*(end.X-start.X), Y: start.Y + f*(end.Y-start.Y)}))
+	}
+	return points
+}
+
+// Arc returns points on a circle around center. Angles are in degrees
+// counterclockwise from east and a negative sweep goes clockwise.
+func Arc(center Point, radius float64, startDeg float64, sweepDeg float64, segments int) []Point {
+	points := make([]Point, 0, segments+1)
+	for i := range segments + 1 {
+		angle := (startDeg + sweepDeg*float64(i)/float64(segments)) * ms.TO_RADIANS
+		points = append(points, round(Point{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}))
+	}
+	return points
+}
+
+// round snaps a point to the millimetre so points that should be the same
+// node are equal after floating point math.
+func round(p Point) Point {
+	return Point{X: math.Round(p.X*1000) / 1000, Y: math.Round(p.Y*1000) / 1000}
+}
@@ -0,0 +1,163 @@
+package tilebuilder_test
+
+import (
+	"math"
+	"testing"
+
+	"pfeifer.dev/mapd/cereal/offline"
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+func build(t *testing.T, b *tb.Builder) maps.Offline {
+	t.Helper()
+	o, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// way returns the way of a tile with an id.
+func way(t *testing.T, o *maps.Offline, id int64) maps.Way {
+	t.Helper()
+	for _, w := range o.Ways() {
+		if w.ID() == id {
+			return w
+		}
+	}
+	t.Fatalf("no way %d in tile", id)
+	return maps.Way{}
+}
+
+func TestBuildWayAttributes(t *testing.T) {
+	b := tb.New(tb.Origin)
+	b.Way(tb.Pt(0, 0), tb.Pt(100, 0), tb.Pt(100, 100)).
+		ID(42).Name("Test Street").Ref("SR 7").Hazard("school zone").
+		MaxSpeed(50 * ms.KPH_TO_MS).MaxSpeedForward(40 * ms.KPH_TO_MS).MaxSpeedBackward(30 * ms.KPH_TO_MS).
+		AdvisorySpeed(20 * ms.KPH_TO_MS).Lanes(3).LanesForward(2).LanesBackward(1).
+		Highway(offline.HighwayType_secondary).OneWay()
+	o := build(t, b)
+
+	if !o.Loaded || len(o.Ways()) != 1 {
+		t.Fatalf("expected a loaded tile with one way, got loaded %v with %d ways", o.Loaded, len(o.Ways()))
+	}
+	w := way(t, &o, 42)
+	if w.Name() != "Test Street" || w.WayRef() != "SR 7" || w.Hazard() != "school zone" {
+		t.Errorf("unexpected name %q, ref %q or hazard %q", w.Name(), w.WayRef(), w.Hazard())
+	}
+	if w.Lanes() != 3 || w.Highway() != offline.HighwayType_secondary || !w.OneWay() {
+		t.Errorf("unexpected lanes %d, highway %s or one way %v", w.Lanes(), w.Highway(), w.OneWay())
+	}
+	if math.Abs(w.MaxSpeed()-50*ms.KPH_TO_MS) > 0.01 || math.Abs(w.AdvisorySpeed()-20*ms.KPH_TO_MS) > 0.01 {
+		t.Errorf("unexpected max speed %f or advisory speed %f", w.MaxSpeed(), w.AdvisorySpeed())
+	}
+
+	nodes := w.Nodes()
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(nodes))
+	}
+	if d := nodes[0].DistanceTo(nodes[1]); math.Abs(float64(d)-100) > 0.5 {
+		t.Errorf("expected nodes 100 m apart, got %f", d)
+	}
+	if d := nodes[0].DistanceTo(nodes[2]); math.Abs(float64(d)-100*math.Sqrt2) > 0.5 {
+		t.Errorf("expected nodes %f m apart, got %f", 100*math.Sqrt2, d)
+	}
+	if nodes[0].NodeID() == 0 || nodes[0].NodeID() == nodes[1].NodeID() {
+		t.Errorf("expected distinct node ids, got %d and %d", nodes[0].NodeID(), nodes[1].NodeID())
+	}
+}
+
+func TestWaysShareNodes(t *testing.T) {
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(0, 100), 10)...)
+	b.Way(tb.Arc(tb.Pt(50, 100), 50, 180, -90, 9)...)
+	o := build(t, b)
+
+	firstWay, secondWay := way(t, &o, 1), way(t, &o, 2)
+	first, second := firstWay.Nodes(), secondWay.Nodes()
+	if !first[len(first)-1].SameNode(second[0]) {
+		t.Errorf("expected the ways to share a node")
+	}
+	if first[0].SameNode(second[len(second)-1]) {
+		t.Errorf("expected the ends of the ways to be different nodes")
+	}
+}
+
+type nextWayCase struct {
+	name      string
+	from      int64
+	isForward bool
+	want      int64
+	wantFwd   bool
+}
+
+func checkNextWays(t *testing.T, o *maps.Offline, cases []nextWayCase) {
+	t.Helper()
+	for _, c := range cases {
+		w := way(t, o, c.from)
+		next, err := w.NextWay(o, c.isForward)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if next.Way.ID() != c.want || (c.want != 0 && next.IsForward != c.wantFwd) {
+			t.Errorf("%s: next way is %d forward %v, want %d forward %v", c.name, next.Way.ID(), next.IsForward, c.want, c.wantFwd)
+		}
+	}
+}
+
+func TestForkNextWay(t *testing.T) {
+	o := build(t, tb.Fork())
+	checkNextWays(t, &o, []nextWayCase{
+		{name: "stays on main street", from: 1, isForward: true, want: 2, wantFwd: true},
+		{name: "main street back to the fork", from: 2, isForward: false, want: 1, wantFwd: false},
+		{name: "airport road back to the fork", from: 3, isForward: false, want: 1, wantFwd: false},
+		{name: "end of main street", from: 2, isForward: true, want: 0},
+	})
+}
+
+func TestCloverleafNextWay(t *testing.T) {
+	o := build(t, tb.Cloverleaf())
+	checkNextWays(t, &o, []nextWayCase{
+		{name: "stays on I 1 past the ramp", from: 1, isForward: true, want: 2, wantFwd: true},
+		{name: "stays on I 2 past the ramp", from: 3, isForward: true, want: 4, wantFwd: true},
+		{name: "ramp joins I 2 westbound", from: 5, isForward: true, want: 3, wantFwd: false},
+	})
+}
+
+func TestRoundaboutNextWay(t *testing.T) {
+	o := build(t, tb.Roundabout())
+	checkNextWays(t, &o, []nextWayCase{
+		{name: "south road enters counterclockwise", from: 5, isForward: true, want: 1, wantFwd: true},
+		{name: "east road enters counterclockwise", from: 6, isForward: false, want: 2, wantFwd: true},
+		{name: "north road enters counterclockwise", from: 7, isForward: false, want: 3, wantFwd: true},
+		{name: "west road enters counterclockwise", from: 8, isForward: true, want: 4, wantFwd: true},
+		{name: "circle continues", from: 4, isForward: true, want: 1, wantFwd: true},
+	})
+}
+
+func TestTileEdgeNextWay(t *testing.T) {
+	b := tb.TileEdge()
+	south := build(t, b)
+	if len(south.Ways()) != 1 {
+		t.Fatalf("expected only the way crossing the edge in the southern tile, got %d ways", len(south.Ways()))
+	}
+	// the next way starts outside of the tile so it can't be found
+	checkNextWays(t, &south, []nextWayCase{
+		{name: "southern tile", from: 1, isForward: true, want: 0},
+	})
+
+	north := build(t, b.Box(tb.NorthBox(b)))
+	if len(north.Ways()) != 2 {
+		t.Fatalf("expected both ways in the northern tile, got %d ways", len(north.Ways()))
+	}
+	merged := maps.MergeOffline(tb.SouthBox(b), []maps.Offline{south, north})
+	if len(merged.Ways()) != 2 {
+		t.Fatalf("expected the way in both tiles to be merged, got %d ways", len(merged.Ways()))
+	}
+	checkNextWays(t, &merged, []nextWayCase{
+		{name: "merged tiles", from: 1, isForward: true, want: 2, wantFwd: true},
+	})
+}
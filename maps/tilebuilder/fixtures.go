@@ -0,0 +1,88 @@
+package tilebuilder
+
+import (
+	"pfeifer.dev/mapd/cereal/offline"
+	"pfeifer.dev/mapd/maps"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// Origin is where the fixtures are built, well inside a single tile.
+var Origin = m.NewPosition(40.1, -79.9)
+
+// EdgeOrigin is on the boundary between two tiles, the tile to the north
+// starts at its latitude.
+var EdgeOrigin = m.NewPosition(40.25, -79.9)
+
+// Fork is a road that splits in two. Main Street runs north from (0, -600) to
+// the fork at (0, 0) and continues bearing slightly left to (-60, 600). Airport
+// Road bears slightly right from the fork to (60, 600).
+func Fork() *Builder {
+	b := New(Origin)
+	b.Way(Line(Pt(0, -600), Pt(0, 0), 50)...).
+		Name("Main Street").Highway(offline.HighwayType_primary).Lanes(2).MaxSpeed(50 * ms.KPH_TO_MS)
+	b.Way(Line(Pt(0, 0), Pt(-60, 600), 50)...).
+		Name("Main Street").Highway(offline.HighwayType_primary).Lanes(2).MaxSpeed(50 * ms.KPH_TO_MS)
+	b.Way(Line(Pt(0, 0), Pt(60, 600), 50)...).
+		Name("Airport Road").Highway(offline.HighwayType_primary).Lanes(2).MaxSpeed(80 * ms.KPH_TO_MS)
+	return b
+}
+
+// Cloverleaf is two freeways crossing on a bridge with one loop ramp. I 1
+// runs north and is split where the ramp leaves at (0, 100). I 2 runs east
+// and is split where the ramp joins at (100, 0). The freeways cross at (0, 0)
+// without sharing a node. The one way ramp leaves I 1 heading north and turns
+// right through 270 degrees around (100, 100) to join I 2 heading west.
+func Cloverleaf() *Builder {
+	b := New(Origin)
+	freeway := func(points ...Point) *WayBuilder {
+		return b.Way(points...).Highway(offline.HighwayType_motorway).Lanes(4).MaxSpeed(110 * ms.KPH_TO_MS)
+	}
+	freeway(append(Line(Pt(0, -1000), Pt(0, -50), 50), Pt(0, 100))...).Ref("I 1")
+	freeway(Line(Pt(0, 100), Pt(0, 1000), 50)...).Ref("I 1")
+	freeway(append(Line(Pt(-1000, 0), Pt(-50, 0), 50), Pt(100, 0))...).Ref("I 2")
+	freeway(Line(Pt(100, 0), Pt(1000, 0), 50)...).Ref("I 2")
+	b.Way(Arc(Pt(100, 100), 100, 180, -270, 27)...).
+		Highway(offline.HighwayType_motorwayLink).Lanes(1).OneWay().AdvisorySpeed(40 * ms.KPH_TO_MS)
+	return b
+}
+
+// Roundabout is a one way circle of radius 30 around (0, 0), driven
+// counterclockwise, with a way between each pair of entries. South Road,
+// East Road, North Road and West Road each run 400 metres out from the circle.
+func Roundabout() *Builder {
+	b := New(Origin)
+	for _, start := range []float64{-90, 0, 90, 180} {
+		b.Way(Arc(Pt(0, 0), 30, start, 90, 9)...).
+			Highway(offline.HighwayType_tertiary).Lanes(1).OneWay()
+	}
+	b.Way(Line(Pt(0, -400), Pt(0, -30), 50)...).Name("South Road").Lanes(2)
+	b.Way(Line(Pt(30, 0), Pt(400, 0), 50)...).Name("East Road").Lanes(2)
+	b.Way(Line(Pt(0, 30), Pt(0, 400), 50)...).Name("North Road").Lanes(2)
+	b.Way(Line(Pt(-400, 0), Pt(-30, 0), 50)...).Name("West Road").Lanes(2)
+	return b
+}
+
+// TileEdge is a road that crosses from one tile into the next. Edge Road runs
+// north from (0, -800) across the tile boundary at y = 0 to (0, 100), where it
+// continues as a second way to (0, 800). The builder covers the southern tile,
+// NorthBox is the tile across the boundary.
+func TileEdge() *Builder {
+	b := New(EdgeOrigin)
+	b.Box(SouthBox(b))
+	b.Way(Line(Pt(0, -800), Pt(0, 100), 100)...).Name("Edge Road").Lanes(2).MaxSpeed(70 * ms.KPH_TO_MS)
+	b.Way(Line(Pt(0, 100), Pt(0, 800), 100)...).Name("Edge Road").Lanes(2).MaxSpeed(70 * ms.KPH_TO_MS)
+	return b
+}
+
+// SouthBox is the tile just south of the origin of b.
+func SouthBox(b *Builder) m.Box {
+	area := maps.AreaAround(b.Position(Pt(0, -1)))
+	return area.Box
+}
+
+// NorthBox is the tile just north of the origin of b.
+func NorthBox(b *Builder) m.Box {
+	area := maps.AreaAround(b.Position(Pt(0, 1)))
+	return area.Box
+}
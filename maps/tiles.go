@@ -0,0 +1,141 @@
+package maps
+
+import (
+	"container/list"
+
+	"github.com/pkg/errors"
+	m "pfeifer.dev/mapd/math"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+type cachedTile struct {
+	name string
+	tile Offline
+}
+
+// TileManager keeps recently used tiles in memory and combines the tile
+// around a position with its 8 neighbours so lookups don't stop at tile
+// edges.
+type TileManager struct {
+	Settings OfflineSettings
+	Capacity int
+	tiles    map[string]*list.Element
+	lru      *list.List
+}
+
+func NewTileManager(s OfflineSettings, capacity int) *TileManager {
+	return &TileManager{
+		Settings: s,
+		Capacity: capacity,
+		tiles:    map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// Tile returns the tile for an area, reading it from disk if it is not
+// cached. Tiles that fail to load are not cached so they are picked up once
+// they have been downloaded.
+func (t *TileManager) Tile(area Area) (Offline, error) {
+	name := GenerateBoundsFileName(area, t.Settings)
+	if e, ok := t.tiles[name]; ok {
+		t.lru.MoveToFront(e)
+		return e.Value.(*cachedTile).tile, nil
+	}
+
+	tile, err := ReadArea(area, t.Settings)
+	if err != nil || !tile.Loaded {
+		return tile, err
+	}
+
+	// read the ways once so every copy handed out shares them
+	tile.Ways()
+	t.tiles[name] = t.lru.PushFront(&cachedTile{name: name, tile: tile})
+	for t.lru.Len() > t.Capacity {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.tiles, oldest.Value.(*cachedTile).name)
+	}
+	return tile, nil
+}
+
+// Around returns the tile containing pos merged with its neighbouring tiles.
+// The merged map reports the center tile's box so callers know when to
+// re-center, while its overlap box covers all neighbours.
+func (t *TileManager) Around(pos m.Position) (Offline, error) {
+	center := AreaAround(pos)
+	centerTile, err := t.Tile(center)
+	tiles := []Offline{centerTile}
+	for _, area := range neighbourAreas(center) {
+		// missing neighbours are expected at the edge of downloaded regions
+		tile, _ := t.Tile(area)
+		if tile.Loaded {
+			tiles = append(tiles, tile)
+		}
+	}
+
+	merged := MergeOffline(center.Box, tiles)
+	merged.Loaded = centerTile.Loaded
+	return merged, errors.Wrap(err, "could not load center tile")
+}
+
+func neighbourAreas(center Area) []Area {
+	areas := make([]Area, 0, 8)
+	centerPos := center.Box.MinPos
+	for _, dLat := range []float64{-1, 0, 1} {
+		for _, dLon := range []float64{-1, 0, 1} {
+			if dLat == 0 && dLon == 0 {
+				continue
+			}
+			pos := m.NewPosition(
+				centerPos.Lat()+(dLat+0.5)*ms.AREA_BOX_DEGREES,
+				centerPos.Lon()+(dLon+0.5)*ms.AREA_BOX_DEGREES,
+			)
+			areas = append(areas, AreaAround(pos))
+		}
+	}
+	return areas
+}
+
+type boxKey struct {
+	minLat, minLon, maxLat, maxLon float64
+}
+
+// MergeOffline combines several tiles into one queryable map. Ways that are
+// stored in more than one tile because of the tile overlap are only kept
+// once.
+func MergeOffline(box m.Box, tiles []Offline) Offline {
+	ways := []Way{}
+	seenIds := map[int64]bool{}
+	seenBoxes := map[boxKey]bool{}
+	overlap := float64(0)
+	for i := range tiles {
+		tile := &tiles[i]
+		if !tile.Loaded {
+			continue
+		}
+		overlap = max(overlap, tile.Overlap())
+		for _, way := range tile.Ways() {
+			if way.ID() != 0 {
+				if seenIds[way.ID()] {
+					continue
+				}
+				seenIds[way.ID()] = true
+			} else {
+				wBox := way.Box()
+				key := boxKey{wBox.MinPos.Lat(), wBox.MinPos.Lon(), wBox.MaxPos.Lat(), wBox.MaxPos.Lon()}
+				if seenBoxes[key] {
+					continue
+				}
+				seenBoxes[key] = true
+			}
+			ways = append(ways, way)
+		}
+	}
+
+	merged := Offline{Loaded: len(ways) > 0}
+	merged.box.Set(box)
+	merged.overlap.Set(overlap)
+	merged.overlapBox.Set(box.Overlap(ms.AREA_BOX_DEGREES + overlap))
+	merged.ways.Set(ways)
+	return merged
+}
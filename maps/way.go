@@ -6,7 +6,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"pfeifer.dev/mapd/cereal/log"
 	"pfeifer.dev/mapd/cereal/offline"
 	m "pfeifer.dev/mapd/math"
 	ms "pfeifer.dev/mapd/settings"
@@ -22,20 +21,23 @@ const (
 )
 
 // Highway hierarchy ranking
-var HIGHWAY_RANK = map[string]int{
-	"motorway":       0,
-	"motorway_link":  1,
-	"trunk":          10,
-	"trunk_link":     11,
-	"primary":        20,
-	"primary_link":   21,
-	"secondary":      30,
-	"secondary_link": 31,
-	"tertiary":       40,
-	"tertiary_link":  41,
-	"unclassified":   50,
-	"residential":    60,
-	"living_street":  61,
+var HIGHWAY_RANK = map[offline.HighwayType]int{
+	offline.HighwayType_motorway:      0,
+	offline.HighwayType_motorwayLink:  1,
+	offline.HighwayType_trunk:         10,
+	offline.HighwayType_trunkLink:     11,
+	offline.HighwayType_primary:       20,
+	offline.HighwayType_primaryLink:   21,
+	offline.HighwayType_secondary:     30,
+	offline.HighwayType_secondaryLink: 31,
+	offline.HighwayType_tertiary:      40,
+	offline.HighwayType_tertiaryLink:  41,
+	offline.HighwayType_unclassified:  50,
+	offline.HighwayType_road:          50,
+	offline.HighwayType_residential:   60,
+	offline.HighwayType_livingStreet:  61,
+	offline.HighwayType_service:       70,
+	offline.HighwayType_other:         80,
 }
 
 // Road type detection and priorities
@@ -57,10 +59,27 @@ type OnWayResult struct {
 }
 
 type DistanceResult struct {
-	LineStart    m.Position
-	LineEnd      m.Position
-	LinePosition m.LinePosition
-	Distance     float32
+	LineStart     m.Position
+	LineEnd       m.Position
+	LinePosition  m.LinePosition
+	Distance      float32
+	AlongDistance float32 // distance along the way from its first node to LinePosition
+}
+
+// LateralOffset returns the distance of pos from the way's center line with
+// positive values to the right when travelling in the given direction.
+func (d *DistanceResult) LateralOffset(pos m.Position, isForward bool) float32 {
+	segment := d.LineStart.VectorTo(d.LineEnd)
+	toPos := d.LineStart.VectorTo(pos)
+	delta := toPos.Bearing() - segment.Bearing()
+	offset := d.Distance
+	if math.Sin(delta) < 0 {
+		offset = -offset
+	}
+	if !isForward {
+		offset = -offset
+	}
+	return offset
 }
 
 type NextWayResult struct {
@@ -91,10 +110,16 @@ type Way struct {
 	box              u.Curry[m.Box]
 	nodes            u.Curry[[]m.Position]
 	lanes            u.Curry[int]
+	highway          u.Curry[offline.HighwayType]
 	advisorySpeed    u.Curry[float64]
 	hazard           u.Curry[string]
 	maxSpeedForward  u.Curry[float64]
 	maxSpeedBackward u.Curry[float64]
+
+	// user override, refreshed when the override store changes
+	overrideVersion int
+	override        WayOverride
+	hasOverride     bool
 }
 
 func (w *Way) IsForwardFrom(matchNode m.Position) bool {
@@ -107,7 +132,7 @@ func (w *Way) IsForwardFrom(matchNode m.Position) bool {
 	}
 
 	lastNode := nodes[len(nodes)-1]
-	return !lastNode.Equals(matchNode)
+	return !lastNode.SameNode(matchNode)
 }
 
 func IsForward(lineStart m.Position, lineEnd m.Position, bearing float64) bool {
@@ -125,7 +150,7 @@ func (w *Way) _nodes() []m.Position {
 	res := make([]m.Position, nodes.Len())
 	for i := range nodes.Len() {
 		node := nodes.At(i)
-		res[i] = m.NewPosition(node.Latitude(), node.Longitude())
+		res[i] = m.NewNodePosition(node.Latitude(), node.Longitude(), node.NodeId())
 	}
 	return res
 }
@@ -134,6 +159,21 @@ func (w *Way) Nodes() []m.Position {
 	return w.nodes.Value(w._nodes)
 }
 
+// ID returns the OSM way id, or 0 for tiles generated without way ids.
+func (w *Way) ID() int64 {
+	return w.Way.Id()
+}
+
+// SameWay reports whether both ways are the same OSM way. Tiles without way
+// ids fall back to comparing bounding boxes.
+func (w *Way) SameWay(other *Way) bool {
+	if w.ID() != 0 && other.ID() != 0 {
+		return w.ID() == other.ID()
+	}
+	box := other.Box()
+	return box.Equals(w.Box())
+}
+
 func (w *Way) _oneWay() bool {
 	return w.Way.OneWay()
 }
@@ -171,14 +211,32 @@ func (w *Way) _maxSpeed() float64 {
 }
 
 func (w *Way) MaxSpeed() float64 {
+	if o, ok := w.Override(); ok {
+		if o.IgnoreMaxSpeed {
+			return 0
+		}
+		if o.MaxSpeed > 0 {
+			return o.MaxSpeed
+		}
+	}
 	return w.maxSpeed.Value(w._maxSpeed)
 }
 
+// overridesMaxSpeed reports whether a user override replaces all of the
+// maxspeed tags of the way.
+func (w *Way) overridesMaxSpeed() bool {
+	o, ok := w.Override()
+	return ok && (o.IgnoreMaxSpeed || o.MaxSpeed > 0)
+}
+
 func (w *Way) _maxSpeedForward() float64 {
 	return w.Way.MaxSpeedForward()
 }
 
 func (w *Way) MaxSpeedForward() float64 {
+	if w.overridesMaxSpeed() {
+		return 0
+	}
 	return w.maxSpeedForward.Value(w._maxSpeedForward)
 }
 
@@ -187,6 +245,9 @@ func (w *Way) _maxSpeedBackward() float64 {
 }
 
 func (w *Way) MaxSpeedBackward() float64 {
+	if w.overridesMaxSpeed() {
+		return 0
+	}
 	return w.maxSpeedBackward.Value(w._maxSpeedBackward)
 }
 
@@ -209,11 +270,49 @@ func (w *Way) Lanes() int {
 	return w.lanes.Value(w._lanes)
 }
 
+// DirectionLanes returns the number of lanes in the direction of travel using
+// lanes:forward and lanes:backward when tagged, otherwise half of the lanes of
+// a two way road. Returns 0 when the lane count is unknown.
+func (w *Way) DirectionLanes(isForward bool) int {
+	if w.OneWay() {
+		return w.Lanes()
+	}
+	directional := w.Way.LanesBackward()
+	if isForward {
+		directional = w.Way.LanesForward()
+	}
+	if directional > 0 {
+		return int(directional)
+	}
+	if w.Lanes() == 0 {
+		return 0
+	}
+	return max(w.Lanes()/2, 1)
+}
+
+func (w *Way) _highway() offline.HighwayType {
+	return w.Way.Highway()
+}
+
+// The osm highway class of the way. Tiles generated before the class was
+// stored return HighwayType_unknown, in which case callers fall back to
+// guessing the class from the name, ref and lanes.
+func (w *Way) Highway() offline.HighwayType {
+	return w.highway.Value(w._highway)
+}
+
+func (w *Way) HasHighway() bool {
+	return w.Highway() != offline.HighwayType_unknown
+}
+
 func (w *Way) _advisorySpeed() float64 {
 	return w.Way.AdvisorySpeed()
 }
 
 func (w *Way) AdvisorySpeed() float64 {
+	if o, ok := w.Override(); ok && o.AdvisorySpeed > 0 {
+		return o.AdvisorySpeed
+	}
 	return w.advisorySpeed.Value(w._advisorySpeed)
 }
 
@@ -226,24 +325,51 @@ func (w *Way) _hazard() string {
 }
 
 func (w *Way) Hazard() string {
+	if o, ok := w.Override(); ok {
+		if o.IgnoreHazard {
+			return ""
+		}
+		if o.Hazard != "" {
+			return o.Hazard
+		}
+	}
 	return w.hazard.Value(w._hazard)
 }
 
-func (w *Way) OnWay(location log.GpsLocationData, distanceMultiplier float32) (OnWayResult, error) {
+// CurveSpeed returns the user override speed for curves on the way, or 0 when
+// curve speeds are calculated from the map.
+func (w *Way) CurveSpeed() float64 {
+	if o, ok := w.Override(); ok {
+		return o.CurveSpeed
+	}
+	return 0
+}
+
+// Override returns the user override for the way if there is one.
+func (w *Way) Override() (WayOverride, bool) {
+	version := WayOverrides.Version()
+	if w.overrideVersion != version {
+		w.overrideVersion = version
+		w.override, w.hasOverride = WayOverrides.Find(w)
+	}
+	return w.override, w.hasOverride
+}
+
+func (w *Way) OnWay(location m.Location, distanceMultiplier float32) (OnWayResult, error) {
 	res := OnWayResult{}
-	pos := m.NewPosition(location.Latitude(), location.Longitude())
+	pos := location.Pos
 	d, err := w.DistanceFrom(pos)
 	res.Distance = d
 	if err != nil {
 		res.OnWay = false
 		return res, errors.Wrap(err, "could not get distance to way")
 	}
-	max_dist := max(location.HorizontalAccuracy(), 5) + w.Width()
+	max_dist := max(location.HorizontalAccuracy, 5) + w.Width()
 	max_dist *= distanceMultiplier
 
 	if d.Distance < max_dist {
 		res.OnWay = true
-		res.IsForward = IsForward(d.LineStart, d.LineEnd, float64(location.BearingDeg()))
+		res.IsForward = IsForward(d.LineStart, d.LineEnd, location.BearingDeg)
 		if !res.IsForward && w.OneWay() {
 			res.OnWay = false
 		}
@@ -253,8 +379,8 @@ func (w *Way) OnWay(location log.GpsLocationData, distanceMultiplier float32) (O
 	return res, nil
 }
 
-func (w *Way) BearingAlignment(location log.GpsLocationData) (float32, error) {
-	pos := m.NewPosition(location.Latitude(), location.Longitude())
+func (w *Way) BearingAlignment(location m.Location) (float32, error) {
+	pos := location.Pos
 	d, err := w.DistanceFrom(pos)
 	if err != nil {
 		return 1.0, err
@@ -264,7 +390,7 @@ func (w *Way) BearingAlignment(location log.GpsLocationData) (float32, error) {
 	wayBearing := lineVec.Bearing()
 
 	// Calculate bearing delta
-	delta := math.Abs(float64(location.BearingDeg())*ms.TO_RADIANS - wayBearing)
+	delta := math.Abs(location.BearingDeg*ms.TO_RADIANS - wayBearing)
 
 	// Normalize to 0-π range
 	if delta > math.Pi {
@@ -311,6 +437,7 @@ func (w *Way) DistanceFrom(pos m.Position) (DistanceResult, error) {
 	res.LineStart = minNodeStart
 	res.LineEnd = minNodeEnd
 	res.LinePosition = minLinePosition
+	res.AlongDistance = onWayDistance
 	return res, nil
 }
 
@@ -332,16 +459,11 @@ func (w *Way) GetStartEnd(isForward bool) (m.Position, m.Position) {
 
 func (w *Way) MatchingWays(offlineMaps *Offline, matchNode m.Position) ([]Way, error) {
 	matchingWays := []Way{}
-	ways := offlineMaps.Ways()
+	ways := offlineMaps.WaysAtEndpoint(matchNode)
 
 	for i := range len(ways) {
 		way := ways[i]
-		if len(way.Nodes()) == 0 {
-			continue
-		}
-
-		box := way.Box()
-		if box.Equals(w.Box()) {
+		if w.SameWay(&way) {
 			continue
 		}
 
@@ -352,7 +474,7 @@ func (w *Way) MatchingWays(offlineMaps *Offline, matchNode m.Position) ([]Way, e
 
 		fNode := wNodes[0]
 		lNode := wNodes[len(wNodes)-1]
-		if fNode.Equals(matchNode) || lNode.Equals(matchNode) {
+		if fNode.SameNode(matchNode) || lNode.SameNode(matchNode) {
 			matchingWays = append(matchingWays, way)
 		}
 	}
@@ -367,7 +489,7 @@ func (w *Way) isValidConnection(matchNode, bearingNode m.Position, maxCurvature
 	}
 
 	var nextBearingNode m.Position
-	if matchNode.Equals(nodes[0]) {
+	if matchNode.SameNode(nodes[0]) {
 		nextBearingNode = nodes[1]
 	} else {
 		nextBearingNode = nodes[len(nodes)-2]
@@ -402,7 +524,7 @@ func (w *Way) DistanceToNode(pos m.Position, isForward bool, finalNode m.Positio
 			index = len(nodes) - 1 - i
 		}
 		node := nodes[index]
-		if (node.Equals(distanceResult.LineStart) || node.Equals(distanceResult.LineEnd)) && !stopFiltering {
+		if (node.SameNode(distanceResult.LineStart) || node.SameNode(distanceResult.LineEnd)) && !stopFiltering {
 			stopFiltering = true
 			continue
 		}
@@ -412,7 +534,7 @@ func (w *Way) DistanceToNode(pos m.Position, isForward bool, finalNode m.Positio
 
 		dist += lastPos.DistanceTo(node)
 		lastPos = node
-		if lastPos.Equals(finalNode) {
+		if lastPos.SameNode(finalNode) {
 			found = true
 			break
 		}
@@ -420,6 +542,64 @@ func (w *Way) DistanceToNode(pos m.Position, isForward bool, finalNode m.Positio
 	return dist, found, nil
 }
 
+// PositionAlong walks distance meters along the way from pos in the direction
+// of travel. If the end of the way is reached first it returns the last node
+// along with the distance that is left over.
+func (w *Way) PositionAlong(pos m.Position, isForward bool, distance float32) (m.Position, float32, error) {
+	path, err := w.NodesAhead(pos, isForward)
+	if err != nil {
+		return pos, distance, err
+	}
+
+	current := path[0]
+	for _, node := range path[1:] {
+		if distance <= 0 {
+			return current, 0, nil
+		}
+		segment := current.DistanceTo(node)
+		if segment >= distance {
+			t := float64(distance / segment)
+			return m.NewPosition(
+				current.Lat()+t*(node.Lat()-current.Lat()),
+				current.Lon()+t*(node.Lon()-current.Lon()),
+			), 0, nil
+		}
+		distance -= segment
+		current = node
+	}
+	return current, distance, nil
+}
+
+// NodesAhead returns the point on the way nearest to pos followed by the
+// nodes after it in the direction of travel.
+func (w *Way) NodesAhead(pos m.Position, isForward bool) ([]m.Position, error) {
+	d, err := w.DistanceFrom(pos)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find position on way")
+	}
+	nodes := w.Nodes()
+	ordered := make([]m.Position, len(nodes))
+	for i := range nodes {
+		index := i
+		if !isForward {
+			index = len(nodes) - 1 - i
+		}
+		ordered[i] = nodes[index]
+	}
+
+	// find the node ahead of pos in the direction of travel
+	next := len(ordered) - 1
+	for i := 1; i < len(ordered); i++ {
+		prevNode := ordered[i-1]
+		if (prevNode.SameNode(d.LineStart) && ordered[i].SameNode(d.LineEnd)) || (prevNode.SameNode(d.LineEnd) && ordered[i].SameNode(d.LineStart)) {
+			next = i
+			break
+		}
+	}
+
+	return append([]m.Position{d.LinePosition.Pos}, ordered[next:]...), nil
+}
+
 func (w *Way) _distance() float32 {
 	nodes := w.Nodes()
 
@@ -476,6 +656,31 @@ func (w *Way) Width() float32 {
 }
 
 func (w *Way) _context() RoadContext {
+	if !w.HasHighway() {
+		return w._contextFromName()
+	}
+
+	if w.IsFreeway() {
+		return CONTEXT_FREEWAY
+	}
+
+	switch w.Highway() {
+	case offline.HighwayType_motorway,
+		offline.HighwayType_motorwayLink,
+		offline.HighwayType_trunk,
+		offline.HighwayType_trunkLink,
+		offline.HighwayType_primary,
+		offline.HighwayType_primaryLink,
+		offline.HighwayType_secondary,
+		offline.HighwayType_secondaryLink,
+		offline.HighwayType_other:
+		return CONTEXT_UNKNOWN
+	}
+	return CONTEXT_CITY
+}
+
+// fallback for tiles without a highway class
+func (w *Way) _contextFromName() RoadContext {
 	lanes := w.Way.Lanes()
 	name, _ := w.Way.Name()
 	ref, _ := w.Way.Ref()
@@ -500,6 +705,20 @@ func (w *Way) Context() RoadContext {
 }
 
 func (w *Way) _isFreeway() bool {
+	switch w.Highway() {
+	case offline.HighwayType_unknown:
+		return w._isFreewayFromName()
+	case offline.HighwayType_motorway, offline.HighwayType_motorwayLink:
+		return true
+	case offline.HighwayType_trunk, offline.HighwayType_trunkLink:
+		// divided trunk roads are mapped as one way per carriageway and drive like a freeway
+		return w.OneWay()
+	}
+	return false
+}
+
+// fallback for tiles without a highway class
+func (w *Way) _isFreewayFromName() bool {
 	lanes := w.Way.Lanes()
 	name, _ := w.Way.Name()
 	ref, _ := w.Way.Ref()
@@ -529,6 +748,14 @@ func (w *Way) IsFreeway() bool {
 }
 
 func (w *Way) _rank() int {
+	if rank, ok := HIGHWAY_RANK[w.Highway()]; ok {
+		return rank
+	}
+	return w._rankFromName()
+}
+
+// fallback for tiles without a highway class
+func (w *Way) _rankFromName() int {
 	name, _ := w.Way.Name()
 	ref, _ := w.Way.Ref()
 	lanes := w.Way.Lanes()
@@ -536,9 +763,9 @@ func (w *Way) _rank() int {
 	// Infer highway type from characteristics
 	if w.IsFreeway() {
 		if lanes >= 6 {
-			return HIGHWAY_RANK["motorway"]
+			return HIGHWAY_RANK[offline.HighwayType_motorway]
 		}
-		return HIGHWAY_RANK["trunk"]
+		return HIGHWAY_RANK[offline.HighwayType_trunk]
 	}
 
 	nameUpper := strings.ToUpper(name)
@@ -547,21 +774,21 @@ func (w *Way) _rank() int {
 	// Primary roads (usually have ref numbers)
 	if len(ref) > 0 && !strings.Contains(nameUpper, "STREET") {
 		if strings.HasPrefix(refUpper, "US-") || strings.HasPrefix(refUpper, "SR-") {
-			return HIGHWAY_RANK["primary"]
+			return HIGHWAY_RANK[offline.HighwayType_primary]
 		}
 
-		return HIGHWAY_RANK["secondary"]
+		return HIGHWAY_RANK[offline.HighwayType_secondary]
 	}
 
 	// Local roads
 	if strings.Contains(nameUpper, "STREET") ||
 		strings.Contains(nameUpper, "AVENUE") ||
 		strings.Contains(nameUpper, "ROAD") {
-		return HIGHWAY_RANK["residential"]
+		return HIGHWAY_RANK[offline.HighwayType_residential]
 	}
 
 	// Default to unclassified
-	return HIGHWAY_RANK["unclassified"]
+	return HIGHWAY_RANK[offline.HighwayType_unclassified]
 }
 
 // Get highway hierarchy rank for a way
@@ -579,6 +806,23 @@ func (w *Way) _priority() int {
 		priority = 30
 	}
 
+	if w.HasHighway() {
+		// higher classes of road get a larger boost, roughly matching the name based boosts
+		priority += (HIGHWAY_RANK[offline.HighwayType_other] - w.Rank()) / 2
+	} else {
+		priority += w._priorityFromName(name)
+	}
+
+	if len(ref) > 0 {
+		priority += 10
+	}
+
+	return priority
+}
+
+// fallback for tiles without a highway class
+func (w *Way) _priorityFromName(name string) int {
+	priority := 0
 	switch w.Context() {
 	case CONTEXT_FREEWAY:
 		if w.IsFreeway() {
@@ -611,10 +855,6 @@ func (w *Way) _priority() int {
 		}
 	}
 
-	if len(ref) > 0 {
-		priority += 10
-	}
-
 	return priority
 }
 
@@ -674,6 +914,12 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 	if w.Context() == CONTEXT_FREEWAY {
 		filteredWays := []Way{}
 		for _, mWay := range matchingWays {
+			if mWay.HasHighway() {
+				if mWay.Highway() != offline.HighwayType_service {
+					filteredWays = append(filteredWays, mWay)
+				}
+				continue
+			}
 			name := mWay.WayName()
 			nameUpper := strings.ToUpper(name)
 			if !strings.Contains(nameUpper, "SERVICE") &&
@@ -712,7 +958,7 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 		}
 
 		if len(candidates) > 0 {
-			bestWay := selectBestCandidate(candidates, w.Context())
+			bestWay := selectBestCandidate(candidates, w)
 			isForward := bestWay.IsForwardFrom(matchNode)
 			start, end := bestWay.GetStartEnd(isForward)
 			return NextWayResult{
@@ -742,7 +988,7 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 		}
 
 		if len(candidates) > 0 {
-			bestWay := selectBestCandidate(candidates, w.Context())
+			bestWay := selectBestCandidate(candidates, w)
 			isForward := bestWay.IsForwardFrom(matchNode)
 			start, end := bestWay.GetStartEnd(isForward)
 			return NextWayResult{
@@ -779,7 +1025,7 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 		}
 
 		if len(candidates) > 0 {
-			bestWay := selectBestCandidate(candidates, w.Context())
+			bestWay := selectBestCandidate(candidates, w)
 			isForward := bestWay.IsForwardFrom(matchNode)
 			start, end := bestWay.GetStartEnd(isForward)
 			return NextWayResult{
@@ -803,7 +1049,7 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 	}
 
 	if len(validWays) > 0 {
-		bestWay := selectBestCandidate(validWays, w.Context())
+		bestWay := selectBestCandidate(validWays, w)
 		nextIsForward := bestWay.IsForwardFrom(matchNode)
 		start, end := bestWay.GetStartEnd(nextIsForward)
 		return NextWayResult{
@@ -828,11 +1074,12 @@ func (w *Way) NextWay(offlineMaps *Offline, isForward bool) (NextWayResult, erro
 	return NextWayResult{StartPosition: matchNode}, nil
 }
 
-func selectBestCandidate(candidates []Way, context RoadContext) Way {
+func selectBestCandidate(candidates []Way, from *Way) Way {
 	if len(candidates) == 1 {
 		return candidates[0]
 	}
 
+	context := from.Context()
 	bestWay := candidates[0]
 	bestScore := float64(-1000)
 
@@ -850,6 +1097,12 @@ func selectBestCandidate(candidates []Way, context RoadContext) Way {
 			score += float64(lanes) * laneWeight
 		}
 
+		// prefer staying on the same class of road, e.g. continuing on the
+		// motorway instead of taking an unnamed motorway_link at a split
+		if from.HasHighway() && way.Highway() == from.Highway() {
+			score += 25
+		}
+
 		if score > bestScore {
 			bestScore = score
 			bestWay = way
@@ -0,0 +1,243 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/params"
+)
+
+var WAY_OVERRIDES_PATH = filepath.Join(params.BasePath, "way_overrides.json")
+
+// WAY_OVERRIDES_DIRECTORY holds the files way overrides are imported from and
+// exported to.
+var WAY_OVERRIDES_DIRECTORY = filepath.Join(params.BasePath, "way_overrides")
+
+// WayOverride replaces values from the offline map files for a single way.
+// Zero values leave the map value in place.
+type WayOverride struct {
+	WayId          int64   `json:"way_id,omitempty"`
+	Geometry       string  `json:"geometry,omitempty"`  // first and last node of the way, used when tiles have no way ids
+	Name           string  `json:"name,omitempty"`      // informational only
+	MaxSpeed       float64 `json:"max_speed,omitempty"` // m/s
+	IgnoreMaxSpeed bool    `json:"ignore_max_speed,omitempty"`
+	AdvisorySpeed  float64 `json:"advisory_speed,omitempty"` // m/s
+	Hazard         string  `json:"hazard,omitempty"`
+	IgnoreHazard   bool    `json:"ignore_hazard,omitempty"`
+	CurveSpeed     float64 `json:"curve_speed,omitempty"` // m/s, used for every curve on the way
+}
+
+func (o *WayOverride) Empty() bool {
+	return o.MaxSpeed <= 0 && !o.IgnoreMaxSpeed && o.AdvisorySpeed <= 0 && o.Hazard == "" && !o.IgnoreHazard && o.CurveSpeed <= 0
+}
+
+// WayOverrideStore holds the user overrides for ways. Overrides are matched by
+// way id when available and otherwise by the way geometry so they survive
+// tiles generated without ids.
+type WayOverrideStore struct {
+	mu         sync.RWMutex
+	byId       map[int64]*WayOverride
+	byGeometry map[string]*WayOverride
+	version    int
+}
+
+var WayOverrides = &WayOverrideStore{}
+
+// GeometryKey identifies a way by the coordinates of its first and last node.
+func GeometryKey(w *Way) string {
+	nodes := w.Nodes()
+	if len(nodes) == 0 {
+		return ""
+	}
+	first := nodes[0]
+	last := nodes[len(nodes)-1]
+	return fmt.Sprintf("%.6f,%.6f;%.6f,%.6f", first.Lat(), first.Lon(), last.Lat(), last.Lon())
+}
+
+// Load replaces the overrides in memory with the overrides file on the device.
+func (s *WayOverrideStore) Load() {
+	overrides, err := readWayOverrides(WAY_OVERRIDES_PATH)
+	if err != nil {
+		slog.Warn("could not load way overrides", "error", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+	for _, o := range overrides {
+		s.add(o)
+	}
+	if len(overrides) > 0 {
+		slog.Info("Loaded way overrides", "count", len(overrides))
+	}
+}
+
+// Version changes each time the overrides are modified so ways can refresh
+// their cached override.
+func (s *WayOverrideStore) Version() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Find returns the override for a way if there is one.
+func (s *WayOverrideStore) Find(w *Way) (WayOverride, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o := s.find(w)
+	if o == nil {
+		return WayOverride{}, false
+	}
+	return *o, true
+}
+
+// Edit applies edit to the override for a way, creating it if needed, and
+// saves the overrides to the device. Overrides left empty are removed.
+func (s *WayOverrideStore) Edit(w *Way, edit func(o *WayOverride)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.find(w)
+	if o == nil {
+		o = &WayOverride{}
+	}
+	s.remove(o)
+	o.WayId = w.ID()
+	o.Geometry = GeometryKey(w)
+	o.Name = w.WayName()
+	if o.Name == "" {
+		o.Name = w.WayRef()
+	}
+	edit(o)
+	if !o.Empty() {
+		s.add(*o)
+	}
+	s.version++
+	return s.save()
+}
+
+// Import merges the overrides from a file into the current overrides. Imported
+// overrides replace existing overrides for the same way.
+func (s *WayOverrideStore) Import(path string) error {
+	overrides, err := readWayOverrides(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range overrides {
+		if existing := s.lookup(o.WayId, o.Geometry); existing != nil {
+			s.remove(existing)
+		}
+		s.add(o)
+	}
+	s.version++
+	return s.save()
+}
+
+// Export writes all overrides to a file.
+func (s *WayOverrideStore) Export(path string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return writeWayOverrides(path, s.list())
+}
+
+func (s *WayOverrideStore) reset() {
+	s.byId = map[int64]*WayOverride{}
+	s.byGeometry = map[string]*WayOverride{}
+	s.version++
+}
+
+func (s *WayOverrideStore) add(o WayOverride) {
+	if o.WayId == 0 && o.Geometry == "" {
+		return
+	}
+	if s.byId == nil {
+		s.reset()
+	}
+	if o.WayId != 0 {
+		s.byId[o.WayId] = &o
+	}
+	if o.Geometry != "" {
+		s.byGeometry[o.Geometry] = &o
+	}
+}
+
+func (s *WayOverrideStore) remove(o *WayOverride) {
+	if s.byId[o.WayId] == o {
+		delete(s.byId, o.WayId)
+	}
+	if s.byGeometry[o.Geometry] == o {
+		delete(s.byGeometry, o.Geometry)
+	}
+}
+
+func (s *WayOverrideStore) lookup(wayId int64, geometry string) *WayOverride {
+	if o, ok := s.byId[wayId]; ok && wayId != 0 {
+		return o
+	}
+	if o, ok := s.byGeometry[geometry]; ok && geometry != "" {
+		return o
+	}
+	return nil
+}
+
+func (s *WayOverrideStore) find(w *Way) *WayOverride {
+	if len(s.byId) == 0 && len(s.byGeometry) == 0 {
+		return nil
+	}
+	return s.lookup(w.ID(), GeometryKey(w))
+}
+
+func (s *WayOverrideStore) list() []WayOverride {
+	overrides := []WayOverride{}
+	seen := map[*WayOverride]bool{}
+	for _, o := range s.byId {
+		seen[o] = true
+		overrides = append(overrides, *o)
+	}
+	for _, o := range s.byGeometry {
+		if !seen[o] {
+			overrides = append(overrides, *o)
+		}
+	}
+	return overrides
+}
+
+func (s *WayOverrideStore) save() error {
+	return writeWayOverrides(WAY_OVERRIDES_PATH, s.list())
+}
+
+func readWayOverrides(path string) ([]WayOverride, error) {
+	overrides := []WayOverride{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && path == WAY_OVERRIDES_PATH {
+		return overrides, nil
+	}
+	if err != nil {
+		return overrides, errors.Wrap(err, "could not read way overrides")
+	}
+	err = json.Unmarshal(data, &overrides)
+	return overrides, errors.Wrap(err, "could not parse way overrides")
+}
+
+func writeWayOverrides(path string, overrides []WayOverride) error {
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal way overrides")
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o775)
+	if err != nil {
+		return errors.Wrap(err, "could not make way overrides directory")
+	}
+	// write to a temporary file first so a power loss can't corrupt the data
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0o664)
+	if err != nil {
+		return errors.Wrap(err, "could not write way overrides")
+	}
+	return errors.Wrap(os.Rename(tmp, path), "could not replace way overrides")
+}
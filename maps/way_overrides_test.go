@@ -0,0 +1,84 @@
+package maps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+func airportRoad(t *testing.T, b *tb.Builder) maps.Way {
+	t.Helper()
+	offline, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range offline.Ways() {
+		if w.WayName() == "Airport Road" {
+			return w
+		}
+	}
+	t.Fatal("no Airport Road in the tile")
+	return maps.Way{}
+}
+
+// TestWayOverrideRoundTrip exports an override, clears it and imports it
+// again, once into the same tile and once into a tile without way ids.
+func TestWayOverrideRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := maps.WAY_OVERRIDES_PATH
+	maps.WAY_OVERRIDES_PATH = filepath.Join(dir, "way_overrides.json")
+	t.Cleanup(func() {
+		maps.WAY_OVERRIDES_PATH = path
+		maps.WayOverrides.Load()
+	})
+	maps.WayOverrides.Load()
+
+	way := airportRoad(t, tb.Fork())
+	mapSpeed := 80 * ms.KPH_TO_MS
+	overrideSpeed := 60 * ms.KPH_TO_MS
+	err := maps.WayOverrides.Edit(&way, func(o *maps.WayOverride) { o.MaxSpeed = overrideSpeed })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if way.MaxSpeed() != overrideSpeed {
+		t.Fatalf("edited max speed is %f, want %f", way.MaxSpeed(), overrideSpeed)
+	}
+
+	exported := filepath.Join(dir, "export.json")
+	err = maps.WayOverrides.Export(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Remove(maps.WAY_OVERRIDES_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maps.WayOverrides.Load()
+	if way.MaxSpeed() != mapSpeed {
+		t.Fatalf("cleared max speed is %f, want the map value %f", way.MaxSpeed(), mapSpeed)
+	}
+
+	err = maps.WayOverrides.Import(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if way.MaxSpeed() != overrideSpeed {
+		t.Errorf("imported max speed is %f, want %f", way.MaxSpeed(), overrideSpeed)
+	}
+	o, ok := maps.WayOverrides.Find(&way)
+	if !ok || o.Name != "Airport Road" {
+		t.Errorf("imported override %+v, want one named Airport Road", o)
+	}
+
+	// the same road from a tile generated without way ids
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, 0), tb.Pt(60, 600), 50)...).ID(0).Name("Airport Road").MaxSpeed(mapSpeed)
+	noId := airportRoad(t, b)
+	if noId.MaxSpeed() != overrideSpeed {
+		t.Errorf("max speed without a way id is %f, want %f", noId.MaxSpeed(), overrideSpeed)
+	}
+}
@@ -100,16 +100,17 @@ type Velocity struct {
 	TriggerDistance float32
 }
 
-func GetTargetVelocities(curvatures []m.Curvature, previousTargets []Velocity) (velocities []Velocity) {
+func GetTargetVelocities(curvatures []m.Curvature, previousTargets []Velocity, learned PathCurves) (velocities []Velocity) {
 	velocities = make([]Velocity, len(curvatures))
 	for i, curv := range curvatures {
 		if curv.Curvature == 0 {
 			continue
 		}
-		velocities[i].Velocity = math.Pow(float64(ms.Settings.MapCurveTargetLatA)/curv.Curvature, 1.0/2)
+		latA := learnedLatA(float64(ms.Settings.MapCurveTargetLatA), curv.Pos, learned)
+		velocities[i].Velocity = math.Pow(latA/curv.Curvature, 1.0/2)
 		velocities[i].Pos = curv.Pos
 		for _, t := range previousTargets {
-			if velocities[i].Pos.Equals(t.Pos) {
+			if velocities[i].Pos.SameNode(t.Pos) {
 				velocities[i].TriggerDistance = t.TriggerDistance
 			}
 		}
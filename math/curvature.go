@@ -16,12 +16,18 @@ func CalculateCurvature(a Position, b Position, c Position) Curvature {
 
 	sp := (lengthA + lengthB + lengthC) / 2
 
-	area := float32(m.Sqrt(float64(sp * (sp - lengthA) * (sp - lengthB) * (sp - lengthC))))
+	areaSq := float64(sp * (sp - lengthA) * (sp - lengthB) * (sp - lengthC))
 
 	lengthProd := lengthA * lengthB * lengthC
 	if lengthProd == 0 {
 		return Curvature{Pos: b}
 	}
+	// rounding can make the area of points on a straight line slightly
+	// negative, the arc is then just the straight line between a and c
+	if areaSq <= 0 {
+		return Curvature{Pos: b, ArcLength: float64(lengthB)}
+	}
+	area := float32(m.Sqrt(areaSq))
 
 	res := Curvature{Pos: b}
 	res.Curvature = float64((4 * area) / lengthProd)
@@ -0,0 +1,213 @@
+package math
+
+import (
+	m "math"
+
+	ms "pfeifer.dev/mapd/settings"
+)
+
+const (
+	ekfEast = iota
+	ekfNorth
+	ekfHeading
+	ekfSpeed
+	ekfSize
+)
+
+// PositionFilter is an extended Kalman filter that fuses gps fixes with the
+// car's speed, acceleration and yaw rate. The state is kept in a local east,
+// north plane in meters around an origin that moves with the car, along with
+// the heading (radians clockwise from north) and speed.
+type PositionFilter struct {
+	origin      Position
+	x           [ekfSize]float64
+	p           [ekfSize][ekfSize]float64
+	initialized bool
+}
+
+func (f *PositionFilter) Initialized() bool {
+	return f.initialized
+}
+
+func (f *PositionFilter) Reset() {
+	*f = PositionFilter{}
+}
+
+// Predict moves the estimate forward by dt seconds. accel is in m/s^2 and
+// yawRate is in rad/s with positive values turning left, like carState.
+func (f *PositionFilter) Predict(dt, accel, yawRate float64) {
+	if !f.initialized || dt <= 0 {
+		return
+	}
+	heading := f.x[ekfHeading]
+	speed := f.x[ekfSpeed]
+	sin, cos := m.Sin(heading), m.Cos(heading)
+
+	f.x[ekfEast] += speed * sin * dt
+	f.x[ekfNorth] += speed * cos * dt
+	f.x[ekfHeading] = wrapAngle(heading - yawRate*dt)
+	f.x[ekfSpeed] = max(speed+accel*dt, 0)
+
+	var jac [ekfSize][ekfSize]float64
+	for i := range ekfSize {
+		jac[i][i] = 1
+	}
+	jac[ekfEast][ekfHeading] = speed * cos * dt
+	jac[ekfEast][ekfSpeed] = sin * dt
+	jac[ekfNorth][ekfHeading] = -speed * sin * dt
+	jac[ekfNorth][ekfSpeed] = cos * dt
+
+	// P = F P F^T + Q
+	var fp [ekfSize][ekfSize]float64
+	for i := range ekfSize {
+		for j := range ekfSize {
+			for k := range ekfSize {
+				fp[i][j] += jac[i][k] * f.p[k][j]
+			}
+		}
+	}
+	for i := range ekfSize {
+		for j := range ekfSize {
+			sum := 0.0
+			for k := range ekfSize {
+				sum += fp[i][k] * jac[j][k]
+			}
+			f.p[i][j] = sum
+		}
+	}
+	f.p[ekfEast][ekfEast] += ms.EKF_POSITION_NOISE * dt
+	f.p[ekfNorth][ekfNorth] += ms.EKF_POSITION_NOISE * dt
+	f.p[ekfHeading][ekfHeading] += ms.EKF_HEADING_NOISE * dt
+	f.p[ekfSpeed][ekfSpeed] += ms.EKF_SPEED_NOISE * dt
+
+	f.recenter()
+}
+
+// UpdateGps corrects the estimate with a gps fix. The filter is reset to the
+// fix when it isn't initialized yet or the fix is too far from the estimate
+// to be trusted.
+func (f *PositionFilter) UpdateGps(loc Location, speedAccuracy, bearingAccuracyDeg float32) {
+	accuracy := float64(max(loc.HorizontalAccuracy, 1))
+	if !f.initialized || f.origin.DistanceTo(loc.Pos) > ms.EKF_MAX_ORIGIN_DISTANCE {
+		f.reset(loc, accuracy)
+		return
+	}
+	east, north := f.toLocal(loc.Pos)
+	if m.Hypot(east-f.x[ekfEast], north-f.x[ekfNorth]) > ms.EKF_RESET_DISTANCE {
+		f.reset(loc, accuracy)
+		return
+	}
+
+	f.update(ekfEast, east-f.x[ekfEast], accuracy*accuracy)
+	f.update(ekfNorth, north-f.x[ekfNorth], accuracy*accuracy)
+
+	speedStd := float64(speedAccuracy)
+	if speedStd <= 0 {
+		speedStd = ms.EKF_GPS_SPEED_STD
+	}
+	f.update(ekfSpeed, float64(loc.Speed)-f.x[ekfSpeed], speedStd*speedStd)
+
+	// gps bearing is meaningless when stopped
+	if loc.Speed > ms.EKF_MIN_BEARING_SPEED {
+		bearingStd := float64(bearingAccuracyDeg) * ms.TO_RADIANS
+		if bearingStd <= 0 {
+			bearingStd = ms.EKF_GPS_BEARING_STD
+		}
+		residual := wrapAngle(loc.BearingDeg*ms.TO_RADIANS - f.x[ekfHeading])
+		f.update(ekfHeading, residual, bearingStd*bearingStd)
+	}
+}
+
+// UpdateSpeed corrects the estimate with a speed measurement such as vEgo.
+func (f *PositionFilter) UpdateSpeed(speed, std float64) {
+	if !f.initialized {
+		return
+	}
+	f.update(ekfSpeed, speed-f.x[ekfSpeed], std*std)
+}
+
+// Location returns the current estimate. HorizontalAccuracy is the standard
+// deviation of the position along its least certain axis.
+func (f *PositionFilter) Location() Location {
+	bearing := f.x[ekfHeading] * ms.TO_DEGREES
+	if bearing < 0 {
+		bearing += 360
+	}
+	variance := max(f.p[ekfEast][ekfEast], f.p[ekfNorth][ekfNorth])
+	return Location{
+		Pos:                f.toPosition(f.x[ekfEast], f.x[ekfNorth]),
+		BearingDeg:         bearing,
+		HorizontalAccuracy: float32(m.Sqrt(variance)),
+		Speed:              float32(f.x[ekfSpeed]),
+		BearingAccuracyDeg: float32(m.Sqrt(f.p[ekfHeading][ekfHeading]) * ms.TO_DEGREES),
+	}
+}
+
+// update applies a scalar measurement of a single state element.
+func (f *PositionFilter) update(idx int, residual, variance float64) {
+	s := f.p[idx][idx] + variance
+	if s <= 0 {
+		return
+	}
+	var gain [ekfSize]float64
+	for i := range ekfSize {
+		gain[i] = f.p[i][idx] / s
+	}
+	for i := range ekfSize {
+		f.x[i] += gain[i] * residual
+	}
+	f.x[ekfHeading] = wrapAngle(f.x[ekfHeading])
+	f.x[ekfSpeed] = max(f.x[ekfSpeed], 0)
+
+	// P = (I - K H) P
+	row := f.p[idx]
+	for i := range ekfSize {
+		for j := range ekfSize {
+			f.p[i][j] -= gain[i] * row[j]
+		}
+	}
+}
+
+func (f *PositionFilter) reset(loc Location, accuracy float64) {
+	f.origin = NewPosition(loc.Pos.Lat(), loc.Pos.Lon())
+	f.x = [ekfSize]float64{0, 0, loc.BearingDeg * ms.TO_RADIANS, float64(loc.Speed)}
+	f.p = [ekfSize][ekfSize]float64{}
+	f.p[ekfEast][ekfEast] = accuracy * accuracy
+	f.p[ekfNorth][ekfNorth] = accuracy * accuracy
+	f.p[ekfHeading][ekfHeading] = m.Pi * m.Pi / 4
+	f.p[ekfSpeed][ekfSpeed] = ms.EKF_GPS_SPEED_STD * ms.EKF_GPS_SPEED_STD
+	f.initialized = true
+}
+
+// recenter moves the origin to the current estimate so the flat earth
+// approximation stays accurate.
+func (f *PositionFilter) recenter() {
+	if m.Hypot(f.x[ekfEast], f.x[ekfNorth]) < ms.EKF_MAX_ORIGIN_DISTANCE/2 {
+		return
+	}
+	f.origin = f.toPosition(f.x[ekfEast], f.x[ekfNorth])
+	f.x[ekfEast] = 0
+	f.x[ekfNorth] = 0
+}
+
+func (f *PositionFilter) toLocal(pos Position) (east, north float64) {
+	north = (pos.Lat() - f.origin.Lat()) * ms.TO_RADIANS * ms.R
+	east = (pos.Lon() - f.origin.Lon()) * ms.TO_RADIANS * ms.R * m.Cos(f.origin.LatRad())
+	return east, north
+}
+
+func (f *PositionFilter) toPosition(east, north float64) Position {
+	lat := f.origin.Lat() + north/ms.R*ms.TO_DEGREES
+	lon := f.origin.Lon() + east/(ms.R*m.Cos(f.origin.LatRad()))*ms.TO_DEGREES
+	return NewPosition(lat, lon)
+}
+
+func wrapAngle(angle float64) float64 {
+	for angle > m.Pi {
+		angle -= 2 * m.Pi
+	}
+	for angle < -m.Pi {
+		angle += 2 * m.Pi
+	}
+	return angle
+}
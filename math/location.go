@@ -0,0 +1,12 @@
+package math
+
+// Location is a position estimate along with the details needed to match it
+// to a road. It can come directly from a position source or from the
+// position filter.
+type Location struct {
+	Pos                Position
+	BearingDeg         float64
+	HorizontalAccuracy float32 // meters
+	Speed              float32 // meters/second
+	BearingAccuracyDeg float32 // 0 when unknown
+}
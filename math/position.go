@@ -11,6 +11,12 @@ func NewPosition(latDeg, lonDeg float64) Position {
 	return Position{latitudeDeg: latDeg, longitudeDeg: lonDeg}
 }
 
+// NewNodePosition creates a position for an OSM node. The node id is carried
+// along so way connections and path points can be matched by topology.
+func NewNodePosition(latDeg, lonDeg float64, nodeId int64) Position {
+	return Position{latitudeDeg: latDeg, longitudeDeg: lonDeg, nodeId: nodeId}
+}
+
 func PosFromLocation(loc log.GpsLocationData) Position {
 	return Position{latitudeDeg: loc.Latitude(), longitudeDeg: loc.Longitude()}
 }
@@ -18,6 +24,7 @@ func PosFromLocation(loc log.GpsLocationData) Position {
 type Position struct {
 	latitudeDeg  float64
 	longitudeDeg float64
+	nodeId       int64
 }
 
 func (p *Position) LatRad() float64 {
@@ -61,10 +68,35 @@ func (p *Position) Dot(other Position) float64 {
 	return p.Lat()*other.Lat() + p.Lon()*other.Lon()
 }
 
+// NodeID returns the OSM node id of the position, or 0 if it is not a node
+// or came from a tile generated without node ids.
+func (p *Position) NodeID() int64 {
+	return p.nodeId
+}
+
 func (p *Position) Equals(other Position) bool {
 	return p.Lat() == other.Lat() && p.Lon() == other.Lon()
 }
 
+// SameNode reports whether both positions refer to the same OSM node. When
+// either side has no node id it falls back to comparing coordinates.
+func (p *Position) SameNode(other Position) bool {
+	if p.nodeId != 0 && other.nodeId != 0 {
+		return p.nodeId == other.nodeId
+	}
+	return p.Equals(other)
+}
+
+// Project returns the position reached by travelling distance meters from p
+// along the given bearing in degrees.
+func (p *Position) Project(distance float64, bearingDeg float64) Position {
+	angular := distance / ms.R
+	bearing := bearingDeg * ms.TO_RADIANS
+	lat := m.Asin(m.Sin(p.LatRad())*m.Cos(angular) + m.Cos(p.LatRad())*m.Sin(angular)*m.Cos(bearing))
+	lon := p.LonRad() + m.Atan2(m.Sin(bearing)*m.Sin(angular)*m.Cos(p.LatRad()), m.Cos(angular)-m.Sin(p.LatRad())*m.Sin(lat))
+	return Position{latitudeDeg: lat * ms.TO_DEGREES, longitudeDeg: lon * ms.TO_DEGREES}
+}
+
 func (p *Position) VectorTo(end Position) Vector {
 	res := Vector{}
 	dlon := end.LonRad() - p.LonRad()
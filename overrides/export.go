@@ -0,0 +1,159 @@
+package overrides
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+const (
+	FORMAT_TEXT    = "text"
+	FORMAT_GEOJSON = "geojson"
+	FORMAT_GPX     = "gpx"
+)
+
+// Export writes events to w in the given format.
+func Export(w io.Writer, events []Event, format string) error {
+	switch format {
+	case FORMAT_TEXT:
+		return WriteText(w, events)
+	case FORMAT_GEOJSON:
+		return WriteGeoJSON(w, events)
+	case FORMAT_GPX:
+		return WriteGPX(w, events)
+	}
+	return errors.Errorf("unknown override export format %q", format)
+}
+
+// OsmUrl links to the way on openstreetmap.org so it can be reviewed and
+// edited.
+func (e *Event) OsmUrl() string {
+	return fmt.Sprintf("https://www.openstreetmap.org/way/%d", e.WayId)
+}
+
+func (e *Event) Summary() string {
+	name := e.WayName
+	if name == "" {
+		name = e.WayRef
+	}
+	if name == "" {
+		name = "unnamed way"
+	}
+	return fmt.Sprintf("%s override on %s: map limit %s, suggested %s, driver chose %s",
+		e.Type, name, formatSpeed(e.MapLimit), formatSpeed(e.SuggestedSpeed), formatSpeed(e.ChosenSpeed))
+}
+
+func formatSpeed(speed float32) string {
+	if speed <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%.0f km/h (%.0f mph)", speed/ms.KPH_TO_MS, speed/ms.MPH_TO_MS)
+}
+
+func WriteText(w io.Writer, events []Event) error {
+	for _, e := range events {
+		_, err := fmt.Fprintf(w, "%s  %.6f,%.6f  %s  %s\n", e.Time.Local().Format(time.DateTime), e.Latitude, e.Longitude, e.Summary(), e.OsmUrl())
+		if err != nil {
+			return errors.Wrap(err, "could not write override events")
+		}
+	}
+	return nil
+}
+
+type geoJSONFeature struct {
+	Type       string         `json:"type"`
+	Geometry   geoJSONPoint   `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type geoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func WriteGeoJSON(w io.Writer, events []Event) error {
+	features := []geoJSONFeature{}
+	for _, e := range events {
+		features = append(features, geoJSONFeature{
+			Type: "Feature",
+			// geojson coordinates are longitude first
+			Geometry: geoJSONPoint{Type: "Point", Coordinates: [2]float64{e.Longitude, e.Latitude}},
+			Properties: map[string]any{
+				"time":            e.Time,
+				"type":            e.Type,
+				"way_id":          e.WayId,
+				"way_name":        e.WayName,
+				"way_ref":         e.WayRef,
+				"map_limit":       e.MapLimit,
+				"suggested_speed": e.SuggestedSpeed,
+				"chosen_speed":    e.ChosenSpeed,
+				"osm_url":         e.OsmUrl(),
+			},
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(map[string]any{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+	return errors.Wrap(err, "could not write geojson")
+}
+
+type gpx struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+type gpxWaypoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+	Name string  `xml:"name"`
+	Desc string  `xml:"desc"`
+	Link gpxLink `xml:"link"`
+	Type string  `xml:"type"`
+}
+
+type gpxLink struct {
+	Href string `xml:"href,attr"`
+}
+
+func WriteGPX(w io.Writer, events []Event) error {
+	doc := gpx{
+		Version: "1.1",
+		Creator: "mapd",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+	}
+	for _, e := range events {
+		doc.Waypoints = append(doc.Waypoints, gpxWaypoint{
+			Lat:  e.Latitude,
+			Lon:  e.Longitude,
+			Time: e.Time.UTC().Format(time.RFC3339),
+			Name: fmt.Sprintf("way %d", e.WayId),
+			Desc: e.Summary(),
+			Link: gpxLink{Href: e.OsmUrl()},
+			Type: e.Type,
+		})
+	}
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.Wrap(err, "could not write gpx")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(doc)
+	if err != nil {
+		return errors.Wrap(err, "could not write gpx")
+	}
+	_, err = io.WriteString(w, "\n")
+	return errors.Wrap(err, "could not write gpx")
+}
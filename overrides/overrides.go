@@ -0,0 +1,94 @@
+package overrides
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/params"
+)
+
+var OVERRIDES_PATH = filepath.Join(params.BasePath, "overrides.jsonl")
+
+const (
+	TYPE_GAS       = "gas"       // driver pressed the gas past the suggested speed limit
+	TYPE_SET_SPEED = "set_speed" // driver changed the set speed away from the suggested speed limit
+)
+
+// Event is a place where the driver chose a different speed than the speed
+// limit mapd suggested. These usually point at a wrong or missing maxspeed in
+// open street maps.
+type Event struct {
+	Time           time.Time `json:"time"`
+	Type           string    `json:"type"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	WayId          int64     `json:"way_id"`
+	WayName        string    `json:"way_name"`
+	WayRef         string    `json:"way_ref"`
+	MapLimit       float32   `json:"map_limit"`       // m/s, 0 when the way has no maxspeed
+	SuggestedSpeed float32   `json:"suggested_speed"` // m/s
+	ChosenSpeed    float32   `json:"chosen_speed"`    // m/s
+}
+
+// Append adds an event to the end of the override log. The log is stored as
+// one json object per line so a partial write only loses the last event.
+func Append(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal override event")
+	}
+	err = os.MkdirAll(filepath.Dir(OVERRIDES_PATH), 0o775)
+	if err != nil {
+		return errors.Wrap(err, "could not make overrides directory")
+	}
+	f, err := os.OpenFile(OVERRIDES_PATH, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o664)
+	if err != nil {
+		return errors.Wrap(err, "could not open overrides file")
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return errors.Wrap(err, "could not write override event")
+}
+
+// Read returns all events in the override log. Lines that can't be parsed are
+// skipped.
+func Read() ([]Event, error) {
+	events := []Event{}
+	f, err := os.Open(OVERRIDES_PATH)
+	if os.IsNotExist(err) {
+		return events, nil
+	}
+	if err != nil {
+		return events, errors.Wrap(err, "could not open overrides file")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		event := Event{}
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			slog.Warn("skipping invalid override event", "error", err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, errors.Wrap(scanner.Err(), "could not read overrides file")
+}
+
+// Clear removes the override log.
+func Clear() error {
+	err := os.Remove(OVERRIDES_PATH)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "could not remove overrides file")
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"math"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+type localPoint struct {
+	x float64 // meters ahead
+	y float64 // meters to the right
+}
+
+// PathAgreement compares the trajectory predicted by the model with the map
+// path ahead. It is used to ignore vision curves on a confidently matched
+// straight road and to detect when we are leaving the mapped road.
+type PathAgreement struct {
+	Score         float32 // 0 to 1, -1 when there is no map path to compare against
+	Leaving       bool
+	mapStraight   bool
+	leavingFrames int
+}
+
+// Update compares a modelV2 message against the map path ahead of the
+// current position.
+func (p *PathAgreement) Update(model log.ModelDataV2, s *State) {
+	p.Score = -1
+	p.mapStraight = false
+	positionData, err := model.Position()
+	if err != nil {
+		return
+	}
+	xs, err := positionData.X()
+	if err != nil {
+		return
+	}
+	ys, err := positionData.Y()
+	if err != nil || ys.Len() != xs.Len() || xs.Len() == 0 {
+		return
+	}
+
+	maxDistance := float32(min(xs.At(xs.Len()-1), ms.PATH_AGREEMENT_DISTANCE))
+	mapPath := localMapPath(s, maxDistance)
+	if len(mapPath) < 2 {
+		p.leavingFrames = 0
+		p.Leaving = false
+		return
+	}
+	mapLength := 0.0
+	p.mapStraight = true
+	for i, pt := range mapPath {
+		if math.Abs(pt.y) > ms.PATH_STRAIGHT_OFFSET {
+			p.mapStraight = false
+		}
+		if i > 0 {
+			mapLength += math.Hypot(pt.x-mapPath[i-1].x, pt.y-mapPath[i-1].y)
+		}
+	}
+
+	sumSq := 0.0
+	count := 0
+	lastError := 0.0
+	// both paths are compared relative to where they start so an offset
+	// from the center line, like being in the right lane, isn't a disagreement
+	startY := float64(ys.At(0))
+	for i := range xs.Len() {
+		pt := localPoint{x: float64(xs.At(i)), y: float64(ys.At(i)) - startY}
+		if pt.x > mapLength {
+			break
+		}
+		lastError = distanceToPath(pt, mapPath)
+		sumSq += lastError * lastError
+		count++
+	}
+	if count == 0 {
+		return
+	}
+	rms := math.Sqrt(sumSq / float64(count))
+	p.Score = float32(math.Exp(-0.5 * math.Pow(rms/ms.PATH_AGREEMENT_STD, 2)))
+
+	threshold := float64(s.CurrentWay.Way.Width()/2) + ms.PATH_LEAVING_MARGIN
+	if lastError > threshold {
+		p.leavingFrames++
+	} else {
+		p.leavingFrames = 0
+	}
+	p.Leaving = p.leavingFrames >= ms.PATH_LEAVING_FRAMES
+}
+
+// VisionWeight returns how much of the vision curve should be used. Vision
+// curves are down weighted when the map confidently says the road ahead is
+// straight, unless the model has been consistently leaving the road.
+func (p *PathAgreement) VisionWeight(s *State) float32 {
+	if p.Score < 0 || !p.mapStraight || p.Leaving || !confidentMatch(s) {
+		return 1
+	}
+	return p.Score
+}
+
+func confidentMatch(s *State) bool {
+	if s.DeadReckoning.Active {
+		return false
+	}
+	switch s.CurrentWay.SelectionType {
+	case custom.WaySelectionType_current, custom.WaySelectionType_predicted:
+		return true
+	case custom.WaySelectionType_hmm:
+		return s.CurrentWay.Confidence >= ms.PATH_MIN_HMM_CONFIDENCE
+	}
+	return false
+}
+
+// localMapPath returns the map path ahead of the current position in meters
+// relative to the road direction at the current position.
+func localMapPath(s *State, maxDistance float32) []localPoint {
+	path, err := s.CurrentWay.Way.NodesAhead(s.Position, s.CurrentWay.OnWay.IsForward)
+	if err != nil {
+		return nil
+	}
+	for _, next := range s.NextWays {
+		nodes := next.Way.Nodes()
+		for i := 1; i < len(nodes); i++ {
+			idx := i
+			if !next.IsForward {
+				idx = len(nodes) - 1 - i
+			}
+			path = append(path, nodes[idx])
+		}
+	}
+
+	origin := path[0]
+	heading := 0.0
+	for _, node := range path[1:] {
+		if origin.DistanceTo(node) > 1 {
+			vec := origin.VectorTo(node)
+			heading = vec.Bearing()
+			break
+		}
+	}
+
+	sin, cos := math.Sin(heading), math.Cos(heading)
+	local := []localPoint{}
+	distance := float32(0)
+	for i, pos := range path {
+		if i > 0 {
+			distance += path[i-1].DistanceTo(pos)
+		}
+		north := (pos.Lat() - origin.Lat()) * ms.TO_RADIANS * ms.R
+		east := (pos.Lon() - origin.Lon()) * ms.TO_RADIANS * ms.R * math.Cos(origin.LatRad())
+		local = append(local, localPoint{
+			x: east*sin + north*cos,
+			y: east*cos - north*sin,
+		})
+		if distance > maxDistance {
+			break
+		}
+	}
+	return local
+}
+
+func distanceToPath(pt localPoint, path []localPoint) float64 {
+	best := math.Inf(1)
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		dx, dy := b.x-a.x, b.y-a.y
+		lengthSq := dx*dx + dy*dy
+		t := 0.0
+		if lengthSq > 0 {
+			t = math.Min(math.Max(((pt.x-a.x)*dx+(pt.y-a.y)*dy)/lengthSq, 0), 1)
+		}
+		best = math.Min(best, math.Hypot(pt.x-(a.x+t*dx), pt.y-(a.y+t*dy)))
+	}
+	return best
+}
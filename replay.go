@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
+	"pfeifer.dev/mapd/cli"
+	"pfeifer.dev/mapd/maps"
+	"pfeifer.dev/mapd/overrides"
+	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+)
+
+var (
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Replay runs recorded openpilot logs through the mapd loop. Like the service
+// each event is handled when it arrives, at its log time, and the outputs are
+// sent at the output rate. The log times are the clock so replaying a drive
+// twice gives the same outputs.
+func Replay(opts cli.ReplayOptions) error {
+	if opts.Format != cli.REPLAY_FORMAT_JSON && opts.Format != cli.REPLAY_FORMAT_CAPNP {
+		return fmt.Errorf("unknown replay format: %s", opts.Format)
+	}
+
+	ms.Settings.Default()
+	if opts.SettingsFile != "" {
+		data, err := os.ReadFile(opts.SettingsFile)
+		if err != nil {
+			return errors.Wrap(err, "could not read settings file")
+		}
+		if !ms.Settings.Unmarshal(data) {
+			return fmt.Errorf("could not parse settings file: %s", opts.SettingsFile)
+		}
+	}
+
+	// anything mapd persists goes to a scratch directory so a replay neither
+	// reads nor changes the state of the device it runs on
+	scratch, err := os.MkdirTemp("", "mapd-replay")
+	if err != nil {
+		return errors.Wrap(err, "could not create replay directory")
+	}
+	defer os.RemoveAll(scratch)
+	overrides.OVERRIDES_PATH = filepath.Join(scratch, filepath.Base(overrides.OVERRIDES_PATH))
+	LEARNED_CURVES_PATH = filepath.Join(scratch, filepath.Base(LEARNED_CURVES_PATH))
+	maps.WAY_OVERRIDES_PATH = filepath.Join(scratch, filepath.Base(maps.WAY_OVERRIDES_PATH))
+	maps.WayOverrides.Load()
+	if opts.WayOverridesFile != "" {
+		err = maps.WayOverrides.Import(opts.WayOverridesFile)
+		if err != nil {
+			return errors.Wrap(err, "could not import way overrides")
+		}
+	}
+
+	out, err := os.Create(opts.OutputFile)
+	if err != nil {
+		return errors.Wrap(err, "could not create output file")
+	}
+	defer out.Close()
+	buf := bufio.NewWriter(out)
+	var w io.Writer = buf
+	if opts.Format == cli.REPLAY_FORMAT_JSON {
+		w = &jsonEventWriter{enc: json.NewEncoder(buf)}
+	}
+
+	r := replayer{writer: w, tiles: opts.TilesDirectory}
+	for _, path := range opts.Logs {
+		err = r.replayFile(path)
+		if err != nil {
+			return err
+		}
+	}
+	r.finish()
+	cereal.SetTimeSource(nil)
+
+	slog.Info("Finished replay", "events", r.events, "iterations", r.iterations, "output", opts.OutputFile)
+	return errors.Wrap(buf.Flush(), "could not write output file")
+}
+
+type replayer struct {
+	writer     io.Writer
+	tiles      string
+	loop       *Loop
+	positions  cereal.PositionSources
+	clock      *utils.ManualClock
+	mono       uint64
+	nextTick   uint64
+	events     int
+	iterations int
+}
+
+// start sets up the loop at the time of the first event so everything that
+// is timed from startup behaves like the service did.
+func (r *replayer) start(mono uint64) {
+	r.mono = mono
+	r.nextTick = mono
+	r.clock = utils.NewManualClock(time.Unix(0, int64(mono)))
+	r.positions.Clock = r.clock
+	cereal.SetTimeSource(func() uint64 { return r.mono })
+
+	state := State{Clock: r.clock}
+	state.Init()
+	pub := cereal.NewWriterPublisher(r.writer, cereal.MapdOutCreator)
+	state.Publisher = &pub
+	extendedState := ExtendedState{
+		Pub:   cereal.NewWriterPublisher(r.writer, cereal.MapdExtendedOutCreator),
+		state: &state,
+	}
+	// timings measured on the log clock are always zero so outputs don't
+	// depend on the machine the replay runs on
+	extendedState.Timings.Clock = r.clock
+
+	tileLoader := maps.NewTileLoader(maps.NewTileManager(maps.OfflineSettings{OutputDirectory: r.tiles}, ms.TILE_CACHE_SIZE))
+	tileLoader.Clock = r.clock
+	tileLoader.StartSync()
+
+	r.loop = &Loop{State: &state, Extended: &extendedState, Tiles: tileLoader}
+}
+
+func (r *replayer) replayFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "could not open log")
+	}
+	defer f.Close()
+
+	reader, closeReader, err := decompress(f)
+	if err != nil {
+		return errors.Wrapf(err, "could not read log %s", path)
+	}
+	defer closeReader()
+
+	decoder := capnp.NewDecoder(reader)
+	for {
+		msg, err := decoder.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not decode log %s", path)
+		}
+		msg.ResetReadLimit(math.MaxUint64)
+		event, err := log.ReadRootEvent(msg)
+		if err != nil {
+			return errors.Wrapf(err, "could not read event in log %s", path)
+		}
+		r.handle(event)
+	}
+}
+
+func (r *replayer) handle(event log.Event) {
+	mono := event.LogMonoTime()
+	if r.loop == nil {
+		r.start(mono)
+	}
+	// send every output that would have gone out before this event
+	for mono >= r.nextTick {
+		r.tick()
+	}
+	r.setTime(mono)
+	if r.dispatch(event) {
+		r.events++
+	}
+}
+
+// dispatch hands an event to the loop the same way the service does, it
+// returns false for events mapd doesn't read.
+func (r *replayer) dispatch(event log.Event) bool {
+	switch event.Which() {
+	case log.Event_Which_mapdIn:
+		input, err := event.MapdIn()
+		if err != nil || !replayableInput(input.Type()) {
+			return false
+		}
+		r.loop.HandleInput(input)
+	case log.Event_Which_carState:
+		carState, err := event.CarState()
+		if err != nil {
+			return false
+		}
+		r.loop.HandleCarState(carState)
+	case log.Event_Which_liveParameters:
+		liveParams, err := event.LiveParameters()
+		if err != nil {
+			return false
+		}
+		r.loop.HandleLiveParameters(liveParams)
+	case log.Event_Which_modelV2:
+		model, err := event.ModelV2()
+		if err != nil {
+			return false
+		}
+		r.loop.HandleModel(model)
+	case log.Event_Which_gpsLocation:
+		r.handlePosition(cereal.SOURCE_GPS, event)
+	case log.Event_Which_gpsLocationExternal:
+		r.handlePosition(cereal.SOURCE_GPS_EXTERNAL, event)
+	case log.Event_Which_liveLocationKalmanDEPRECATED:
+		r.handlePosition(cereal.SOURCE_LLK, event)
+	case log.Event_Which_livePose:
+		r.handlePosition(cereal.SOURCE_LIVE_POSE, event)
+	default:
+		return false
+	}
+	return true
+}
+
+func (r *replayer) handlePosition(source string, event log.Event) {
+	fix, err := positionFix(source, event)
+	if err != nil {
+		slog.Debug("could not read logged position", "source", source, "error", err)
+		return
+	}
+	fix, success := r.positions.Push(fix)
+	if success {
+		r.loop.HandlePosition(fix)
+	}
+}
+
+func (r *replayer) setTime(mono uint64) {
+	r.mono = mono
+	r.clock.Set(time.Unix(0, int64(mono)))
+}
+
+// tick sends the outputs at the time of the next output tick.
+func (r *replayer) tick() {
+	r.setTime(r.nextTick)
+	r.loop.Send()
+	r.iterations++
+	r.nextTick += uint64(ms.Settings.OutputInterval().Nanoseconds())
+}
+
+// finish sends the outputs of the events after the last tick.
+func (r *replayer) finish() {
+	if r.loop != nil {
+		r.tick()
+	}
+}
+
+// decompress detects bz2 and zstd compressed logs from their magic bytes.
+func decompress(f io.Reader) (io.Reader, func(), error) {
+	reader := bufio.NewReader(f)
+	magic, _ := reader.Peek(len(zstdMagic))
+	switch {
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(reader), func() {}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not read zstd log")
+		}
+		return decoder, decoder.Close, nil
+	}
+	return reader, func() {}, nil
+}
+
+// replayableInput filters out inputs that reach outside of the replay, like
+// downloading maps or reading and writing the device settings.
+func replayableInput(t custom.MapdInputType) bool {
+	switch t {
+	case custom.MapdInputType_download,
+		custom.MapdInputType_cancelDownload,
+		custom.MapdInputType_saveSettings,
+		custom.MapdInputType_reloadSettings,
+		custom.MapdInputType_loadPersistentSettings,
+		custom.MapdInputType_importWayOverrides,
+		custom.MapdInputType_exportWayOverrides:
+		return false
+	}
+	return true
+}
+
+func positionFix(source string, event log.Event) (cereal.PositionFix, error) {
+	switch source {
+	case cereal.SOURCE_GPS:
+		loc, err := event.GpsLocation()
+		return cereal.GpsFix(source, loc), err
+	case cereal.SOURCE_GPS_EXTERNAL:
+		loc, err := event.GpsLocationExternal()
+		return cereal.GpsFix(source, loc), err
+	case cereal.SOURCE_LLK:
+		llk, err := event.LiveLocationKalmanDEPRECATED()
+		return cereal.LiveLocationKalmanFix(llk), err
+	case cereal.SOURCE_LIVE_POSE:
+		pose, err := event.LivePose()
+		return cereal.LivePoseFix(pose), err
+	}
+	return cereal.PositionFix{}, fmt.Errorf("unknown position source: %s", source)
+}
+
+// jsonEventWriter converts each marshaled output event to a line of json.
+type jsonEventWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonEventWriter) Write(b []byte) (int, error) {
+	msg, err := capnp.Unmarshal(b)
+	if err != nil {
+		return 0, err
+	}
+	msg.ResetReadLimit(math.MaxUint64)
+	event, err := log.ReadRootEvent(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	line := map[string]any{"logMonoTime": event.LogMonoTime()}
+	switch event.Which() {
+	case log.Event_Which_mapdOut:
+		output, err := event.MapdOut()
+		if err != nil {
+			return 0, err
+		}
+		line["mapdOut"] = cereal.StructFields(output)
+	case log.Event_Which_mapdExtendedOut:
+		output, err := event.MapdExtendedOut()
+		if err != nil {
+			return 0, err
+		}
+		line["mapdExtendedOut"] = cereal.StructFields(output)
+	default:
+		return 0, fmt.Errorf("unexpected output event: %s", event.Which())
+	}
+	return len(b), w.enc.Encode(line)
+}
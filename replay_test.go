@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompressZstd(t *testing.T) {
+	log := bytes.Repeat([]byte("logged event "), 1000)
+	compressed := bytes.Buffer{}
+	encoder, err := zstd.NewWriter(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = encoder.Write(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, closeReader, err := decompress(bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(reader)
+	closeReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, log) {
+		t.Errorf("decompressed %d bytes, want the %d logged", len(data), len(log))
+	}
+
+	// a truncated log is an error instead of looking like the end of the log
+	truncated := compressed.Bytes()[:compressed.Len()/2]
+	reader, closeReader, err = decompress(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeReader()
+	_, err = io.ReadAll(reader)
+	if err == nil {
+		t.Error("reading a truncated log succeeded")
+	}
+}
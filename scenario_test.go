@@ -0,0 +1,389 @@
+package main
+
+import (
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"pfeifer.dev/mapd/cereal"
+	"pfeifer.dev/mapd/cereal/car"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
+	"pfeifer.dev/mapd/cereal/offline"
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+	"pfeifer.dev/mapd/overrides"
+	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+)
+
+// scenarioRoad runs north from the origin. The first way is signed 50 km/h
+// and continues into a way signed 80 km/h 2 km ahead.
+func scenarioRoad() *tb.Builder {
+	b := tb.New(tb.Origin)
+	b.Way(tb.Line(tb.Pt(0, -1000), tb.Pt(0, 2000), 100)...).
+		Name("First Street").Highway(offline.HighwayType_primary).Lanes(2).MaxSpeed(50 * ms.KPH_TO_MS)
+	b.Way(tb.Line(tb.Pt(0, 2000), tb.Pt(0, 4000), 100)...).
+		Name("Second Street").Highway(offline.HighwayType_primary).Lanes(2).MaxSpeed(80 * ms.KPH_TO_MS)
+	return b
+}
+
+// scenarioStep holds the car inputs for a stretch of driving and the speed
+// mapd should suggest once it is over.
+type scenarioStep struct {
+	duration time.Duration
+	vEgo     float32 // m/s
+	vCruise  float32 // km/h
+	gas      bool
+	want     float32 // km/h
+	limiter  string  // the speed limiter the suggested speed comes from, empty for the cruise speed
+	reason   custom.SpeedReason
+}
+
+type scenario struct {
+	name     string
+	settings func(s *ms.MapdSettings)
+	limiters []SpeedLimiter // registered for this scenario only
+	steps    []scenarioStep
+}
+
+func TestSpeedLimitScenarios(t *testing.T) {
+	scenarios := []scenario{
+		{
+			name: "limit is applied",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "limit control disabled",
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 100},
+			},
+		},
+		{
+			// the speed limit only lowers a set cruise speed
+			name: "cruise speed not set",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 0, want: 0},
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "offset is added to the limit",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitOffset = 5 * ms.KPH_TO_MS
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 55, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "press gas to accept",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitChangeRequiresAccept = true
+				s.PressGasToAcceptSpeedLimit = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 100},
+				{duration: 100 * time.Millisecond, vEgo: 20, vCruise: 100, gas: true, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+				{duration: 2 * time.Second, vEgo: 14, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "accept times out",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitChangeRequiresAccept = true
+				s.PressGasToAcceptSpeedLimit = true
+				s.AcceptSpeedLimitTimeout = 1
+			},
+			steps: []scenarioStep{
+				{duration: 3 * time.Second, vEgo: 20, vCruise: 100, want: 100},
+				{duration: 100 * time.Millisecond, vEgo: 20, vCruise: 100, gas: true, want: 100},
+			},
+		},
+		{
+			name: "adjust set speed to accept",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitChangeRequiresAccept = true
+				s.AdjustSetSpeedToAcceptSpeedLimit = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 100},
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 90, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "press gas to override",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.PressGasToOverrideSpeedLimit = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 14, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+				{duration: 500 * time.Millisecond, vEgo: 20, vCruise: 100, gas: true, want: 72, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_gasOverride},
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 72, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_gasOverride},
+				// changing the set speed gives control back to the limit
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 90, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "enable speed",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitUseEnableSpeed = true
+				s.HoldSpeedLimitWhileChangingSetSpeed = false
+				s.EnableSpeed = 100 * ms.KPH_TO_MS
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 90, want: 90},
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "speed up for the next limit",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedUpForNextSpeedLimit = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+				// 40 m before the 80 km/h way
+				{duration: 96 * time.Second, vEgo: 20, vCruise: 100, want: 80, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_nextSpeedLimit},
+			},
+		},
+		{
+			name: "next limit awaits acceptance",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+				s.SpeedLimitChangeRequiresAccept = true
+				s.PressGasToAcceptSpeedLimit = true
+			},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 100},
+				{duration: 100 * time.Millisecond, vEgo: 20, vCruise: 100, gas: true, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+				// onto the 80 km/h way, the accepted limit is kept until the new one is accepted
+				{duration: 100 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_awaitingAcceptance},
+				{duration: 100 * time.Millisecond, vEgo: 20, vCruise: 100, gas: true, want: 80, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+		{
+			name: "registered limiter",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+			},
+			limiters: []SpeedLimiter{fixedLimiter{name: "fixed", speed: 30 * ms.KPH_TO_MS}},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 30, limiter: "fixed", reason: custom.SpeedReason_other},
+			},
+		},
+		{
+			name: "registered limiter above the limit",
+			settings: func(s *ms.MapdSettings) {
+				s.SpeedLimitControlEnabled = true
+			},
+			limiters: []SpeedLimiter{fixedLimiter{name: "fixed", speed: 70 * ms.KPH_TO_MS}},
+			steps: []scenarioStep{
+				{duration: 2 * time.Second, vEgo: 20, vCruise: 100, want: 50, limiter: LIMITER_SPEED_LIMIT, reason: custom.SpeedReason_speedLimit},
+			},
+		},
+	}
+
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			registerScenarioLimiters(t, sc.limiters)
+			h := newScenarioHarness(t, sc.settings)
+			elapsed := time.Duration(0)
+			for i, step := range sc.steps {
+				h.drive(step)
+				elapsed += step.duration
+				got := h.loop.State.SuggestedSpeed() * ms.MS_TO_KPH
+				if math.Abs(float64(got-step.want)) > 0.5 {
+					t.Errorf("step %d at %s: suggested speed is %.1f km/h, want %.1f km/h", i, elapsed, got, step.want)
+				}
+				if h.loop.State.ActiveLimiter != step.limiter {
+					t.Errorf("step %d at %s: active limiter is %q, want %q", i, elapsed, h.loop.State.ActiveLimiter, step.limiter)
+				}
+				if h.loop.State.ActiveReason != step.reason {
+					t.Errorf("step %d at %s: reason is %s, want %s", i, elapsed, h.loop.State.ActiveReason, step.reason)
+				}
+				if len(h.loop.State.Limiters) != len(speedLimiters) {
+					t.Errorf("step %d at %s: got %d limiter results, want one for each of the %d limiters", i, elapsed, len(h.loop.State.Limiters), len(speedLimiters))
+				}
+			}
+		})
+	}
+}
+
+// fixedLimiter always wants the same speed, like a limiter added by a fork.
+type fixedLimiter struct {
+	name  string
+	speed float32
+}
+
+func (l fixedLimiter) Name() string                           { return l.name }
+func (l fixedLimiter) Enabled(s *State) bool                  { return true }
+func (l fixedLimiter) TargetSpeed(s *State) float32           { return l.speed }
+func (l fixedLimiter) Confidence(s *State) float32            { return 1 }
+func (l fixedLimiter) Reason(s *State) string                 { return "fixed speed" }
+func (l fixedLimiter) ReasonCode(s *State) custom.SpeedReason { return custom.SpeedReason_other }
+func (l fixedLimiter) AppliesWithoutCruise() bool             { return true }
+func (l fixedLimiter) Candidates(s *State) []SpeedCandidate {
+	return []SpeedCandidate{{Reason: custom.SpeedReason_other, Speed: l.speed}}
+}
+
+// registerScenarioLimiters registers limiters until the scenario is over.
+func registerScenarioLimiters(t *testing.T, limiters []SpeedLimiter) {
+	t.Helper()
+	registered := slices.Clone(speedLimiters)
+	t.Cleanup(func() {
+		speedLimiters = registered
+	})
+	for _, limiter := range limiters {
+		RegisterSpeedLimiter(limiter)
+	}
+}
+
+// scenarioHarness runs the mapd loop on a manual clock against a tile with
+// the scenario road.
+type scenarioHarness struct {
+	t      *testing.T
+	clock  *utils.ManualClock
+	loop   *Loop
+	road   *tb.Builder
+	inputs scenarioInputs
+	ticks  int
+}
+
+func newScenarioHarness(t *testing.T, settings func(s *ms.MapdSettings)) *scenarioHarness {
+	t.Helper()
+	dir := t.TempDir()
+
+	// keep the settings and files of one scenario from leaking into the next
+	ms.Settings.Default()
+	ms.Settings.ResetSpeedLimitAccepted()
+	if settings != nil {
+		settings(&ms.Settings)
+	}
+	overridesPath, learnedCurvesPath := overrides.OVERRIDES_PATH, LEARNED_CURVES_PATH
+	overrides.OVERRIDES_PATH = filepath.Join(dir, "overrides.jsonl")
+	LEARNED_CURVES_PATH = filepath.Join(dir, "learned_curves.json")
+	t.Cleanup(func() {
+		overrides.OVERRIDES_PATH, LEARNED_CURVES_PATH = overridesPath, learnedCurvesPath
+		ms.Settings.Default()
+		ms.Settings.ResetSpeedLimitAccepted()
+	})
+
+	road := scenarioRoad()
+	tiles := maps.OfflineSettings{OutputDirectory: filepath.Join(dir, "offline")}
+	err := road.Write(tiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := utils.NewManualClock(time.Unix(1700000000, 0))
+	state := State{Clock: clock}
+	state.Init()
+	pub := cereal.NewWriterPublisher(io.Discard, cereal.MapdOutCreator)
+	state.Publisher = &pub
+	extended := ExtendedState{
+		Pub:   cereal.NewWriterPublisher(io.Discard, cereal.MapdExtendedOutCreator),
+		state: &state,
+	}
+	tileLoader := maps.NewTileLoader(maps.NewTileManager(tiles, ms.TILE_CACHE_SIZE))
+	tileLoader.Clock = clock
+	tileLoader.StartSync()
+
+	return &scenarioHarness{
+		t:     t,
+		clock: clock,
+		loop:  &Loop{State: &state, Extended: &extended, Tiles: tileLoader},
+		road:  road,
+	}
+}
+
+// drive runs the loop for the duration of a step. The car moves north at
+// vEgo and gets a gps fix every other iteration like a 10 hz receiver.
+func (h *scenarioHarness) drive(step scenarioStep) {
+	h.t.Helper()
+	dt := ms.LOOP_DELAY
+	for elapsed := time.Duration(0); elapsed < step.duration; elapsed += dt {
+		h.clock.Advance(dt)
+		h.inputs.distance += float64(step.vEgo) * dt.Seconds()
+		pos := h.road.Position(tb.Pt(0, h.inputs.distance))
+		h.inputs.car = newScenarioCarState(h.t, step)
+		h.inputs.hasPosition = h.ticks%2 == 0
+		h.inputs.fix = cereal.PositionFix{
+			Source:             "scenario",
+			HasPosition:        true,
+			Latitude:           pos.Lat(),
+			Longitude:          pos.Lon(),
+			HorizontalAccuracy: 1,
+			Speed:              step.vEgo,
+			SpeedAccuracy:      0.1,
+			Valid:              true,
+			ReceivedAt:         h.clock.Now(),
+		}
+		h.loop.Step(&h.inputs)
+		h.loop.Send()
+		h.ticks++
+	}
+}
+
+func newScenarioCarState(t *testing.T, step scenarioStep) car.CarState {
+	t.Helper()
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	carState, err := car.NewRootCarState(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	carState.SetVEgo(step.vEgo)
+	carState.SetVCruise(step.vCruise)
+	carState.SetGasPressed(step.gas)
+	return carState
+}
+
+// scenarioInputs feeds the loop the scripted car and gps for one iteration.
+type scenarioInputs struct {
+	distance    float64 // metres driven north from the origin
+	car         car.CarState
+	fix         cereal.PositionFix
+	hasPosition bool
+}
+
+func (i *scenarioInputs) Inputs() []custom.MapdIn {
+	return nil
+}
+
+func (i *scenarioInputs) CarState() (car.CarState, bool) {
+	return i.car, true
+}
+
+func (i *scenarioInputs) LiveParameters() (log.LiveParametersData, bool) {
+	return log.LiveParametersData{}, false
+}
+
+func (i *scenarioInputs) Model() (log.ModelDataV2, bool) {
+	return log.ModelDataV2{}, false
+}
+
+func (i *scenarioInputs) Position() (cereal.PositionFix, bool) {
+	return i.fix, i.hasPosition
+}
@@ -10,13 +10,14 @@ import (
 
 const (
 	// Queue sizes matching openpilot's services.py
-	QUEUE_SIZE_LEGACY        = 10 * 1024 * 1024 // 10MB - Used by all services in previous versions of msgq/openpilot
-	QUEUE_SIZE_BIG           = 10 * 1024 * 1024 // 10MB - modelV2, video encoders
-	QUEUE_SIZE_MEDIUM        = 2 * 1024 * 1024  // 2MB - CAN, controlsState
-	QUEUE_SIZE_SMALL         = 250 * 1024       // 250KB - most services
-	DEFAULT_SEGMENT_SIZE     = 1 * 1024 * 1024  // 1MB - services not in openpilot list
+	QUEUE_SIZE_LEGACY    = 10 * 1024 * 1024 // 10MB - Used by all services in previous versions of msgq/openpilot
+	QUEUE_SIZE_BIG       = 10 * 1024 * 1024 // 10MB - modelV2, video encoders
+	QUEUE_SIZE_MEDIUM    = 2 * 1024 * 1024  // 2MB - CAN, controlsState
+	QUEUE_SIZE_SMALL     = 250 * 1024       // 250KB - most services
+	DEFAULT_SEGMENT_SIZE = 1 * 1024 * 1024  // 1MB - services not in openpilot list
 
 	LOOP_DELAY                   = 50 * time.Millisecond
+	MAX_OUTPUT_RATE              = 100 // hz. mapdOut is never sent faster than this
 	MS_TO_KPH                    = 3.6
 	MS_TO_MPH                    = 2.237
 	MS_TO_KNOTS                  = 1.944
@@ -37,19 +38,102 @@ const (
 	ACCEPTABLE_BEARING_DELTA_SIN = 0.7071067811865475 // sin(45°) - max acceptable bearing mismatch
 	MIN_WAY_DIST                 = 500                // meters. how many meters to look ahead before stopping gathering next ways.
 	CURVE_CALC_OFFSET            = 10 * MPH_TO_MS
+	WAY_INDEX_CELL_DEGREES       = float64(0.002)   // roughly 200 meters. size of the grid cells used to look up nearby ways
+	MAX_ROAD_WIDTH               = 50               // meters. upper bound on road width used when querying for nearby ways
+	TILE_CACHE_SIZE              = 16               // number of map tiles kept in memory. must hold at least the current tile and its 8 neighbours
+	TILE_RETRY_INTERVAL          = 5 * time.Second  // how often to retry loading tiles when the current tile is missing
+	TILE_PREFETCH_TIME           = 60 * time.Second // how far ahead at the current speed to look for the next tile
+	TILE_PREFETCH_MIN_DISTANCE   = 1000             // meters. minimum lookahead distance for prefetching the next tile
+	HMM_WINDOW                   = 10               // number of gps fixes kept for way matching
+	HMM_BETA                     = 5.0              // meters. scale of the difference between route and driven distance between fixes
+	HMM_UNCONNECTED_LOG_PROB     = -10.0            // penalty for switching between ways that aren't connected
+	HMM_BEARING_WEIGHT           = 4.0              // penalty for a way perpendicular to our bearing
+	HMM_MAX_SIGMAS               = 4                // ignore ways further than this many gps accuracies off the road
+	HMM_MIN_BEARING_SPEED        = 2                // m/s. gps bearing is ignored below this speed
+	HMM_MAX_BEARING_ACCURACY     = 30               // degrees. less accurate bearings are ignored
+	HMM_MAX_ODOMETRY_AGE         = 1 * time.Second  // carState older than this is not used for the distance between fixes
+	EKF_POSITION_NOISE           = 0.5              // m^2/s. process noise of the position filter position
+	EKF_HEADING_NOISE            = 0.01             // rad^2/s. process noise of the position filter heading
+	EKF_SPEED_NOISE              = 1.0              // (m/s)^2/s. process noise of the position filter speed
+	EKF_GPS_SPEED_STD            = 1.0              // m/s. gps speed accuracy used when the fix doesn't report one
+	EKF_GPS_BEARING_STD          = 10 * TO_RADIANS  // gps bearing accuracy used when the fix doesn't report one
+	EKF_MIN_BEARING_SPEED        = 2                // m/s. gps bearing is ignored below this speed
+	EKF_VEGO_STD                 = 0.2              // m/s. accuracy of carState vEgo
+	EKF_RESET_DISTANCE           = 100              // meters. reset the position filter when a fix is further than this from the estimate
+	EKF_MAX_ORIGIN_DISTANCE      = 2000             // meters. how far the position filter can move before moving its local origin
+	EKF_MAX_PREDICT_DT           = 0.5              // seconds. longer gaps between carState messages are not predicted over
+	DR_START_DELAY               = 2 * time.Second  // how long without a gps fix before dead reckoning starts
+	DR_HEADING_CHECK_DISTANCE    = 20               // meters. how often the yaw rate is compared against the path while dead reckoning
+	DR_MAX_HEADING_DELTA         = 45               // degrees. max difference between yaw rate and path heading before the position is stale
+	POSITION_SOURCE_MAX_AGE      = 2 * time.Second  // fixes older than this are not used when selecting a position source
+	POSITION_AGE_PENALTY         = 10               // meters of accuracy a fix loses per second of age when selecting a position source
+	POSITION_INVALID_PENALTY     = 100              // meters of accuracy a fix loses when its source flags it as invalid
+	POSITION_HYSTERESIS          = 5                // meters. how much better another position source must be before switching to it
+	GPS_MAX_ACCURACY             = 50               // meters. fixes with a worse horizontal accuracy are rejected
+	GPS_JUMP_MARGIN              = 20               // meters. extra distance allowed between fixes beyond what the car could have driven
+	GPS_JUMP_SPEED_FACTOR        = 1.5              // multiplier on the car's speed when checking for jumps between fixes
+	GPS_MAX_REJECT_TIME          = 5 * time.Second  // accept jumps once fixes have been rejected for this long, the car may really be there
+	GPS_BEARING_CHECK_DISTANCE   = 10               // meters. minimum distance between fixes before the bearing is compared to the travel direction
+	GPS_MAX_BEARING_DELTA        = 90               // degrees. fixes with a bearing further than this from the travel direction are down weighted
+	GPS_MIN_BEARING_SPEED        = 2                // m/s. gps bearing is ignored below this speed
+	GPS_DOWNWEIGHT_FACTOR        = 3                // multiplier on the accuracy of down weighted fixes
+	LANE_VISION_MAX_AGE          = 1 * time.Second  // modelV2 lane data older than this is not used for lane estimation
+	LANE_MIN_LINE_PROB           = 0.5              // minimum modelV2 lane line probability to measure the lane width
+	LANE_MIN_WIDTH               = 2.5              // meters. narrower measured lanes fall back to the default lane width
+	LANE_MAX_WIDTH               = 5                // meters. wider measured lanes fall back to the default lane width
+	LANE_MIN_POSITION_STD        = 1                // meters. lower bound on position accuracy used for lane estimation
+	LANE_SMOOTHING               = 0.8              // weight of the previous lane estimate when combining with a new one
+	GRAVITY                      = 9.81             // m/s^2
+	VISION_ROLL_MAX_AGE          = 1 * time.Second  // liveParameters roll older than this is not used for vision curve speed
+	VISION_MAX_ROLL              = 0.15             // radians. road roll is clamped to this when correcting vision curve speed
+	PATH_AGREEMENT_DISTANCE      = 200              // meters. how far ahead the model path is compared with the map path
+	PATH_AGREEMENT_STD           = 4.0              // meters. rms difference between the model and map paths that scores 0.6
+	PATH_STRAIGHT_OFFSET         = 3.0              // meters. max distance of the map path from a straight line to be considered straight
+	PATH_LEAVING_MARGIN          = 5.0              // meters. how far past the road edge the model path must end to be leaving the road
+	PATH_LEAVING_FRAMES          = 10               // consecutive model frames leaving the road before it is flagged
+	PATH_MIN_HMM_CONFIDENCE      = 0.8              // min hmm confidence to trust the map path over the model
+	FUSION_GOOD_ACCURACY         = 5.0              // meters. gps accuracy at which the map curve speed is fully trusted
+	FUSION_BAD_ACCURACY          = 30.0             // meters. gps accuracy at which the map curve speed is not trusted
+	FUSION_OVERLAP_TILE          = 0.8              // map confidence when the position is only covered by a neighbouring tile
+	FUSION_VISION_ALPHA          = 0.1              // smoothing factor for tracking the consistency of the vision curve speed
+	FUSION_VISION_STD            = 3.0              // m/s. vision curve speed deviation between frames that scores 0.37
+	FUSION_CONFIDENT             = 0.7              // confidence at which a source lower than the average is followed
+	SPEED_LIMIT_NEXT_CONFIDENCE  = 0.8              // confidence in an upcoming speed limit applied before reaching it
+	SPEED_LIMIT_HELD_CONFIDENCE  = 0.5              // confidence in a last seen speed limit held on a way without one
+	LEARN_NODE_RADIUS            = 20               // meters. samples within this distance of a curve node are learned for that node
+	LEARN_MIN_SPEED              = 5                // m/s. passes slower than this are not learned
+	LEARN_MIN_SAMPLES            = 5                // minimum carState samples near a node for a pass to be learned
+	LEARN_MAX_PASSES             = 20               // passes averaged for a learned curve, older passes fade out
+	LEARN_PRIOR_PASSES           = 3                // passes at which a learned curve is weighted equally with the map curve
+	LEARN_MIN_LAT_A              = 0.5              // m/s^2. lower bound on learned lateral acceleration
+	LEARN_MAX_LAT_A              = 4.0              // m/s^2. upper bound on learned lateral acceleration
+	LEARN_SAVE_INTERVAL          = 60 * time.Second // how often learned curves are written to disk
+	WEB_REQUEST_TIMEOUT          = 2 * time.Second  // how long a web request waits for the main loop to answer
+	WEB_EVENT_INTERVAL           = time.Second / 5  // minimum time between live output events sent to web clients
+	WEB_RETRY_INTERVAL           = 5 * time.Second  // how often the web server retries listening after it failed
+	TRANSPORT_QUEUE_SIZE         = 100              // events buffered for each subscriber of the channel and tcp transports
+	TCP_START_PORT               = 8023             // lowest port a service is published on by the tcp transport
+	TCP_MAX_PORT                 = 65535            // highest port a service is published on by the tcp transport
+	TCP_MAX_MESSAGE_SIZE         = QUEUE_SIZE_BIG   // bytes. larger events received over tcp drop the connection
+	TCP_RECONNECT_INTERVAL       = 1 * time.Second  // how often tcp subscribers retry connecting to a publisher
+	MSGQ_WAIT_TIMEOUT            = time.Second / 10 // how long a msgq subscriber waits for a publisher's signal before checking its queue anyway
+
+	// publishers don't signal shadow msgq subscribers so they check their queue
+	// at this interval instead, one frame of the 100 hz carState
+	MSGQ_SHADOW_POLL_INTERVAL = 10 * time.Millisecond
 )
 
 // ServiceQueueSize maps service names to their queue sizes from openpilot's services.py
 var ServiceQueueSize = map[string]int64{
 	// BIG (10MB)
-	"modelV2":              QUEUE_SIZE_BIG,
-	"modelDataV2SP":        QUEUE_SIZE_BIG,
-	"can":                  QUEUE_SIZE_BIG,
-	"procLog":              QUEUE_SIZE_BIG,
-	"roadEncodeData":       QUEUE_SIZE_BIG,
-	"driverEncodeData":     QUEUE_SIZE_BIG,
-	"wideRoadEncodeData":   QUEUE_SIZE_BIG,
-	"qRoadEncodeData":      QUEUE_SIZE_BIG,
+	"modelV2":            QUEUE_SIZE_BIG,
+	"modelDataV2SP":      QUEUE_SIZE_BIG,
+	"can":                QUEUE_SIZE_BIG,
+	"procLog":            QUEUE_SIZE_BIG,
+	"roadEncodeData":     QUEUE_SIZE_BIG,
+	"driverEncodeData":   QUEUE_SIZE_BIG,
+	"wideRoadEncodeData": QUEUE_SIZE_BIG,
+	"qRoadEncodeData":    QUEUE_SIZE_BIG,
 
 	// MEDIUM (2MB)
 	"controlsState": QUEUE_SIZE_MEDIUM,
@@ -62,6 +146,8 @@ var ServiceQueueSize = map[string]int64{
 	"gpsLocation":         QUEUE_SIZE_SMALL,
 	"gpsLocationExternal": QUEUE_SIZE_SMALL,
 	"liveLocationKalman":  QUEUE_SIZE_SMALL,
+	"liveParameters":      QUEUE_SIZE_SMALL,
+	"livePose":            QUEUE_SIZE_SMALL,
 	"gpsNMEA":             QUEUE_SIZE_SMALL,
 	"ubloxGnss":           QUEUE_SIZE_SMALL,
 	"qcomGnss":            QUEUE_SIZE_SMALL,
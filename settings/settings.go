@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -28,12 +29,30 @@ const (
 	PRIORITY_LOWEST   = "lowest"
 )
 
+const (
+	MATCHING_HEURISTIC = "heuristic"
+	MATCHING_HMM       = "hmm"
+)
+
+const POSITION_SOURCE_AUTO = "auto"
+
+// PositionSources are the values of position_source, auto and the services
+// that report a position. livePose only has orientation and velocity so it
+// can't be forced.
+var PositionSources = []string{POSITION_SOURCE_AUTO, "gpsLocation", "gpsLocationExternal", "liveLocationKalman"}
+
+const (
+	CURVE_MODE_MINIMUM = "minimum"
+	CURVE_MODE_FUSION  = "fusion"
+)
+
 type MapdSettings struct {
 	downloadProgress                    chan DownloadProgress
 	cancelDownload                      chan bool
 	downloadActive                      bool
 	externalSpeedLimit                  float32
 	speedLimitAccepted                  bool
+	resetLearnedCurves                  bool
 	SettingsVersion                     float32 `json:"settings_version"`
 	PressGasToAcceptSpeedLimit          bool    `json:"press_gas_to_accept_speed_limit"`
 	PressGasToOverrideSpeedLimit        bool    `json:"press_gas_to_override_speed_limit"`
@@ -60,10 +79,21 @@ type MapdSettings struct {
 	TargetSpeedAccel                    float32 `json:"target_speed_accel"`
 	TargetSpeedTimeOffset               float32 `json:"target_speed_time_offset"`
 	DefaultLaneWidth                    float32 `json:"default_lane_width"`
+	DriveOnLeft                         bool    `json:"drive_on_left"`
 	MapCurveTargetLatA                  float32 `json:"map_curve_target_lat_a"`
 	SlowDownForNextSpeedLimit           bool    `json:"slow_down_for_next_speed_limit"`
 	SpeedUpForNextSpeedLimit            bool    `json:"speed_up_for_next_speed_limit"`
 	HoldSpeedLimitWhileChangingSetSpeed bool    `json:"hold_speed_limit_while_changing_set_speed"`
+	WayMatchingMode                     string  `json:"way_matching_mode"`
+	PositionFilterEnabled               bool    `json:"position_filter_enabled"`
+	DeadReckoningMaxDistance            float32 `json:"dead_reckoning_max_distance"`
+	PositionSource                      string  `json:"position_source"`
+	CurveSpeedMode                      string  `json:"curve_speed_mode"`
+	CurveLearningEnabled                bool    `json:"curve_learning_enabled"`
+	WebServerEnabled                    bool    `json:"web_server_enabled"`
+	WebServerPort                       float32 `json:"web_server_port"`
+	WebServerAddress                    string  `json:"web_server_address"`
+	OutputRate                          float32 `json:"output_rate"`
 }
 
 func (s *MapdSettings) Default() {
@@ -239,6 +269,8 @@ func (s *MapdSettings) Handle(input custom.MapdIn) {
 		s.TargetSpeedTimeOffset = input.Float()
 	case custom.MapdInputType_setDefaultLaneWidth:
 		s.DefaultLaneWidth = input.Float()
+	case custom.MapdInputType_setDriveOnLeft:
+		s.DriveOnLeft = input.Bool()
 	case custom.MapdInputType_setMapCurveTargetLatA:
 		s.MapCurveTargetLatA = input.Float()
 	case custom.MapdInputType_setExternalSpeedLimitControl:
@@ -250,6 +282,52 @@ func (s *MapdSettings) Handle(input custom.MapdIn) {
 			return
 		}
 		s.SpeedLimitPriority = priority
+	case custom.MapdInputType_setWayMatchingMode:
+		mode, err := input.Str()
+		if err != nil {
+			slog.Warn("failed to read way matching mode string", "error", err)
+			return
+		}
+		s.WayMatchingMode = mode
+	case custom.MapdInputType_setPositionFilterEnabled:
+		s.PositionFilterEnabled = input.Bool()
+	case custom.MapdInputType_setDeadReckoningMaxDistance:
+		s.DeadReckoningMaxDistance = input.Float()
+	case custom.MapdInputType_setPositionSource:
+		source, err := input.Str()
+		if err != nil {
+			slog.Warn("failed to read position source string", "error", err)
+			return
+		}
+		if !slices.Contains(PositionSources, source) {
+			slog.Warn("unknown position source", "source", source)
+			return
+		}
+		s.PositionSource = source
+	case custom.MapdInputType_setCurveSpeedMode:
+		mode, err := input.Str()
+		if err != nil {
+			slog.Warn("failed to read curve speed mode string", "error", err)
+			return
+		}
+		s.CurveSpeedMode = mode
+	case custom.MapdInputType_setCurveLearningEnabled:
+		s.CurveLearningEnabled = input.Bool()
+	case custom.MapdInputType_setWebServerEnabled:
+		s.WebServerEnabled = input.Bool()
+	case custom.MapdInputType_setWebServerPort:
+		s.WebServerPort = input.Float()
+	case custom.MapdInputType_setWebServerAddress:
+		address, err := input.Str()
+		if err != nil {
+			slog.Warn("failed to read web server address string", "error", err)
+			return
+		}
+		s.WebServerAddress = address
+	case custom.MapdInputType_setOutputRate:
+		s.OutputRate = input.Float()
+	case custom.MapdInputType_resetLearnedCurves:
+		s.resetLearnedCurves = true
 	case custom.MapdInputType_setExternalSpeedLimit:
 		s.externalSpeedLimit = input.Float()
 	case custom.MapdInputType_setHoldSpeedLimitWhileChangingSetSpeed:
@@ -360,3 +438,20 @@ func (s *MapdSettings) SpeedLimitAccepted() bool {
 func (s *MapdSettings) AcceptSpeedLimit() {
 	s.speedLimitAccepted = true
 }
+
+// OutputInterval is the time between mapdOut messages for the configured
+// output rate.
+func (s *MapdSettings) OutputInterval() time.Duration {
+	if s.OutputRate <= 0 {
+		return LOOP_DELAY
+	}
+	return time.Duration(float32(time.Second) / min(s.OutputRate, MAX_OUTPUT_RATE))
+}
+
+// TakeLearnedCurvesReset returns true once after a reset of the learned curves
+// was requested.
+func (s *MapdSettings) TakeLearnedCurvesReset() bool {
+	reset := s.resetLearnedCurves
+	s.resetLearnedCurves = false
+	return reset
+}
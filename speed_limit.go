@@ -1,26 +1,36 @@
 package main
 
 import (
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
+	"pfeifer.dev/mapd/cereal/custom"
 	"pfeifer.dev/mapd/maps"
 	m "pfeifer.dev/mapd/math"
+	"pfeifer.dev/mapd/overrides"
 	ms "pfeifer.dev/mapd/settings"
 	"pfeifer.dev/mapd/utils"
 )
 
 type SpeedLimitState struct {
+	Clock                utils.Clock
 	Limit                utils.Float32Tracker
 	Suggestion           utils.Float32Tracker
 	SetSpeedWhenAccepted float32
 	OverrideSpeed        float32
 	AcceptedLimit        float32
+	SuggestionSource     custom.SpeedReason // where the suggested limit came from
+	AcceptedSource       custom.SpeedReason // where the accepted limit came from
 	NextLimit            Upcoming[float32]
+	gasOverride          *overrides.Event // override in progress, logged once the driver lets the speed limit back in
 }
 
-func (s *SpeedLimitState) Init() {
+func (s *SpeedLimitState) Init(clock utils.Clock) {
+	s.Clock = clock
+	s.Limit.Clock = clock
+	s.Suggestion.Clock = clock
 	s.Limit.AllowNullLastValue = false
 	s.Suggestion.AllowNullLastValue = true
 
@@ -37,13 +47,31 @@ func (s *SpeedLimitState) Update(currentWay CurrentWay, car CarState) {
 	if car.SetSpeedChanging {
 		s.OverrideSpeed = 0
 	}
-	s.UpdateLimitAcceptedState(car)
+	s.trackGasOverride(currentWay)
+	s.UpdateLimitAcceptedState(currentWay, car)
 	s.UpdateAcceptedLimitValue(currentWay, car)
 }
 
-func (s *SpeedLimitState) UpdateLimitAcceptedState(car CarState) {
+// trackGasOverride follows a gas override from when it starts until the
+// override speed is cleared and logs the highest speed the driver chose.
+func (s *SpeedLimitState) trackGasOverride(currentWay CurrentWay) {
+	if s.OverrideSpeed > 0 {
+		if s.gasOverride == nil {
+			event := newOverrideEvent(s.Clock.Now(), overrides.TYPE_GAS, currentWay, s.AcceptedLimit, s.OverrideSpeed)
+			s.gasOverride = &event
+		}
+		s.gasOverride.ChosenSpeed = max(s.gasOverride.ChosenSpeed, s.OverrideSpeed)
+		return
+	}
+	if s.gasOverride != nil {
+		logOverride(*s.gasOverride)
+		s.gasOverride = nil
+	}
+}
+
+func (s *SpeedLimitState) UpdateLimitAcceptedState(currentWay CurrentWay, car CarState) {
 	timeout := ms.Settings.AcceptSpeedLimitTimeout
-	if timeout > 0 && time.Since(s.Limit.UpdatedTime) > time.Duration(timeout)*time.Second {
+	if timeout > 0 && s.Clock.Now().Sub(s.Limit.UpdatedTime) > time.Duration(timeout)*time.Second {
 		return
 	}
 	if ms.Settings.PressGasToAcceptSpeedLimit && car.GasPressed {
@@ -52,6 +80,7 @@ func (s *SpeedLimitState) UpdateLimitAcceptedState(car CarState) {
 	if ms.Settings.AdjustSetSpeedToAcceptSpeedLimit && car.SetSpeed.UpdatedTime.After(s.Limit.UpdatedTime) {
 		if ms.Settings.SpeedLimitAccepted() && s.SetSpeedWhenAccepted != car.SetSpeed.Value {
 			ms.Settings.ResetSpeedLimitAccepted()
+			logOverride(newOverrideEvent(s.Clock.Now(), overrides.TYPE_SET_SPEED, currentWay, s.Suggestion.Value, car.SetSpeed.Value))
 		}
 		if s.SetSpeedWhenAccepted == 0 {
 			s.SetSpeedWhenAccepted = car.SetSpeed.Value
@@ -71,25 +100,95 @@ func (s *SpeedLimitState) UpdateAcceptedLimitValue(currentWay CurrentWay, car Ca
 				s.OverrideSpeed = 0
 			}
 			s.AcceptedLimit = s.Suggestion.Value
+			s.AcceptedSource = s.SuggestionSource
 		}
 }
-func (s *SpeedLimitState) SpeedLimitFinalSuggestion(enableSpeedActive bool, setSpeedChanging bool, vEgo float32) float32 {
-	slSuggestedSpeed := s.AcceptedLimit
-	if s.OverrideSpeed > 0  && s.OverrideSpeed > slSuggestedSpeed {
-		slSuggestedSpeed = s.OverrideSpeed
+// Target is the accepted limit, or the speed the driver chose while
+// overriding it with the gas.
+func (s *SpeedLimitState) Target() float32 {
+	if s.OverrideSpeed > 0 && s.OverrideSpeed > s.AcceptedLimit {
+		return s.OverrideSpeed
 	}
-	if !ms.Settings.SpeedLimitUseEnableSpeed || enableSpeedActive {
-		return slSuggestedSpeed
-	} else if setSpeedChanging && ms.Settings.HoldSpeedLimitWhileChangingSetSpeed && vEgo-1 < s.AcceptedLimit {
-		return slSuggestedSpeed
+	return s.AcceptedLimit
+}
+
+// AwaitingAcceptance reports whether a new limit is waiting for the driver to
+// accept it.
+func (s *SpeedLimitState) AwaitingAcceptance() bool {
+	return !ms.Settings.SpeedLimitAccepted() && s.Suggestion.Value > 0 && s.Suggestion.Value != s.AcceptedLimit
+}
+
+// Reason explains the target speed.
+func (s *SpeedLimitState) Reason() custom.SpeedReason {
+	if s.OverrideSpeed > 0 && s.OverrideSpeed > s.AcceptedLimit {
+		return custom.SpeedReason_gasOverride
 	}
-	return 0
+	if s.AwaitingAcceptance() {
+		return custom.SpeedReason_awaitingAcceptance
+	}
+	return s.AcceptedSource
+}
+
+// Confidence rates the target by where it came from. Limits from a maxspeed
+// tag, the external source or the driver are certain, an upcoming limit
+// applied early or a held limit less so.
+func (s *SpeedLimitState) Confidence() float32 {
+	if s.Reason() == custom.SpeedReason_gasOverride {
+		return 1
+	}
+	switch s.AcceptedSource {
+	case custom.SpeedReason_none:
+		return 0
+	case custom.SpeedReason_nextSpeedLimit:
+		return ms.SPEED_LIMIT_NEXT_CONFIDENCE
+	case custom.SpeedReason_heldSpeedLimit:
+		return ms.SPEED_LIMIT_HELD_CONFIDENCE
+	}
+	return 1
+}
+
+// Candidates are the raw values the target speed is chosen from, before
+// offsets are added.
+func (s *SpeedLimitState) Candidates(currentWay CurrentWay) []SpeedCandidate {
+	candidates := []SpeedCandidate{}
+	add := func(reason custom.SpeedReason, speed float32, distance float32) {
+		if speed > 0 {
+			candidates = append(candidates, SpeedCandidate{Reason: reason, Speed: speed, Distance: distance})
+		}
+	}
+	add(custom.SpeedReason_speedLimit, float32(currentWay.MaxSpeed()), 0)
+	if ms.Settings.ExternalSpeedLimitControlEnabled {
+		add(custom.SpeedReason_externalSpeedLimit, ms.Settings.ExternalSpeedLimit(), 0)
+	}
+	if s.SuggestionSource == custom.SpeedReason_heldSpeedLimit {
+		add(custom.SpeedReason_heldSpeedLimit, s.Limit.LastValue, 0)
+	}
+	add(custom.SpeedReason_nextSpeedLimit, s.NextLimit.Value, s.NextLimit.Distance)
+	add(custom.SpeedReason_gasOverride, s.OverrideSpeed, 0)
+	if s.AwaitingAcceptance() {
+		add(custom.SpeedReason_awaitingAcceptance, s.Suggestion.Value, 0)
+	}
+	return candidates
+}
+
+// Active reports whether the enable speed settings let the limit apply.
+func (s *SpeedLimitState) Active(car CarState) bool {
+	if !ms.Settings.SpeedLimitUseEnableSpeed || car.EnableSpeedActive {
+		return true
+	}
+	return car.SetSpeedChanging && ms.Settings.HoldSpeedLimitWhileChangingSetSpeed && car.VEgo-1 < s.AcceptedLimit
 }
 
 func (s *SpeedLimitState) SuggestNewSpeedLimit(currentWay CurrentWay, car CarState) float32 {
-	slSuggestedSpeed := ms.Settings.PrioritySpeedLimit(float32(currentWay.MaxSpeed()))
+	mapLimit := float32(currentWay.MaxSpeed())
+	slSuggestedSpeed := ms.Settings.PrioritySpeedLimit(mapLimit)
+	s.SuggestionSource = custom.SpeedReason_speedLimit
+	if slSuggestedSpeed > 0 && slSuggestedSpeed != mapLimit {
+		s.SuggestionSource = custom.SpeedReason_externalSpeedLimit
+	}
 	if slSuggestedSpeed == 0 && ms.Settings.HoldLastSeenSpeedLimit {
 		slSuggestedSpeed = float32(s.Limit.LastValue)
+		s.SuggestionSource = custom.SpeedReason_heldSpeedLimit
 	}
 	if slSuggestedSpeed > 0 {
 		slSuggestedSpeed += ms.Settings.SpeedLimitOffset
@@ -108,13 +207,44 @@ func (s *SpeedLimitState) SuggestNewSpeedLimit(currentWay CurrentWay, car CarSta
 		}
 		if !nextIsLower && ms.Settings.SpeedUpForNextSpeedLimit && s.NextLimit.Distance < distanceToReachSpeed {
 			slSuggestedSpeed = float32(offsetNextSpeedLimit)
+			s.SuggestionSource = custom.SpeedReason_nextSpeedLimit
 		} else if nextIsLower && ms.Settings.SlowDownForNextSpeedLimit && s.NextLimit.Distance < distanceToReachSpeed {
 			slSuggestedSpeed = float32(offsetNextSpeedLimit)
+			s.SuggestionSource = custom.SpeedReason_nextSpeedLimit
 		}
 	}
 	return slSuggestedSpeed
 }
 
+func newOverrideEvent(now time.Time, overrideType string, currentWay CurrentWay, suggested float32, chosen float32) overrides.Event {
+	pos := currentWay.Distance.LinePosition.Pos
+	return overrides.Event{
+		Time:           now,
+		Type:           overrideType,
+		Latitude:       pos.Lat(),
+		Longitude:      pos.Lon(),
+		WayId:          currentWay.Way.ID(),
+		WayName:        currentWay.Way.WayName(),
+		WayRef:         currentWay.Way.WayRef(),
+		MapLimit:       float32(currentWay.MaxSpeed()),
+		SuggestedSpeed: suggested,
+		ChosenSpeed:    chosen,
+	}
+}
+
+// logOverride persists an override event. Events off of a known way can't be
+// traced back to the map and are dropped.
+func logOverride(event overrides.Event) {
+	if event.WayId == 0 || event.SuggestedSpeed <= 0 {
+		return
+	}
+	slog.Info("driver overrode speed limit", "type", event.Type, "way", event.WayId, "suggested", event.SuggestedSpeed, "chosen", event.ChosenSpeed)
+	err := overrides.Append(event)
+	if err != nil {
+		slog.Warn("could not log speed limit override", "error", err)
+	}
+}
+
 func ParseMaxSpeed(maxspeed string) float64 {
 	splitSpeed := strings.Split(maxspeed, " ")
 	if len(splitSpeed) == 0 {
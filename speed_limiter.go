@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal/custom"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// names of the built in speed limiters
+const (
+	LIMITER_SPEED_LIMIT  = "speedLimit"
+	LIMITER_CURVE_FUSION = "curveFusion"
+	LIMITER_VISION_CURVE = "visionCurve"
+	LIMITER_MAP_CURVE    = "mapCurve"
+)
+
+// SpeedLimiter is a source of a speed that the suggested speed can be limited
+// to. The suggested speed is the lowest target of the enabled limiters that is
+// below the cruise speed, or of the ones that apply without a cruise speed
+// when it is 0.
+type SpeedLimiter interface {
+	// Name identifies the limiter in the outputs, it has to be unique.
+	Name() string
+	// Enabled reports whether the limiter may limit the speed right now,
+	// including its settings and enable speed checks.
+	Enabled(s *State) bool
+	// TargetSpeed is the speed in m/s the limiter wants, 0 when it doesn't want
+	// to limit the speed.
+	TargetSpeed(s *State) float32
+	// Confidence in the target speed from 0 to 1.
+	Confidence(s *State) float32
+	// Reason is a short description of why the target speed is set.
+	Reason(s *State) string
+	// ReasonCode is the kind of constraint behind the target speed. Limiters
+	// that aren't built in can use SpeedReason_other and describe it in Reason.
+	ReasonCode(s *State) custom.SpeedReason
+	// Candidates are the raw values the target speed was chosen from.
+	Candidates(s *State) []SpeedCandidate
+	// AppliesWithoutCruise reports whether the target speed may be suggested
+	// while the cruise speed is 0, like before the first carState.
+	AppliesWithoutCruise() bool
+}
+
+// SpeedCandidate is a value a limiter considered for its target speed.
+type SpeedCandidate struct {
+	Reason   custom.SpeedReason
+	Speed    float32 // m/s, before any offset is added
+	Distance float32 // m to where the value applies, 0 when it already does
+}
+
+// speedLimiters are checked in order, the earlier limiter wins a tie.
+var speedLimiters = []SpeedLimiter{
+	speedLimitLimiter{},
+	curveFusionLimiter{},
+	visionCurveLimiter{},
+	mapCurveLimiter{},
+}
+
+// RegisterSpeedLimiter adds a limiter after the ones already registered. Call
+// it from an init function, a limiter can be added to mapd in its own file
+// without changing the built in ones.
+func RegisterSpeedLimiter(limiter SpeedLimiter) {
+	for _, registered := range speedLimiters {
+		if registered.Name() == limiter.Name() {
+			panic(fmt.Sprintf("speed limiter %s is already registered", limiter.Name()))
+		}
+	}
+	speedLimiters = append(speedLimiters, limiter)
+}
+
+// LimiterResult is what a limiter wanted for the last suggested speed.
+type LimiterResult struct {
+	Name        string
+	Enabled     bool
+	TargetSpeed float32 // m/s
+	Confidence  float32
+	Reason      string
+	ReasonCode  custom.SpeedReason
+	Candidates  []SpeedCandidate
+	// the limiter applies without a cruise speed
+	withoutCruise bool
+}
+
+// Distance is how far ahead the reason for the target speed applies.
+func (r LimiterResult) Distance() float32 {
+	for _, candidate := range r.Candidates {
+		if candidate.Reason == r.ReasonCode {
+			return candidate.Distance
+		}
+	}
+	return 0
+}
+
+func evaluateLimiter(limiter SpeedLimiter, s *State) LimiterResult {
+	return LimiterResult{
+		Name:        limiter.Name(),
+		Enabled:     limiter.Enabled(s),
+		TargetSpeed: limiter.TargetSpeed(s),
+		Confidence:  limiter.Confidence(s),
+		Reason:      limiter.Reason(s),
+		ReasonCode:  limiter.ReasonCode(s),
+		Candidates:  limiter.Candidates(s),
+
+		withoutCruise: limiter.AppliesWithoutCruise(),
+	}
+}
+
+// writeLimiters sets the results of every limiter, the one that won and why on
+// the output.
+func writeLimiters(s *State, output custom.MapdOut) error {
+	err := output.SetActiveSpeedLimiter(s.ActiveLimiter)
+	if err != nil {
+		return errors.Wrap(err, "could not set active speed limiter")
+	}
+	output.SetSuggestedSpeedReason(s.ActiveReason)
+	output.SetSuggestedSpeedReasonDistance(s.ActiveReasonDistance)
+	limiters, err := output.NewSpeedLimiters(int32(len(s.Limiters)))
+	if err != nil {
+		return errors.Wrap(err, "could not create speed limiters")
+	}
+	for i, result := range s.Limiters {
+		item := limiters.At(i)
+		err = item.SetName(result.Name)
+		if err != nil {
+			return errors.Wrap(err, "could not set speed limiter name")
+		}
+		item.SetEnabled(result.Enabled)
+		item.SetTargetSpeed(result.TargetSpeed)
+		item.SetConfidence(result.Confidence)
+		err = item.SetReason(result.Reason)
+		if err != nil {
+			return errors.Wrap(err, "could not set speed limiter reason")
+		}
+		item.SetReasonCode(result.ReasonCode)
+		candidates, err := item.NewCandidates(int32(len(result.Candidates)))
+		if err != nil {
+			return errors.Wrap(err, "could not create speed limiter candidates")
+		}
+		for j, candidate := range result.Candidates {
+			candidates.At(j).SetReason(candidate.Reason)
+			candidates.At(j).SetSpeed(candidate.Speed)
+			candidates.At(j).SetDistance(candidate.Distance)
+		}
+	}
+	return nil
+}
+
+// speedReasonText describes the reasons of the built in limiters.
+func speedReasonText(reason custom.SpeedReason) string {
+	switch reason {
+	case custom.SpeedReason_speedLimit:
+		return "speed limit"
+	case custom.SpeedReason_nextSpeedLimit:
+		return "upcoming speed limit"
+	case custom.SpeedReason_heldSpeedLimit:
+		return "last seen speed limit"
+	case custom.SpeedReason_externalSpeedLimit:
+		return "external speed limit"
+	case custom.SpeedReason_gasOverride:
+		return "gas override"
+	case custom.SpeedReason_awaitingAcceptance:
+		return "speed limit awaiting acceptance"
+	case custom.SpeedReason_mapCurve:
+		return "curve ahead on the map"
+	case custom.SpeedReason_visionCurve:
+		return "curve seen by the model"
+	case custom.SpeedReason_curve:
+		return "curve ahead"
+	}
+	return ""
+}
+
+type speedLimitLimiter struct{}
+
+func (speedLimitLimiter) Name() string {
+	return LIMITER_SPEED_LIMIT
+}
+
+func (speedLimitLimiter) Enabled(s *State) bool {
+	controlEnabled := ms.Settings.SpeedLimitControlEnabled || ms.Settings.ExternalSpeedLimitControlEnabled
+	return controlEnabled && s.SpeedLimit.Active(s.Car)
+}
+
+func (speedLimitLimiter) TargetSpeed(s *State) float32 {
+	return s.SpeedLimit.Target()
+}
+
+func (speedLimitLimiter) Confidence(s *State) float32 {
+	return s.SpeedLimit.Confidence()
+}
+
+func (speedLimitLimiter) Reason(s *State) string {
+	return speedReasonText(s.SpeedLimit.Reason())
+}
+
+func (speedLimitLimiter) ReasonCode(s *State) custom.SpeedReason {
+	return s.SpeedLimit.Reason()
+}
+
+func (speedLimitLimiter) Candidates(s *State) []SpeedCandidate {
+	return s.SpeedLimit.Candidates(s.CurrentWay)
+}
+
+// The speed limit only lowers a cruise speed that is set.
+func (speedLimitLimiter) AppliesWithoutCruise() bool {
+	return false
+}
+
+// curveFusionLimiter replaces the vision and map curve limiters when
+// curve_speed_mode is fusion. The fused target already checks their settings.
+type curveFusionLimiter struct{}
+
+func (curveFusionLimiter) Name() string {
+	return LIMITER_CURVE_FUSION
+}
+
+func (curveFusionLimiter) Enabled(s *State) bool {
+	return ms.Settings.CurveSpeedMode == ms.CURVE_MODE_FUSION
+}
+
+func (curveFusionLimiter) TargetSpeed(s *State) float32 {
+	return s.CurveFusion.Target
+}
+
+func (curveFusionLimiter) Confidence(s *State) float32 {
+	return s.CurveFusion.MapWeight*s.CurveFusion.MapConfidence + s.CurveFusion.VisionWeight*s.CurveFusion.VisionConfidence
+}
+
+func (curveFusionLimiter) Reason(s *State) string {
+	return speedReasonText(custom.SpeedReason_curve)
+}
+
+func (curveFusionLimiter) ReasonCode(s *State) custom.SpeedReason {
+	return custom.SpeedReason_curve
+}
+
+// Candidates are the fused curve speed followed by the curve speeds it was
+// fused from.
+func (curveFusionLimiter) Candidates(s *State) []SpeedCandidate {
+	candidates := []SpeedCandidate{}
+	if s.CurveFusion.Target > 0 {
+		distance := float32(0)
+		if s.CurveFusion.MapWeight > 0 {
+			distance = s.MapCurveDistance
+		}
+		candidates = append(candidates, SpeedCandidate{Reason: custom.SpeedReason_curve, Speed: s.CurveFusion.Target, Distance: distance})
+	}
+	candidates = append(candidates, mapCurveLimiter{}.Candidates(s)...)
+	return append(candidates, visionCurveLimiter{}.Candidates(s)...)
+}
+
+func (curveFusionLimiter) AppliesWithoutCruise() bool {
+	return true
+}
+
+type visionCurveLimiter struct{}
+
+func (visionCurveLimiter) Name() string {
+	return LIMITER_VISION_CURVE
+}
+
+func (visionCurveLimiter) Enabled(s *State) bool {
+	return ms.Settings.CurveSpeedMode != ms.CURVE_MODE_FUSION && ms.Settings.VisionCurveSpeedControlEnabled &&
+		(!ms.Settings.VisionCurveUseEnableSpeed || s.Car.EnableSpeedActive)
+}
+
+func (visionCurveLimiter) TargetSpeed(s *State) float32 {
+	return s.VisionCurveSpeed
+}
+
+func (visionCurveLimiter) Confidence(s *State) float32 {
+	return s.CurveFusion.VisionConfidence
+}
+
+func (visionCurveLimiter) Reason(s *State) string {
+	return speedReasonText(custom.SpeedReason_visionCurve)
+}
+
+func (visionCurveLimiter) ReasonCode(s *State) custom.SpeedReason {
+	return custom.SpeedReason_visionCurve
+}
+
+func (visionCurveLimiter) Candidates(s *State) []SpeedCandidate {
+	if s.VisionCurveSpeed <= 0 {
+		return nil
+	}
+	return []SpeedCandidate{{Reason: custom.SpeedReason_visionCurve, Speed: s.VisionCurveSpeed}}
+}
+
+func (visionCurveLimiter) AppliesWithoutCruise() bool {
+	return true
+}
+
+type mapCurveLimiter struct{}
+
+func (mapCurveLimiter) Name() string {
+	return LIMITER_MAP_CURVE
+}
+
+func (mapCurveLimiter) Enabled(s *State) bool {
+	return ms.Settings.CurveSpeedMode != ms.CURVE_MODE_FUSION && ms.Settings.MapCurveSpeedControlEnabled &&
+		(!ms.Settings.MapCurveUseEnableSpeed || s.Car.EnableSpeedActive)
+}
+
+func (mapCurveLimiter) TargetSpeed(s *State) float32 {
+	return s.MapCurveSpeed
+}
+
+func (mapCurveLimiter) Confidence(s *State) float32 {
+	return s.CurveFusion.MapConfidence
+}
+
+func (mapCurveLimiter) Reason(s *State) string {
+	return speedReasonText(custom.SpeedReason_mapCurve)
+}
+
+func (mapCurveLimiter) ReasonCode(s *State) custom.SpeedReason {
+	return custom.SpeedReason_mapCurve
+}
+
+func (mapCurveLimiter) Candidates(s *State) []SpeedCandidate {
+	if s.MapCurveSpeed <= 0 {
+		return nil
+	}
+	return []SpeedCandidate{{Reason: custom.SpeedReason_mapCurve, Speed: s.MapCurveSpeed, Distance: s.MapCurveDistance}}
+}
+
+func (mapCurveLimiter) AppliesWithoutCruise() bool {
+	return true
+}
@@ -1,71 +1,151 @@
 package main
 
 import (
+	"log/slog"
+	"time"
+
 	"pfeifer.dev/mapd/cereal"
 	"pfeifer.dev/mapd/cereal/car"
 	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/cereal/log"
 	"pfeifer.dev/mapd/maps"
 	m "pfeifer.dev/mapd/math"
 	ms "pfeifer.dev/mapd/settings"
+	"pfeifer.dev/mapd/utils"
+	"pfeifer.dev/mapd/web"
 )
 
 type State struct {
+	Clock                     utils.Clock
 	Publisher                 *cereal.Publisher[custom.MapdOut]
+	Web                       *web.Server
 	Data                      maps.Offline
 	Car                       CarState
 	CurrentWay                CurrentWay
+	WayMatcher                WayMatcher
+	PositionFilter            m.PositionFilter
+	DeadReckoning             DeadReckoning
+	PositionSource            string
+	GpsValidator              GpsValidator
+	Lane                      LaneEstimator
+	Roll                      float32 // radians, from liveParameters
+	RollValid                 bool
+	RollUpdated               time.Time
+	VisionCurveRoll           float32
+	PathAgreement             PathAgreement
+	CurveFusion               CurveFusion
+	PositionAccuracy          float32
+	CurveLearner              CurveLearner
 	SpeedLimit                SpeedLimitState
 	NextWays                  []maps.NextWayResult
 	Position                  m.Position
 	Curvatures                []m.Curvature
 	TargetVelocities          []Velocity
 	DistanceSinceLastPosition float32
+	Odometer                  float64 // meters driven according to vEgo, never reset
 	VisionCurveSpeed          float32
 	MapCurveSpeed             float32
+	MapCurveDistance          float32 // m to the curve point MapCurveSpeed is for
+	Limiters                  []LimiterResult
+	ActiveLimiter             string // name of the limiter the suggested speed came from
+	ActiveReason              custom.SpeedReason
+	ActiveReasonDistance      float32 // m to where ActiveReason applies
 	VisionCurveMA             m.MovingAverage
 	NextAdvisorySpeed         Upcoming[float32]
 	NextHazard                Upcoming[string]
 }
 
+// Init sets up the state. The system clock is used unless Clock was already
+// set.
 func (s *State) Init() {
-	s.Car.Init()
+	if s.Clock == nil {
+		s.Clock = utils.SystemClock{}
+	}
+	s.Car.Init(s.Clock)
+	s.GpsValidator.Clock = s.Clock
 	s.VisionCurveMA.Init(20)
 	s.NextHazard = NewUpcoming(10, "", checkWayForHazardChange)
 	s.NextAdvisorySpeed = NewUpcoming(10, 0, checkWayForAdvisorySpeedChange)
-	s.SpeedLimit.Init()
+	s.SpeedLimit.Init(s.Clock)
 }
 
 
+// SuggestedSpeed returns the cruise speed limited by the lowest target of
+// the enabled speed limiters. What every limiter wanted is kept in Limiters.
 func (s *State) SuggestedSpeed() float32 {
 	suggestedSpeed := min(s.Car.VCruise*ms.KPH_TO_MS, ms.MAX_OP_SPEED)
 
-	if ms.Settings.SpeedLimitControlEnabled || ms.Settings.ExternalSpeedLimitControlEnabled {
-		slSuggestedSpeed := s.SpeedLimit.SpeedLimitFinalSuggestion(s.Car.EnableSpeedActive, s.Car.SetSpeedChanging, s.Car.VEgo)
-		if suggestedSpeed > slSuggestedSpeed && slSuggestedSpeed > 0 {
-			suggestedSpeed = slSuggestedSpeed
+	s.CurveFusion.Update(s)
+	s.Limiters = s.Limiters[:0]
+	s.ActiveLimiter = ""
+	s.ActiveReason = custom.SpeedReason_none
+	s.ActiveReasonDistance = 0
+	for _, limiter := range speedLimiters {
+		result := evaluateLimiter(limiter, s)
+		s.Limiters = append(s.Limiters, result)
+		below := result.TargetSpeed < suggestedSpeed || (suggestedSpeed == 0 && result.withoutCruise)
+		if result.Enabled && result.TargetSpeed > 0 && below {
+			suggestedSpeed = result.TargetSpeed
+			s.ActiveLimiter = result.Name
+			s.ActiveReason = result.ReasonCode
+			s.ActiveReasonDistance = result.Distance()
 		}
 	}
-	if ms.Settings.VisionCurveSpeedControlEnabled && s.VisionCurveSpeed > 0 && (s.VisionCurveSpeed < suggestedSpeed || suggestedSpeed == 0) && (!ms.Settings.VisionCurveUseEnableSpeed || s.Car.EnableSpeedActive) {
-		suggestedSpeed = s.VisionCurveSpeed
-	}
-	if ms.Settings.MapCurveSpeedControlEnabled && s.MapCurveSpeed > 0 && (s.MapCurveSpeed < suggestedSpeed || suggestedSpeed == 0) && (!ms.Settings.MapCurveUseEnableSpeed || s.Car.EnableSpeedActive) {
-		suggestedSpeed = s.MapCurveSpeed
-	}
 	if suggestedSpeed < 0 {
 		suggestedSpeed = 0
 	}
 	return suggestedSpeed
 }
 
+func (s *State) UpdateLiveParameters(params log.LiveParametersData) {
+	s.Roll = params.Roll()
+	s.RollValid = params.Valid()
+	s.RollUpdated = s.Clock.Now()
+}
+
 func (s *State) UpdateCarState(carData car.CarState) {
 	s.Car.Update(carData)
-	s.DistanceSinceLastPosition += float32(s.Car.UpdateTime.DiffMA.Estimate) * s.Car.VEgo
+	distance := float32(s.Car.UpdateTime.DiffMA.Estimate) * s.Car.VEgo
+	s.Odometer += float64(distance)
+	if ms.Settings.PositionFilterEnabled && s.PositionFilter.Initialized() {
+		// the filtered position already includes the distance driven since the last fix
+		dt := s.Car.UpdateTime.Time.Sub(s.Car.UpdateTime.LastTime).Seconds()
+		if dt < ms.EKF_MAX_PREDICT_DT {
+			s.PositionFilter.Predict(dt, float64(s.Car.AEgo), float64(carData.YawRate()))
+		}
+		s.PositionFilter.UpdateSpeed(float64(s.Car.VEgo), ms.EKF_VEGO_STD)
+		s.Position = s.PositionFilter.Location().Pos
+	} else {
+		s.DistanceSinceLastPosition += distance
+	}
+	s.DeadReckoning.Update(s, s.Car.UpdateTime.DiffMA.Estimate, carData.YawRate())
+	s.CurveLearner.Record(s, carData.YawRate())
 	s.SpeedLimit.NextLimit.Update(s)
 	s.NextAdvisorySpeed.Update(s)
 	s.NextHazard.Update(s)
 	s.SpeedLimit.Update(s.CurrentWay, s.Car)
 }
 
+// UpdatePosition handles a new position fix and returns the location that
+// should be used for way matching, either the raw fix or the filtered estimate.
+func (s *State) UpdatePosition(fix cereal.PositionFix) m.Location {
+	s.DistanceSinceLastPosition = 0
+	s.PositionSource = fix.Source
+	s.PositionAccuracy = fix.HorizontalAccuracy
+	loc := fix.Location()
+	if !ms.Settings.PositionFilterEnabled {
+		s.PositionFilter.Reset()
+		s.Position = loc.Pos
+		s.DeadReckoning.Fix(loc, s.Clock.Now())
+		return loc
+	}
+	s.PositionFilter.UpdateGps(loc, fix.SpeedAccuracy, fix.BearingAccuracyDeg)
+	filtered := s.PositionFilter.Location()
+	s.Position = filtered.Pos
+	s.DeadReckoning.Fix(filtered, s.Clock.Now())
+	return filtered
+}
+
 func (s *State) Send() error {
 	msg, output := s.Publisher.NewMessage(true)
 
@@ -76,6 +156,15 @@ func (s *State) Send() error {
 	output.SetWayRef(ref)
 
 	output.SetRoadName(s.CurrentWay.Way.Name())
+	output.SetWayId(s.CurrentWay.Way.ID())
+	output.SetWaySelectionConfidence(s.CurrentWay.Confidence)
+	output.SetDeadReckoning(s.DeadReckoning.Active)
+	output.SetPositionStale(s.DeadReckoning.Stale)
+	output.SetPositionSource(s.PositionSource)
+	output.SetGpsFixAge(s.GpsValidator.FixAge())
+	output.SetGpsAccepted(s.GpsValidator.Accepted)
+	output.SetGpsRejected(s.GpsValidator.Rejected)
+	output.SetGpsFixStatus(s.GpsValidator.Status)
 
 	maxSpeed := s.CurrentWay.MaxSpeed()
 	output.SetSpeedLimit(float32(maxSpeed))
@@ -108,12 +197,29 @@ func (s *State) Send() error {
 	output.SetRoadContext(custom.RoadContext(s.CurrentWay.Way.Context()))
 	output.SetEstimatedRoadWidth(s.CurrentWay.Way.Width())
 	output.SetVisionCurveSpeed(s.VisionCurveSpeed)
+	output.SetVisionCurveRoll(s.VisionCurveRoll)
+	output.SetVisionMapAgreement(s.PathAgreement.Score)
+	output.SetLeavingMappedRoad(s.PathAgreement.Leaving)
 	output.SetMapCurveSpeed(s.MapCurveSpeed)
 
 	output.SetSuggestedSpeed(s.SuggestedSpeed())
+	err := writeLimiters(s, output)
+	if err != nil {
+		slog.Warn("could not write speed limiters", "error", err)
+	}
+	output.SetCurveSpeed(s.CurveFusion.Target)
+	output.SetCurveMapWeight(s.CurveFusion.MapWeight)
+	output.SetCurveVisionWeight(s.CurveFusion.VisionWeight)
 	output.SetDistanceFromWayCenter(float32(s.CurrentWay.OnWay.Distance.Distance))
+	output.SetLaneFromLeft(s.Lane.LaneFromLeft)
+	output.SetLaneFromRight(s.Lane.LaneFromRight)
+	output.SetLaneConfidence(s.Lane.Confidence)
 
 	output.SetWaySelectionType(s.CurrentWay.SelectionType)
+	_, overridden := s.CurrentWay.Way.Override()
+	output.SetWayOverridden(overridden)
+
+	s.Web.PublishOutput(output)
 
 	return s.Publisher.Send(msg)
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/utils"
+)
+
+// phases of the loop that are timed, published in this order
+const (
+	PHASE_INPUTS          = "inputs"
+	PHASE_CAR_STATE       = "carState"
+	PHASE_LIVE_PARAMETERS = "liveParameters"
+	PHASE_MODEL           = "modelV2"
+	PHASE_POSITION        = "position"
+	PHASE_SEND            = "send"
+	PHASE_WEB             = "web"
+)
+
+var loopPhases = []string{PHASE_INPUTS, PHASE_CAR_STATE, PHASE_LIVE_PARAMETERS, PHASE_MODEL, PHASE_POSITION, PHASE_SEND, PHASE_WEB}
+
+type phaseTiming struct {
+	count uint32
+	total time.Duration
+	max   time.Duration
+}
+
+// PhaseTimings measures how long each phase of the loop takes between two
+// extended outputs. The system clock is used unless Clock is set.
+type PhaseTimings struct {
+	Clock  utils.Clock
+	phases map[string]*phaseTiming
+}
+
+func (t *PhaseTimings) Start() time.Time {
+	if t.Clock == nil {
+		t.Clock = utils.SystemClock{}
+	}
+	return t.Clock.Now()
+}
+
+// Measure records a phase that started at start, it is meant to be deferred
+// at the beginning of the phase.
+func (t *PhaseTimings) Measure(phase string, start time.Time) {
+	duration := t.Clock.Now().Sub(start)
+	if t.phases == nil {
+		t.phases = map[string]*phaseTiming{}
+	}
+	timing, ok := t.phases[phase]
+	if !ok {
+		timing = &phaseTiming{}
+		t.phases[phase] = timing
+	}
+	timing.count++
+	timing.total += duration
+	timing.max = max(timing.max, duration)
+}
+
+// Write sets the timings of the phases that ran since the last write and
+// starts measuring again.
+func (t *PhaseTimings) Write(out custom.MapdExtendedOut) error {
+	measured := []string{}
+	for _, phase := range loopPhases {
+		if _, ok := t.phases[phase]; ok {
+			measured = append(measured, phase)
+		}
+	}
+	timings, err := out.NewTimings(int32(len(measured)))
+	if err != nil {
+		return errors.Wrap(err, "could not create timings")
+	}
+	for i, phase := range measured {
+		timing := t.phases[phase]
+		item := timings.At(i)
+		err = item.SetPhase(phase)
+		if err != nil {
+			return errors.Wrap(err, "could not set timing phase")
+		}
+		item.SetCount(timing.count)
+		item.SetAverageMs(float32(timing.total.Seconds()*1000) / float32(timing.count))
+		item.SetMaxMs(float32(timing.max.Seconds() * 1000))
+	}
+	clear(t.phases)
+	return nil
+}
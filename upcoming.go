@@ -52,7 +52,7 @@ func (u *Upcoming[T]) Update(state *State) {
 		valid, val := u.CheckWay(state, u, nextWay)
 		if valid {
 			cumulativeDistance -= state.DistanceSinceLastPosition
-			if u.Position.Equals(nextWay.StartPosition) {
+			if u.Position.SameNode(nextWay.StartPosition) {
 				u.Distance = min(u.Distance, cumulativeDistance)
 				if m.Abs(u.Distance - cumulativeDistance) > 100 {
 					u.Distance = cumulativeDistance
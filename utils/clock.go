@@ -0,0 +1,36 @@
+package utils
+
+import "time"
+
+// Clock is where the mapd logic reads the time from. Tests and replays use a
+// clock they control so the results don't depend on when they run.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock only moves when it is set or advanced.
+type ManualClock struct {
+	now time.Time
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *ManualClock) Set(now time.Time) {
+	c.now = now
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
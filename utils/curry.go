@@ -18,3 +18,8 @@ func (c *Curry[T]) Set(val T) {
 	c.set = true
 	c.val = val
 }
+
+// IsSet reports whether the value was already computed or set.
+func (c *Curry[T]) IsSet() bool {
+	return c.set
+}
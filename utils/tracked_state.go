@@ -6,6 +6,7 @@ import (
 )
 
 type Float32Tracker struct {
+	Clock              Clock
 	LastValue          float32
 	Value              float32
 	UpdatedTime        time.Time
@@ -17,7 +18,7 @@ func (t *Float32Tracker) Update(val float32) (updated bool) {
 		if t.AllowNullLastValue || !(math.IsNaN(float64(t.Value)) || t.Value == 0) {
 			t.LastValue = t.Value
 		}
-		t.UpdatedTime = time.Now()
+		t.UpdatedTime = t.Clock.Now()
 		t.Value = val
 		return true
 	}
@@ -7,19 +7,21 @@ import (
 )
 
 type UpdateTracker struct {
+	Clock    Clock
 	LastTime time.Time
 	Time     time.Time
 	DiffMA   m.MovingAverage
 }
 
-func (u *UpdateTracker) Init(maLength int) {
-	u.LastTime = time.Now()
-	u.Time = time.Now()
+func (u *UpdateTracker) Init(clock Clock, maLength int) {
+	u.Clock = clock
+	u.LastTime = clock.Now()
+	u.Time = u.LastTime
 	u.DiffMA.Init(maLength)
 }
 
 func (u *UpdateTracker) Update() {
 	u.LastTime = u.Time
-	u.Time = time.Now()
+	u.Time = u.Clock.Now()
 	u.DiffMA.Update(u.Time.Sub(u.LastTime).Seconds())
 }
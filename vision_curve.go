@@ -33,13 +33,20 @@ func calcVisionCurveSpeed(model log.ModelDataV2, state *State) float32 {
 		vEgo = 0.1
 	}
 
+	// part of the turn on a banked road is handled by gravity instead of tire
+	// grip. the model's yaw rate is positive turning right while roll is
+	// positive with the left side lower, so gravity pulls toward the left.
+	state.VisionCurveRoll = roadRoll(state)
+	gravityLatA := -float32(ms.GRAVITY * math.Sin(float64(state.VisionCurveRoll)))
+
 	for i := range zOrientRate.Len() {
-		predictedLatAccels[i] = zOrientRate.At(i) * xVelocity.At(i)
+		predictedLatAccels[i] = zOrientRate.At(i)*xVelocity.At(i) - gravityLatA
 		if predictedLatAccels[i] > maxLatA {
 			maxLatA = predictedLatAccels[i]
 		}
 	}
 
+	maxLatA *= state.PathAgreement.VisionWeight(state)
 	maxCurve := maxLatA / (vEgo * vEgo)
 	vTarget := float32(math.Sqrt(float64(ms.Settings.VisionCurveTargetLatA / maxCurve)))
 	if vTarget < ms.Settings.VisionCurveMinTargetV {
@@ -51,6 +58,17 @@ func calcVisionCurveSpeed(model log.ModelDataV2, state *State) float32 {
 	} else if vTarget > 90*ms.MPH_TO_MS {
 		vTarget = 90 * ms.MPH_TO_MS
 	}
+	state.CurveFusion.UpdateVisionConsistency(vTarget)
 	vTarget = float32(state.VisionCurveMA.Update(float64(vTarget)))
 	return vTarget
 }
+
+// roadRoll returns the road roll from liveParameters in radians, positive when
+// the left side of the road is lower. Returns 0 when there is no recent valid
+// estimate.
+func roadRoll(state *State) float32 {
+	if !state.RollValid || state.Clock.Now().Sub(state.RollUpdated) > ms.VISION_ROLL_MAX_AGE {
+		return 0
+	}
+	return min(max(state.Roll, -ms.VISION_MAX_ROLL), ms.VISION_MAX_ROLL)
+}
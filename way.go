@@ -7,7 +7,6 @@ import (
 
 	"github.com/pkg/errors"
 	"pfeifer.dev/mapd/cereal/custom"
-	"pfeifer.dev/mapd/cereal/log"
 	"pfeifer.dev/mapd/cereal/offline"
 	"pfeifer.dev/mapd/maps"
 	m "pfeifer.dev/mapd/math"
@@ -35,6 +34,7 @@ type CurrentWay struct {
 	LastChangeTime    time.Time
 	StableDistance    float32
 	SelectionType     custom.WaySelectionType
+	Confidence        float32
 	maxSpeed          utils.Curry[float64]
 }
 
@@ -52,7 +52,7 @@ func (w *CurrentWay) MaxSpeed() float64 {
 	return w.maxSpeed.Value(w._maxSpeed)
 }
 
-func selectBestWayAdvanced(possibleWays []maps.Way, location log.GpsLocationData, currentWay maps.Way) maps.Way {
+func selectBestWayAdvanced(possibleWays []maps.Way, location m.Location, currentWay maps.Way) maps.Way {
 	if len(possibleWays) == 0 {
 		return maps.Way{}
 	}
@@ -103,7 +103,26 @@ func selectBestWayAdvanced(possibleWays []maps.Way, location log.GpsLocationData
 	return bestWay
 }
 
-func GetCurrentWay(currentWay CurrentWay, nextWays []maps.NextWayResult, offline *maps.Offline, location log.GpsLocationData) (CurrentWay, error) {
+// MatchCurrentWay selects the current way using the configured way matching
+// mode. The hmm matcher falls back to the heuristics if it has no candidates.
+func MatchCurrentWay(s *State, location m.Location) (CurrentWay, error) {
+	if ms.Settings.WayMatchingMode != ms.MATCHING_HMM {
+		s.WayMatcher.Reset()
+		return GetCurrentWay(s.CurrentWay, s.NextWays, &s.Data, location, s.Clock.Now())
+	}
+	odometry := hmmOdometry{
+		distance: s.Odometer,
+		valid:    s.Clock.Now().Sub(s.Car.UpdateTime.Time) < ms.HMM_MAX_ODOMETRY_AGE,
+	}
+	currentWay, err := s.WayMatcher.Update(s.CurrentWay, &s.Data, location, odometry, s.Clock.Now())
+	if err == nil {
+		return currentWay, nil
+	}
+	slog.Debug("hmm way matching failed, falling back to heuristics", "error", err)
+	return GetCurrentWay(s.CurrentWay, s.NextWays, &s.Data, location, s.Clock.Now())
+}
+
+func GetCurrentWay(currentWay CurrentWay, nextWays []maps.NextWayResult, offline *maps.Offline, location m.Location, now time.Time) (CurrentWay, error) {
 	distanceFromCurrentWay := currentWay.OnWay.Distance.Distance
 	nodes := currentWay.Way.Nodes()
 	if len(nodes) > 1 {
@@ -145,7 +164,7 @@ func GetCurrentWay(currentWay CurrentWay, nextWays []maps.NextWayResult, offline
 				StartPosition:     start,
 				EndPosition:       end,
 				ConfidenceCounter: 1,
-				LastChangeTime:    time.Now(),
+				LastChangeTime:    now,
 				StableDistance:    onWay.Distance.Distance,
 				SelectionType:     custom.WaySelectionType_predicted,
 			}, nil
@@ -169,7 +188,7 @@ func GetCurrentWay(currentWay CurrentWay, nextWays []maps.NextWayResult, offline
 					StartPosition:     start,
 					EndPosition:       end,
 					ConfidenceCounter: 1,
-					LastChangeTime:    time.Now(),
+					LastChangeTime:    now,
 					StableDistance:    selectedOnWay.Distance.Distance,
 					SelectionType:     custom.WaySelectionType_possible,
 				}, nil
@@ -204,9 +223,12 @@ func GetCurrentWay(currentWay CurrentWay, nextWays []maps.NextWayResult, offline
 	return CurrentWay{SelectionType: custom.WaySelectionType_fail}, errors.New(fmt.Sprintf("could not find a current way, distance from last way=%f", distanceFromCurrentWay))
 }
 
-func getPossibleWays(offlineMaps *maps.Offline, location log.GpsLocationData) ([]maps.Way, error) {
+func getPossibleWays(offlineMaps *maps.Offline, location m.Location) ([]maps.Way, error) {
 	possibleWays := []maps.Way{}
-	ways := offlineMaps.Ways()
+	pos := location.Pos
+	// OnWay below is checked with a distance multiplier of 2
+	radius := (max(location.HorizontalAccuracy, 5) + ms.MAX_ROAD_WIDTH) * 2
+	ways := offlineMaps.WaysNear(pos, radius)
 
 	for i := range len(ways) {
 		way := ways[i]
@@ -221,12 +243,12 @@ func getPossibleWays(offlineMaps *maps.Offline, location log.GpsLocationData) ([
 	return possibleWays, nil
 }
 
-func NextWays(location log.GpsLocationData, currentWay CurrentWay, offlineMaps *maps.Offline, isForward bool) ([]maps.NextWayResult, error) {
+func NextWays(location m.Location, currentWay CurrentWay, offlineMaps *maps.Offline, isForward bool) ([]maps.NextWayResult, error) {
 	nextWays := []maps.NextWayResult{}
 	dist := float32(0.0)
 	wayIdx := currentWay.Way
 	forward := isForward
-	startPos := m.NewPosition(location.Latitude(), location.Longitude())
+	startPos := location.Pos
 	for dist < ms.MIN_WAY_DIST {
 		d, err := wayIdx.DistanceToEnd(startPos, forward)
 		if err != nil || d <= 0 {
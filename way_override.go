@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"pfeifer.dev/mapd/cereal/custom"
+	"pfeifer.dev/mapd/maps"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+// HandleWayOverrideInput applies MapdIn actions that edit the user override of
+// the current way or import and export the overrides file.
+func HandleWayOverrideInput(s *State, input custom.MapdIn) {
+	var edit func(o *maps.WayOverride)
+	switch input.Type() {
+	case custom.MapdInputType_setWayMaxSpeed:
+		edit = func(o *maps.WayOverride) { o.MaxSpeed = max(float64(input.Float()), 0) }
+	case custom.MapdInputType_setWayIgnoreMaxSpeed:
+		edit = func(o *maps.WayOverride) { o.IgnoreMaxSpeed = input.Bool() }
+	case custom.MapdInputType_setWayAdvisorySpeed:
+		edit = func(o *maps.WayOverride) { o.AdvisorySpeed = max(float64(input.Float()), 0) }
+	case custom.MapdInputType_setWayHazard:
+		hazard, _ := input.Str()
+		edit = func(o *maps.WayOverride) { o.Hazard = hazard }
+	case custom.MapdInputType_setWayIgnoreHazard:
+		edit = func(o *maps.WayOverride) { o.IgnoreHazard = input.Bool() }
+	case custom.MapdInputType_setWayCurveSpeed:
+		edit = func(o *maps.WayOverride) { o.CurveSpeed = max(float64(input.Float()), 0) }
+	case custom.MapdInputType_clearWayOverride:
+		edit = func(o *maps.WayOverride) { *o = maps.WayOverride{} }
+	case custom.MapdInputType_importWayOverrides:
+		name, _ := input.Str()
+		path, err := wayOverridesFile(name)
+		if err == nil {
+			err = maps.WayOverrides.Import(path)
+		}
+		if err != nil {
+			slog.Warn("could not import way overrides", "file", name, "error", err)
+		}
+		return
+	case custom.MapdInputType_exportWayOverrides:
+		name, _ := input.Str()
+		path, err := wayOverridesFile(name)
+		if err == nil {
+			err = maps.WayOverrides.Export(path)
+		}
+		if err != nil {
+			slog.Warn("could not export way overrides", "file", name, "error", err)
+		}
+		return
+	default:
+		return
+	}
+
+	if s.CurrentWay.Way.ID() == 0 && len(s.CurrentWay.Way.Nodes()) == 0 {
+		slog.Warn("could not edit way override, no current way")
+		return
+	}
+	err := maps.WayOverrides.Edit(&s.CurrentWay.Way, edit)
+	if err != nil {
+		slog.Warn("could not save way override", "error", err)
+	}
+}
+
+// wayOverridesFile resolves the file of an import or export input to a path in
+// the way overrides directory. Paths outside of it are rejected so a publisher
+// of mapdIn can't make mapd read or write any other file.
+func wayOverridesFile(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		rel, err := filepath.Rel(maps.WAY_OVERRIDES_DIRECTORY, name)
+		if err != nil {
+			return "", err
+		}
+		name = rel
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("way overrides file is not in %s", maps.WAY_OVERRIDES_DIRECTORY)
+	}
+	return filepath.Join(maps.WAY_OVERRIDES_DIRECTORY, name), nil
+}
+
+// applyCurveSpeedOverrides replaces the target velocity of curves on ways that
+// have a user curve speed.
+func applyCurveSpeedOverrides(s *State) {
+	ways := []*maps.Way{&s.CurrentWay.Way}
+	for i := range s.NextWays {
+		ways = append(ways, &s.NextWays[i].Way)
+	}
+	for _, way := range ways {
+		curveSpeed := way.CurveSpeed()
+		if curveSpeed <= 0 {
+			continue
+		}
+		for _, node := range way.Nodes() {
+			for i := range s.TargetVelocities {
+				tv := &s.TargetVelocities[i]
+				if tv.Velocity > 0 && tv.Velocity < ms.MAX_OP_SPEED && tv.Pos.SameNode(node) {
+					tv.Velocity = curveSpeed
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"pfeifer.dev/mapd/maps"
+)
+
+func TestWayOverridesFile(t *testing.T) {
+	dir := maps.WAY_OVERRIDES_DIRECTORY
+	maps.WAY_OVERRIDES_DIRECTORY = t.TempDir()
+	t.Cleanup(func() { maps.WAY_OVERRIDES_DIRECTORY = dir })
+
+	cases := []struct {
+		name string
+		want string // empty when the file is rejected
+	}{
+		{name: "shared.json", want: "shared.json"},
+		{name: "car/shared.json", want: "car/shared.json"},
+		{name: filepath.Join(maps.WAY_OVERRIDES_DIRECTORY, "shared.json"), want: "shared.json"},
+		{name: ""},
+		{name: "../way_overrides.json"},
+		{name: "car/../../settings.json"},
+		{name: "/data/params/d/MapdSettings"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, err := wayOverridesFile(c.name)
+			if c.want == "" {
+				if err == nil {
+					t.Errorf("allowed %s", path)
+				}
+				return
+			}
+			want := filepath.Join(maps.WAY_OVERRIDES_DIRECTORY, c.want)
+			if err != nil || path != want {
+				t.Errorf("got %s, %v, want %s", path, err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"pfeifer.dev/mapd/maps"
+	tb "pfeifer.dev/mapd/maps/tilebuilder"
+)
+
+type fixtureCase struct {
+	name       string
+	fixture    func() *tb.Builder
+	at         tb.Point
+	bearingDeg float64
+	wantWay    int64
+	wantFwd    bool
+	nextWays   []int64 // the first next ways expected ahead
+	// the sharpest curvature expected ahead and how far off it may be
+	maxCurvature float64
+	tolerance    float64
+}
+
+func TestFixtureWays(t *testing.T) {
+	cases := []fixtureCase{
+		{
+			name: "fork approach", fixture: tb.Fork, at: tb.Pt(0, -300), bearingDeg: 0,
+			wantWay: 1, wantFwd: true, nextWays: []int64{2}, maxCurvature: 0, tolerance: 0.001,
+		},
+		{
+			name: "fork left branch", fixture: tb.Fork, at: tb.Pt(-50, 500), bearingDeg: -5.7,
+			wantWay: 2, wantFwd: true, maxCurvature: 0, tolerance: 0.001,
+		},
+		{
+			name: "fork right branch", fixture: tb.Fork, at: tb.Pt(50, 500), bearingDeg: 5.7,
+			wantWay: 3, wantFwd: true, maxCurvature: 0, tolerance: 0.001,
+		},
+		{
+			// I 2 passes under without a shared node, the bearing has to pick I 1
+			name: "cloverleaf bridge northbound", fixture: tb.Cloverleaf, at: tb.Pt(0, -5), bearingDeg: 0,
+			wantWay: 1, wantFwd: true, nextWays: []int64{2}, maxCurvature: 0, tolerance: 0.001,
+		},
+		{
+			name: "cloverleaf bridge westbound", fixture: tb.Cloverleaf, at: tb.Pt(-5, 0), bearingDeg: 270,
+			wantWay: 3, wantFwd: false, maxCurvature: 0, tolerance: 0.001,
+		},
+		{
+			name: "cloverleaf ramp", fixture: tb.Cloverleaf, at: tb.Pt(29.3, 170.7), bearingDeg: 45,
+			wantWay: 5, wantFwd: true, nextWays: []int64{3}, maxCurvature: 1.0 / 100, tolerance: 0.002,
+		},
+		{
+			name: "roundabout approach", fixture: tb.Roundabout, at: tb.Pt(0, -200), bearingDeg: 0,
+			wantWay: 5, wantFwd: true, nextWays: []int64{1, 2, 3, 4}, maxCurvature: 1.0 / 30, tolerance: 0.005,
+		},
+		{
+			name: "roundabout circle", fixture: tb.Roundabout, at: tb.Pt(21.2, -21.2), bearingDeg: 45,
+			wantWay: 1, wantFwd: true, nextWays: []int64{2, 3, 4, 1}, maxCurvature: 1.0 / 30, tolerance: 0.005,
+		},
+		{
+			name: "tile edge", fixture: tb.TileEdge, at: tb.Pt(0, -300), bearingDeg: 0,
+			wantWay: 1, wantFwd: true, maxCurvature: 0, tolerance: 0.001,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := c.fixture()
+			data, err := b.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			loc := b.Location(c.at, c.bearingDeg, 15)
+
+			currentWay, err := GetCurrentWay(CurrentWay{}, nil, &data, loc, time.Unix(0, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if currentWay.Way.ID() != c.wantWay || currentWay.OnWay.IsForward != c.wantFwd {
+				t.Fatalf("current way is %d forward %v, want %d forward %v", currentWay.Way.ID(), currentWay.OnWay.IsForward, c.wantWay, c.wantFwd)
+			}
+
+			nextWays, err := NextWays(loc, currentWay, &data, currentWay.OnWay.IsForward)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := wayIds(nextWays)
+			if len(ids) < len(c.nextWays) || !slices.Equal(ids[:len(c.nextWays)], c.nextWays) {
+				t.Errorf("next ways are %v, want them to start with %v", ids, c.nextWays)
+			}
+
+			state := State{Data: data, CurrentWay: currentWay, NextWays: nextWays}
+			curvatures, err := GetStateCurvatures(&state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			maxCurvature := 0.0
+			for _, curvature := range curvatures {
+				if math.IsNaN(curvature.Curvature) || math.IsNaN(curvature.ArcLength) {
+					t.Fatalf("curvature at %v is not a number", curvature.Pos)
+				}
+				maxCurvature = max(maxCurvature, curvature.Curvature)
+			}
+			if math.Abs(maxCurvature-c.maxCurvature) > c.tolerance {
+				t.Errorf("sharpest curvature is %f, want %f", maxCurvature, c.maxCurvature)
+			}
+		})
+	}
+}
+
+func wayIds(nextWays []maps.NextWayResult) []int64 {
+	ids := []int64{}
+	for _, nextWay := range nextWays {
+		ids = append(ids, nextWay.Way.ID())
+	}
+	return ids
+}
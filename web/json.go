@@ -0,0 +1,35 @@
+package web
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// NewLineString creates a line feature from latitude/longitude pairs.
+func NewLineString(points [][2]float64, properties map[string]any) Feature {
+	coordinates := make([][2]float64, len(points))
+	for i, p := range points {
+		// geojson coordinates are longitude first
+		coordinates[i] = [2]float64{p[1], p[0]}
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "LineString", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
@@ -0,0 +1,216 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"pfeifer.dev/mapd/cereal"
+	ms "pfeifer.dev/mapd/settings"
+)
+
+//go:embed static
+var static embed.FS
+
+// Provider returns the data for a json endpoint. Providers are always called
+// from the goroutine that calls Process so they can read the mapd state
+// without locking.
+type Provider func() (any, error)
+
+type providerResult struct {
+	data any
+	err  error
+}
+
+type providerRequest struct {
+	provider Provider
+	result   chan providerResult
+}
+
+// Server is an optional http server for viewing map data and the live mapd
+// output in a browser.
+type Server struct {
+	mux       *http.ServeMux
+	server    *http.Server
+	addr      string
+	failed    chan error // receives the error when the running server stops
+	retryAt   time.Time
+	requests  chan providerRequest
+	mu        sync.Mutex
+	clients   map[chan []byte]bool
+	lastEvent time.Time
+}
+
+func NewServer() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		requests: make(chan providerRequest, 8),
+		clients:  map[chan []byte]bool{},
+	}
+	files, _ := fs.Sub(static, "static")
+	s.mux.Handle("/", http.FileServerFS(files))
+	s.mux.HandleFunc("/api/events", s.handleEvents)
+	return s
+}
+
+// Provide serves the data from provider as json at path.
+func (s *Server) Provide(path string, provider Provider) {
+	s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		req := providerRequest{provider: provider, result: make(chan providerResult, 1)}
+		select {
+		case s.requests <- req:
+		case <-time.After(ms.WEB_REQUEST_TIMEOUT):
+			http.Error(w, "mapd is busy", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case res := <-req.result:
+			if res.err != nil {
+				http.Error(w, res.err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			err := json.NewEncoder(w).Encode(res.data)
+			if err != nil {
+				slog.Debug("could not write web response", "path", path, "error", err)
+			}
+		case <-time.After(ms.WEB_REQUEST_TIMEOUT):
+			http.Error(w, "mapd did not respond", http.StatusGatewayTimeout)
+		case <-r.Context().Done():
+		}
+	})
+}
+
+// Process answers pending requests. It should be called from the mapd loop.
+func (s *Server) Process() {
+	for {
+		select {
+		case req := <-s.requests:
+			data, err := req.provider()
+			req.result <- providerResult{data: data, err: err}
+		default:
+			return
+		}
+	}
+}
+
+// Update starts, stops or moves the server to match the settings. A server
+// that fails to listen is started again after WEB_RETRY_INTERVAL.
+func (s *Server) Update(enabled bool, address string, port int) {
+	select {
+	case err := <-s.failed:
+		slog.Warn("web server stopped, retrying", "addr", s.addr, "error", err, "retry", ms.WEB_RETRY_INTERVAL)
+		s.server = nil
+		s.retryAt = time.Now().Add(ms.WEB_RETRY_INTERVAL)
+	default:
+	}
+
+	addr := net.JoinHostPort(address, fmt.Sprint(port))
+	if addr != s.addr {
+		// changed settings are tried right away
+		s.Close()
+		s.addr = addr
+		s.retryAt = time.Time{}
+		if enabled && (port < 1 || port > 65535) {
+			slog.Warn("invalid web server port", "port", port)
+		}
+	}
+	if !enabled {
+		s.Close()
+		return
+	}
+	if s.server != nil || port < 1 || port > 65535 || time.Now().Before(s.retryAt) {
+		return
+	}
+	s.server = &http.Server{
+		Addr:    addr,
+		Handler: s.mux,
+	}
+	s.failed = make(chan error, 1)
+	go func(server *http.Server, failed chan<- error) {
+		slog.Info("Starting web server", "addr", server.Addr)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}(s.server, s.failed)
+}
+
+func (s *Server) Close() {
+	if s.server == nil {
+		return
+	}
+	err := s.server.Close()
+	if err != nil {
+		slog.Warn("could not close web server", "error", err)
+	}
+	s.server = nil
+	s.failed = nil
+}
+
+// PublishOutput sends a cereal output struct to the connected event clients.
+// Events are rate limited and skipped when there are no clients.
+func (s *Server) PublishOutput(output any) {
+	if s == nil || s.server == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.clients) == 0 || time.Since(s.lastEvent) < ms.WEB_EVENT_INTERVAL {
+		return
+	}
+	s.lastEvent = time.Now()
+	data, err := json.Marshal(cereal.StructFields(output))
+	if err != nil {
+		slog.Debug("could not marshal web event", "error", err)
+		return
+	}
+	for client := range s.clients {
+		// slow clients miss events instead of holding up the mapd loop
+		select {
+		case client <- data:
+		default:
+		}
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan []byte, 4)
+	s.mu.Lock()
+	s.clients[client] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case data := <-client:
+			_, err := fmt.Fprintf(w, "data: %s\n\n", data)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+
+	"pfeifer.dev/mapd/maps"
+	m "pfeifer.dev/mapd/math"
+	"pfeifer.dev/mapd/web"
+)
+
+// NewWebServer creates the web server with endpoints for the map data and
+// path of the given state.
+func NewWebServer(s *State) *web.Server {
+	server := web.NewServer()
+	cache := wayFeatureCache{}
+	server.Provide("/api/ways", func() (any, error) {
+		return cache.get(s.Data.Ways()), nil
+	})
+	server.Provide("/api/path", func() (any, error) {
+		return webPath(s), nil
+	})
+	return server
+}
+
+// wayFeatureCache keeps the features of the loaded tile so they are built on
+// the mapd loop once per tile and override change instead of on each request.
+type wayFeatureCache struct {
+	first      *maps.Way
+	count      int
+	version    int
+	collection web.FeatureCollection
+}
+
+func (c *wayFeatureCache) get(ways []maps.Way) web.FeatureCollection {
+	version := maps.WayOverrides.Version()
+	if len(ways) > 0 && &ways[0] == c.first && len(ways) == c.count && version == c.version {
+		return c.collection
+	}
+	features := []web.Feature{}
+	for i := range ways {
+		features = append(features, wayFeature(&ways[i]))
+	}
+	c.first, c.count, c.version = nil, len(ways), version
+	if len(ways) > 0 {
+		c.first = &ways[0]
+	}
+	c.collection = web.NewFeatureCollection(features)
+	return c.collection
+}
+
+type webPosition struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type webPathPoint struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	NodeId         int64   `json:"node_id"`
+	Curvature      float64 `json:"curvature"`
+	TargetVelocity float64 `json:"target_velocity"`
+}
+
+type webTile struct {
+	Loaded bool    `json:"loaded"`
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+type webPathResponse struct {
+	Position   webPosition    `json:"position"`
+	Tile       webTile        `json:"tile"`
+	CurrentWay *web.Feature   `json:"current_way"`
+	NextWays   []web.Feature  `json:"next_ways"`
+	Path       []webPathPoint `json:"path"`
+}
+
+func webPath(s *State) webPathResponse {
+	box := s.Data.Box()
+	res := webPathResponse{
+		Position: webPosition{Latitude: s.Position.Lat(), Longitude: s.Position.Lon()},
+		Tile: webTile{
+			Loaded: s.Data.Loaded,
+			MinLat: box.MinPos.Lat(),
+			MinLon: box.MinPos.Lon(),
+			MaxLat: box.MaxPos.Lat(),
+			MaxLon: box.MaxPos.Lon(),
+		},
+		NextWays: []web.Feature{},
+		Path:     []webPathPoint{},
+	}
+	if len(s.CurrentWay.Way.Nodes()) > 0 {
+		feature := wayFeature(&s.CurrentWay.Way)
+		feature.Properties["is_forward"] = s.CurrentWay.OnWay.IsForward
+		feature.Properties["selection_type"] = s.CurrentWay.SelectionType.String()
+		res.CurrentWay = &feature
+	}
+	for i := range s.NextWays {
+		feature := wayFeature(&s.NextWays[i].Way)
+		feature.Properties["is_forward"] = s.NextWays[i].IsForward
+		res.NextWays = append(res.NextWays, feature)
+	}
+	for i, curvature := range s.Curvatures {
+		point := webPathPoint{
+			Latitude:  curvature.Pos.Lat(),
+			Longitude: curvature.Pos.Lon(),
+			NodeId:    curvature.Pos.NodeID(),
+			Curvature: finite(curvature.Curvature),
+		}
+		if i < len(s.TargetVelocities) {
+			point.TargetVelocity = finite(s.TargetVelocities[i].Velocity)
+		}
+		res.Path = append(res.Path, point)
+	}
+	return res
+}
+
+func wayFeature(way *maps.Way) web.Feature {
+	_, overridden := way.Override()
+	return web.NewLineString(positionPairs(way.Nodes()), map[string]any{
+		"id":             way.ID(),
+		"name":           way.WayName(),
+		"ref":            way.WayRef(),
+		"highway":        way.Highway().String(),
+		"max_speed":      way.MaxSpeed(),
+		"advisory_speed": way.AdvisorySpeed(),
+		"hazard":         way.Hazard(),
+		"lanes":          way.Lanes(),
+		"one_way":        way.OneWay(),
+		"overridden":     overridden,
+	})
+}
+
+func positionPairs(positions []m.Position) [][2]float64 {
+	pairs := make([][2]float64, len(positions))
+	for i, pos := range positions {
+		pairs[i] = [2]float64{pos.Lat(), pos.Lon()}
+	}
+	return pairs
+}
+
+// finite replaces values json can't represent with 0.
+func finite(v float64) float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+	return v
+}